@@ -1,113 +1,162 @@
 package api
 
 import (
-	"database/sql"
-	"errors"
 	"net/http"
+	"time"
 
 	"github.com/AYehia0/go-bk-mst/api/helpers"
 	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
 	"github.com/AYehia0/go-bk-mst/token"
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 )
 
-type createAccountReq struct {
-	Currency string `json:"currency" binding:"required,currency"`
+type getAccountReq struct {
+	Id int64 `uri:"id" binding:"required,min=1"`
 }
 
-func (server *Server) createAccount(ctx *gin.Context) {
-	var req createAccountReq
+// looks up the account and makes sure it's one of the logged-in user's, writes the error response otherwise
+func (server *Server) getOwnedAccount(ctx *gin.Context, accountId int64) (db.Account, bool) {
+	account, err := server.store.GetAccountById(ctx, accountId)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return account, false
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.OwnerName != payload.Username {
+		helpers.WriteError(ctx, problem.New(http.StatusUnauthorized, problem.CodePermissionDenied, "Account doesn't belong to the logged in user!"))
+		return account, false
+	}
+	return account, true
+}
 
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, helpers.ErrorResp(err))
+type getAccountEntriesReq struct {
+	PageId   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+	Query    string `form:"q" binding:"max=255"`
+	Category string `form:"category" binding:"max=64"`
+}
+
+// the statement lines of the account, newest first
+func (server *Server) getAccountEntries(ctx *gin.Context) {
+	var uri getAccountReq
+	var req getAccountEntriesReq
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
 		return
 	}
 
-	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
 
-	arg := db.CreateAccountParams{
-		OwnerName: payload.Username,
-		Currency:  req.Currency,
-		Balance:   0,
+	if _, ok := server.getOwnedAccount(ctx, uri.Id); !ok {
+		return
 	}
-	account, err := server.store.CreateAccount(ctx, arg)
 
+	entries, err := server.store.SearchEntries(ctx, db.SearchEntriesParams{
+		AccountID: uri.Id,
+		Query:     req.Query,
+		Category:  req.Category,
+		Limit:     req.PageSize,
+		Offset:    (req.PageId - 1) * req.PageSize,
+	})
 	if err != nil {
-		// cast the pq error
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "foreign_key_violation", "unique_violation":
-				ctx.JSON(http.StatusForbidden, helpers.ErrorResp(err))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResp(err))
+		helpers.WriteError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, entries)
 }
 
-type getAccountReq struct {
-	Id int64 `uri:"id" binding:"required,min=1"`
+type transferLimitsResp struct {
+	Limits db.TransferLimits `json:"limits"`
+	// -1 when unlimited
+	RemainingDailyAmount int64 `json:"remaining_daily_amount"`
+	RemainingHourlyCount int64 `json:"remaining_hourly_count"`
 }
 
-func (server *Server) getAccount(ctx *gin.Context) {
+// the effective transfer limits of the account and what's left of them
+func (server *Server) getAccountLimits(ctx *gin.Context) {
 	var req getAccountReq
 
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, helpers.ErrorResp(err))
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
 		return
 	}
 
-	account, err := server.store.GetAccountById(ctx, req.Id)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, helpers.ErrorResp(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResp(err))
+	account, ok := server.getOwnedAccount(ctx, req.Id)
+	if !ok {
 		return
 	}
 
-	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if account.OwnerName != payload.Username {
-		ctx.JSON(http.StatusUnauthorized,
-			errors.New("Account doesn't belong to the logged in user!"),
-		)
+	allowance, err := server.store.TransferAllowance(ctx, account, *db.DefaultTransferLimits(server.config))
+	if err != nil {
+		helpers.WriteError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, transferLimitsResp{
+		Limits:               allowance.Limits,
+		RemainingDailyAmount: allowance.RemainingDailyAmount(),
+		RemainingHourlyCount: allowance.RemainingHourlyCount(),
+	})
+}
+
+type getAccountBalanceReq struct {
+	// now when missing
+	At time.Time `form:"at" time_format:"2006-01-02T15:04:05Z07:00"`
 }
 
-type getAccountsReq struct {
-	PageId   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+type accountBalanceResp struct {
+	AccountId int64     `json:"account_id"`
+	Currency  string    `json:"currency"`
+	At        time.Time `json:"at"`
+	Balance   int64     `json:"balance"`
 }
 
-func (server *Server) getAccounts(ctx *gin.Context) {
-	var req getAccountsReq
+// the balance of the account at a point in time, month-end balances for the auditors for example
+func (server *Server) getAccountBalance(ctx *gin.Context) {
+	var uri getAccountReq
+	var req getAccountBalanceReq
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
 
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, helpers.ErrorResp(err))
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
 		return
 	}
 
-	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	account, ok := server.getOwnedAccount(ctx, uri.Id)
+	if !ok {
+		return
+	}
 
-	arg := db.GetAccountsParams{
-		OwnerName: payload.Username,
-		Limit:     req.PageSize,
-		Offset:    (req.PageId - 1) * req.PageSize,
+	resp := accountBalanceResp{
+		AccountId: account.ID,
+		Currency:  account.Currency,
+		At:        req.At,
+		Balance:   account.Balance,
+	}
+
+	if req.At.IsZero() || req.At.After(time.Now()) {
+		resp.At = time.Now()
+		ctx.JSON(http.StatusOK, resp)
+		return
 	}
 
-	accounts, err := server.store.GetAccounts(ctx, arg)
+	balance, err := server.store.BalanceAt(ctx, account, req.At)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResp(err))
+		helpers.WriteError(ctx, err)
 		return
 	}
+	resp.Balance = balance
 
-	ctx.JSON(http.StatusOK, accounts)
+	ctx.JSON(http.StatusOK, resp)
 }
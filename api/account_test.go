@@ -13,10 +13,12 @@ import (
 
 	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
 	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
 	"github.com/AYehia0/go-bk-mst/token"
 	"github.com/AYehia0/go-bk-mst/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
@@ -157,7 +159,7 @@ func TestCreateAccountAPI(t *testing.T) {
 					Balance:   0,
 				}
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					CreateAccountTransaction(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(account, nil)
 			},
@@ -176,13 +178,31 @@ func TestCreateAccountAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Any()).
+					CreateAccountTransaction(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			testName: "Conflict/DuplicateCurrency",
+			body: gin.H{
+				"currency": account.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pq.Error{Code: "23505"})
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusConflict, problem.CodeAlreadyExists)
+			},
+		},
 		{
 			testName: "InternalError",
 			body: gin.H{
@@ -193,7 +213,7 @@ func TestCreateAccountAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Any()).
+					CreateAccountTransaction(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.Account{}, sql.ErrConnDone)
 			},
@@ -374,6 +394,168 @@ func TestGetAccountsAPI(t *testing.T) {
 	}
 }
 
+func TestGetAccountLimitsAPI(t *testing.T) {
+	user1 := getRandomUser()
+	user2 := getRandomUser()
+	account := getRandomAccount(user1.Username)
+
+	testCases := []struct {
+		testName   string
+		username   string
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "OK",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				// the account has no hourly limit of its own
+				limits := testTransferLimits
+				limits.HourlyCount = 0
+				store.EXPECT().
+					TransferAllowance(gomock.Any(), gomock.Eq(account), gomock.Eq(testTransferLimits)).
+					Times(1).
+					Return(db.TransferAllowance{Limits: limits, DailyAmount: 100, HourlyCount: 3}, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp transferLimitsResp
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, testTransferLimits.DailyAmount-100, resp.RemainingDailyAmount)
+				require.Equal(t, int64(-1), resp.RemainingHourlyCount)
+			},
+		},
+		{
+			testName: "Unauthorized",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().TransferAllowance(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/limits", account.ID), nil)
+
+			addAuthorization(t, req, server.tokenCreator, authorizationType, testCase.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
+
+func TestGetAccountBalanceAPI(t *testing.T) {
+	user1 := getRandomUser()
+	user2 := getRandomUser()
+	account := getRandomAccount(user1.Username)
+
+	monthEnd := time.Date(2024, time.January, 31, 23, 59, 59, 0, time.UTC)
+
+	testCases := []struct {
+		testName   string
+		username   string
+		query      string
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "OK/At",
+			username: user1.Username,
+			query:    "?at=2024-01-31T23:59:59Z",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					BalanceAt(gomock.Any(), gomock.Eq(account), gomock.Eq(monthEnd)).
+					Times(1).
+					Return(int64(1234), nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp accountBalanceResp
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, int64(1234), resp.Balance)
+				require.True(t, monthEnd.Equal(resp.At))
+			},
+		},
+		{
+			testName: "OK/Now",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().BalanceAt(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp accountBalanceResp
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, account.Balance, resp.Balance)
+			},
+		},
+		{
+			testName: "BadRequest/InvalidTime",
+			username: user1.Username,
+			query:    "?at=yesterday",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "Unauthorized",
+			username: user2.Username,
+			query:    "?at=2024-01-31T23:59:59Z",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().BalanceAt(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/accounts/%d/balance%s", account.ID, testCase.query)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+
+			addAuthorization(t, req, server.tokenCreator, authorizationType, testCase.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
+
 func getRandomAccount(username string) db.Account {
 	return db.Account{
 		ID:        utils.GetRandomAmount(),
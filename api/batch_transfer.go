@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/AYehia0/go-bk-mst/api/helpers"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// all the transfers are executed in a single database transaction or none of them
+	batchModeAtomic = "atomic"
+	// every transfer is executed on its own, the failed ones are reported back
+	batchModeBestEffort = "best_effort"
+
+	batchStatusCompleted = "completed"
+	batchStatusFailed    = "failed"
+)
+
+type batchTransferReq struct {
+	Mode      string              `json:"mode" binding:"required,oneof=atomic best_effort"`
+	Transfers []createTransferReq `json:"transfers" binding:"required,min=1,max=500,dive"`
+}
+
+type batchTransferItemResp struct {
+	Index  int                  `json:"index"`
+	Status string               `json:"status"`
+	Result *db.TransferTxResult `json:"result,omitempty"`
+	Error  string               `json:"error,omitempty"`
+}
+
+type batchTransferResp struct {
+	Mode    string                  `json:"mode"`
+	Results []batchTransferItemResp `json:"results"`
+}
+
+// bulk payouts from the logged-in user's accounts
+func (server *Server) createBatchTransfer(ctx *gin.Context) {
+	var req batchTransferReq
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	resp := batchTransferResp{
+		Mode:    req.Mode,
+		Results: make([]batchTransferItemResp, len(req.Transfers)),
+	}
+
+	// the same accounts show up many times in a payroll, look each of them up once
+	accounts := map[int64]db.Account{}
+	checkAccount := func(accountId int64, currency string) (db.Account, error) {
+		account, ok := accounts[accountId]
+		if !ok {
+			var err error
+			account, err = db.CheckTransferAccount(ctx, server.store, accountId, currency)
+			// the accounts of the other currencies are still cached
+			if err != nil && problem.From(err).Status != http.StatusBadRequest {
+				return account, err
+			}
+			accounts[accountId] = account
+		}
+
+		if account.Currency != currency {
+			return account, db.CurrencyMismatch(accountId, currency, account.Currency)
+		}
+		return account, nil
+	}
+
+	args := make([]db.TransferTxParams, 0, len(req.Transfers))
+	for i, item := range req.Transfers {
+		err := func() error {
+			if _, err := checkAccount(item.ToAccountId, item.Currency); err != nil {
+				return err
+			}
+			fromAccount, err := checkAccount(item.FromAccountId, item.Currency)
+			if err != nil {
+				return err
+			}
+			if payload.Username != fromAccount.OwnerName {
+				return problem.New(http.StatusUnauthorized, problem.CodePermissionDenied, "from_account doesn't belong to the logged-in user!")
+			}
+			return nil
+		}()
+
+		if err != nil {
+			e := problem.From(err)
+			// a single invalid transfer fails the whole atomic batch before anything is executed
+			if req.Mode == batchModeAtomic {
+				helpers.WriteError(ctx, problem.Newf(e.Status, e.Code, "Transfer [%d] is invalid: %s", i, e.Message))
+				return
+			}
+			resp.Results[i] = batchTransferItemResp{Index: i, Status: batchStatusFailed, Error: e.Message}
+			continue
+		}
+
+		args = append(args, db.TransferTxParams{
+			FromAccountId: item.FromAccountId,
+			ToAccountId:   item.ToAccountId,
+			Amount:        item.Amount,
+			Description:   item.Description,
+			Reference:     item.Reference,
+			Category:      item.Category,
+			Limits:        db.DefaultTransferLimits(server.config),
+			ChargeFee:     true,
+		})
+	}
+
+	if err := server.categorize(ctx, payload.Username, args); err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	if req.Mode == batchModeAtomic {
+		results, err := server.store.BatchTransferTransaction(ctx, args)
+		if err != nil {
+			helpers.WriteError(ctx, err)
+			return
+		}
+
+		for i := range results {
+			resp.Results[i] = batchTransferItemResp{Index: i, Status: batchStatusCompleted, Result: &results[i]}
+		}
+		ctx.JSON(http.StatusOK, resp)
+		return
+	}
+
+	next := 0
+	for i := range resp.Results {
+		if resp.Results[i].Status == batchStatusFailed {
+			continue
+		}
+
+		result, err := server.store.TransferTransaction(ctx, args[next])
+		next++
+		if err != nil {
+			resp.Results[i] = batchTransferItemResp{Index: i, Status: batchStatusFailed, Error: problem.From(err).Message}
+			continue
+		}
+		resp.Results[i] = batchTransferItemResp{Index: i, Status: batchStatusCompleted, Result: &result}
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBatchTransferAPI(t *testing.T) {
+	user1 := getRandomUser()
+	user2 := getRandomUser()
+
+	account1 := getRandomAccount(user1.Username)
+	account2 := getRandomAccount(user2.Username)
+	account3 := getRandomAccount(user2.Username)
+	account1.ID, account2.ID, account3.ID = 1, 2, 3
+
+	account1.Currency = utils.USD
+	account2.Currency = utils.USD
+	account3.Currency = utils.EGP
+
+	validItem := gin.H{
+		"from_account_id": account1.ID,
+		"to_account_id":   account2.ID,
+		"amount":          10,
+		"currency":        utils.USD,
+	}
+	// account3 has another currency
+	invalidItem := gin.H{
+		"from_account_id": account1.ID,
+		"to_account_id":   account3.ID,
+		"amount":          10,
+		"currency":        utils.USD,
+	}
+
+	stubAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).AnyTimes().Return(account1, nil)
+		store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).AnyTimes().Return(account2, nil)
+		store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account3.ID)).AnyTimes().Return(account3, nil)
+	}
+
+	validArg := db.TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        10,
+		Limits:        &testTransferLimits,
+		ChargeFee:     true,
+	}
+
+	testCases := []struct {
+		testName   string
+		body       gin.H
+		setupAuth  func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator)
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "Atomic/OK",
+			body: gin.H{
+				"mode":      batchModeAtomic,
+				"transfers": []gin.H{validItem, validItem},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					BatchTransferTransaction(gomock.Any(), gomock.Eq([]db.TransferTxParams{validArg, validArg})).
+					Times(1).
+					Return([]db.TransferTxResult{{}, {}}, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp batchTransferResp
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Len(t, resp.Results, 2)
+				for _, item := range resp.Results {
+					require.Equal(t, batchStatusCompleted, item.Status)
+				}
+			},
+		},
+		{
+			testName: "Atomic/InvalidItemRejectsBatch",
+			body: gin.H{
+				"mode":      batchModeAtomic,
+				"transfers": []gin.H{validItem, invalidItem},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().BatchTransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "BestEffort/PerItemResults",
+			body: gin.H{
+				"mode":      batchModeBestEffort,
+				"transfers": []gin.H{validItem, invalidItem, validItem},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().BatchTransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					TransferTransaction(gomock.Any(), gomock.Eq(validArg)).
+					Times(2).
+					Return(db.TransferTxResult{}, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp batchTransferResp
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Len(t, resp.Results, 3)
+				require.Equal(t, batchStatusCompleted, resp.Results[0].Status)
+				require.Equal(t, batchStatusFailed, resp.Results[1].Status)
+				require.NotEmpty(t, resp.Results[1].Error)
+				require.Equal(t, batchStatusCompleted, resp.Results[2].Status)
+			},
+		},
+		{
+			testName: "BestEffort/Unauthorized",
+			body: gin.H{
+				"mode":      batchModeBestEffort,
+				"transfers": []gin.H{validItem},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp batchTransferResp
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, batchStatusFailed, resp.Results[0].Status)
+			},
+		},
+		{
+			testName: "BadRequest/InvalidMode",
+			body: gin.H{
+				"mode":      "sometimes",
+				"transfers": []gin.H{validItem},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "BadRequest/EmptyBatch",
+			body: gin.H{
+				"mode":      batchModeAtomic,
+				"transfers": []gin.H{},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewReader(data))
+
+			testCase.setupAuth(t, req, server.tokenCreator)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
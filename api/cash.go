@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/AYehia0/go-bk-mst/api/helpers"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/gin-gonic/gin"
+)
+
+type cashReq struct {
+	AccountId   int64  `json:"account_id" binding:"required,min=1"`
+	Amount      int64  `json:"amount" binding:"required,gt=0"`
+	Currency    string `json:"currency" binding:"required,currency"`
+	Description string `json:"description" binding:"max=255"`
+}
+
+// cash brought to the counter by the customer
+func (server *Server) deposit(ctx *gin.Context) {
+	server.cashOperation(ctx, db.CashDeposit)
+}
+
+// cash handed over to the customer
+func (server *Server) withdraw(ctx *gin.Context) {
+	server.cashOperation(ctx, db.CashWithdrawal)
+}
+
+func (server *Server) cashOperation(ctx *gin.Context, kind string) {
+	var req cashReq
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	if server.config.CashMaxAmount > 0 && req.Amount > server.config.CashMaxAmount {
+		helpers.WriteError(ctx, problem.Newf(http.StatusUnprocessableEntity, problem.CodeLimitExceeded, "Cash %s exceeds the limit of %d", kind, server.config.CashMaxAmount))
+		return
+	}
+
+	if _, valid := server.validateAccount(ctx, req.AccountId, req.Currency); !valid {
+		return
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.CashTxParams{
+		Kind:        kind,
+		AccountId:   req.AccountId,
+		Amount:      req.Amount,
+		Teller:      payload.Username,
+		Description: req.Description,
+	}
+	if kind == db.CashWithdrawal {
+		arg.Limits = db.DefaultTransferLimits(server.config)
+	}
+
+	result, err := server.store.CashTransaction(ctx, arg)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type getCashReceiptReq struct {
+	Id int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getCashReceipt(ctx *gin.Context) {
+	var req getCashReceiptReq
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	receipt, err := server.store.GetCashReceiptById(ctx, req.Id)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, receipt)
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashAPI(t *testing.T) {
+	teller := getRandomUser()
+	teller.Role = db.RoleTeller
+	customer := getRandomUser()
+	customer.Role = db.RoleDepositor
+
+	account := getRandomAccount(customer.Username)
+	account.Currency = utils.USD
+
+	body := gin.H{
+		"account_id": account.ID,
+		"amount":     500,
+		"currency":   utils.USD,
+	}
+
+	testCases := []struct {
+		testName   string
+		url        string
+		user       db.User
+		body       gin.H
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "Deposit/OK",
+			url:      "/teller/deposits",
+			user:     teller,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(teller.Username)).Times(1).Return(teller, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CashTransaction(gomock.Any(), gomock.Eq(db.CashTxParams{
+						Kind:      db.CashDeposit,
+						AccountId: account.ID,
+						Amount:    500,
+						Teller:    teller.Username,
+					})).
+					Times(1).
+					Return(db.CashTxResult{Receipt: db.CashReceipt{ID: 1, Kind: db.CashDeposit}}, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp db.CashTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, int64(1), resp.Receipt.ID)
+			},
+		},
+		{
+			testName: "Withdrawal/OK",
+			url:      "/teller/withdrawals",
+			user:     teller,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(teller.Username)).Times(1).Return(teller, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CashTransaction(gomock.Any(), gomock.Eq(db.CashTxParams{
+						Kind:      db.CashWithdrawal,
+						AccountId: account.ID,
+						Amount:    500,
+						Teller:    teller.Username,
+						Limits:    &testTransferLimits,
+					})).
+					Times(1)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			testName: "Withdrawal/InsufficientFunds",
+			url:      "/teller/withdrawals",
+			user:     teller,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(teller.Username)).Times(1).Return(teller, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CashTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CashTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			testName: "Deposit/OverCashLimit",
+			url:      "/teller/deposits",
+			user:     teller,
+			body: gin.H{
+				"account_id": account.ID,
+				"amount":     100001,
+				"currency":   utils.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(teller.Username)).Times(1).Return(teller, nil)
+				store.EXPECT().CashTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			testName: "Forbidden/NotATeller",
+			url:      "/teller/deposits",
+			user:     customer,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(customer, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CashTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, testCase.url, bytes.NewReader(data))
+
+			addAuthorization(t, req, server.tokenCreator, authorizationType, testCase.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
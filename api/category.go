@@ -0,0 +1,89 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/AYehia0/go-bk-mst/api/helpers"
+	"github.com/AYehia0/go-bk-mst/category"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/gin-gonic/gin"
+)
+
+type createCategoryRuleReq struct {
+	// matched case-insensitively against the description of the transfer
+	Pattern  string `json:"pattern" binding:"required,max=255"`
+	Category string `json:"category" binding:"required,max=64"`
+}
+
+func (server *Server) createCategoryRule(ctx *gin.Context) {
+	var req createCategoryRuleReq
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	rule, err := server.store.CreateCategoryRule(ctx, db.CreateCategoryRuleParams{
+		Username: payload.Username,
+		Pattern:  req.Pattern,
+		Category: req.Category,
+	})
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rule)
+}
+
+func (server *Server) getCategoryRules(ctx *gin.Context) {
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	rules, err := server.store.GetCategoryRules(ctx, payload.Username)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rules)
+}
+
+type deleteCategoryRuleReq struct {
+	Id int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) deleteCategoryRule(ctx *gin.Context) {
+	var req deleteCategoryRuleReq
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// the username in the condition makes the rules of the other users look like missing ones
+	deleted, err := server.store.DeleteCategoryRule(ctx, db.DeleteCategoryRuleParams{
+		ID:       req.Id,
+		Username: payload.Username,
+	})
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+	if deleted == 0 {
+		helpers.WriteError(ctx, problem.New(http.StatusNotFound, problem.CodeNotFound, "Category rule not found"))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// fills in the missing categories of the transfers using the user's rules
+func (server *Server) categorize(ctx *gin.Context, username string, args []db.TransferTxParams) error {
+	return category.Fill(ctx, server.store, username, args)
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryRulesAPI(t *testing.T) {
+	user := getRandomUser()
+
+	rule := db.CategoryRule{
+		ID:       1,
+		Username: user.Username,
+		Pattern:  "NETFLIX",
+		Category: "subscriptions",
+	}
+
+	testCases := []struct {
+		testName   string
+		method     string
+		url        string
+		body       gin.H
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "Create/OK",
+			method:   http.MethodPost,
+			url:      "/categories/rules",
+			body:     gin.H{"pattern": rule.Pattern, "category": rule.Category},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateCategoryRule(gomock.Any(), gomock.Eq(db.CreateCategoryRuleParams{
+						Username: user.Username,
+						Pattern:  rule.Pattern,
+						Category: rule.Category,
+					})).
+					Times(1).
+					Return(rule, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.CategoryRule
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, rule, got)
+			},
+		},
+		{
+			testName: "Create/BadRequest",
+			method:   http.MethodPost,
+			url:      "/categories/rules",
+			body:     gin.H{"pattern": rule.Pattern},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateCategoryRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "List/OK",
+			method:   http.MethodGet,
+			url:      "/categories/rules",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetCategoryRules(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return([]db.CategoryRule{rule}, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			testName: "Delete/OK",
+			method:   http.MethodDelete,
+			url:      "/categories/rules/1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategoryRule(gomock.Any(), gomock.Eq(db.DeleteCategoryRuleParams{ID: 1, Username: user.Username})).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			testName: "Delete/NotFound",
+			method:   http.MethodDelete,
+			url:      "/categories/rules/2",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteCategoryRule(gomock.Any(), gomock.Eq(db.DeleteCategoryRuleParams{ID: 2, Username: user.Username})).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			var body bytes.Buffer
+			if testCase.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(testCase.body))
+			}
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(testCase.method, testCase.url, &body)
+
+			addAuthorization(t, req, server.tokenCreator, authorizationType, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
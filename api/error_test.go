@@ -0,0 +1,146 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func requireProblem(t *testing.T, recorder *httptest.ResponseRecorder, status int, code string) problem.Document {
+	require.Equal(t, status, recorder.Code)
+	require.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+
+	var doc problem.Document
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &doc))
+	require.Equal(t, status, doc.Status)
+	require.Equal(t, code, doc.Code)
+	// the same id as the response header, for the support requests
+	require.NotEmpty(t, doc.RequestId)
+	require.Equal(t, recorder.Header().Get(utils.RequestIdHeader), doc.RequestId)
+	return doc
+}
+
+func TestErrorResponses(t *testing.T) {
+	user := getRandomUser()
+	account := getRandomAccount(user.Username)
+
+	testCases := []struct {
+		testName   string
+		url        string
+		requestId  string
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "ValidationFailed",
+			url:      fmt.Sprintf("/accounts/%d/entries?page_id=0&page_size=20", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				doc := requireProblem(t, recorder, http.StatusBadRequest, problem.CodeInvalidArgument)
+				require.Equal(t, []problem.FieldError{
+					{Field: "page_id", Tag: "required", Message: "page_id is required"},
+					{Field: "page_size", Tag: "max", Message: "page_size must be at most 10"},
+				}, doc.Errors)
+			},
+		},
+		{
+			testName: "NotFound",
+			url:      fmt.Sprintf("/accounts/%d/limits", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				doc := requireProblem(t, recorder, http.StatusNotFound, problem.CodeNotFound)
+				require.Equal(t, fmt.Sprintf("/accounts/%d/limits", account.ID), doc.Instance)
+			},
+		},
+		{
+			testName: "InternalError",
+			url:      fmt.Sprintf("/accounts/%d/limits", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountById(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, errors.New("pq: password authentication failed for user \"root\""))
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				doc := requireProblem(t, recorder, http.StatusInternalServerError, problem.CodeInternal)
+				require.Equal(t, "Internal server error", doc.Detail)
+				require.NotContains(t, recorder.Body.String(), "pq:")
+			},
+		},
+		{
+			testName:  "ClientRequestId",
+			url:       fmt.Sprintf("/accounts/%d/limits", account.ID),
+			requestId: "client-request-1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				doc := requireProblem(t, recorder, http.StatusNotFound, problem.CodeNotFound)
+				require.Equal(t, "client-request-1", doc.RequestId)
+			},
+		},
+		{
+			testName: "RouteNotFound",
+			url:      "/no/such/route",
+			buildStubs: func(store *mockdb.MockStore) {
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusNotFound, problem.CodeNotFound)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, testCase.url, nil)
+			if testCase.requestId != "" {
+				req.Header.Set(utils.RequestIdHeader, testCase.requestId)
+			}
+
+			addAuthorization(t, req, server.tokenCreator, authorizationType, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
+
+func TestGatewayErrorResponse(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(controller))
+
+	// the routes of the gateway answer with the same bodies
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+
+	server.router.ServeHTTP(recorder, req)
+
+	doc := requireProblem(t, recorder, http.StatusUnauthorized, problem.CodeUnauthenticated)
+	require.Equal(t, "/accounts/1", doc.Instance)
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/AYehia0/go-bk-mst/api/helpers"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/gin-gonic/gin"
+)
+
+type previewFeeReq struct {
+	Amount   int64  `form:"amount" binding:"required,gt=0"`
+	Currency string `form:"currency" binding:"required,currency"`
+}
+
+// computes the fee of a transfer without executing it
+func (server *Server) previewFee(ctx *gin.Context) {
+	var req previewFeeReq
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	quote, err := server.store.PreviewFee(ctx, req.Currency, req.Amount)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
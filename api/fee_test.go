@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewFeeAPI(t *testing.T) {
+	user := getRandomUser()
+
+	quote := db.FeeQuote{
+		Currency: utils.USD,
+		Amount:   10000,
+		Fee:      175,
+		Total:    10175,
+	}
+
+	testCases := []struct {
+		testName   string
+		query      string
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "OK",
+			query:    "amount=10000&currency=USD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					PreviewFee(gomock.Any(), gomock.Eq(utils.USD), gomock.Eq(int64(10000))).
+					Times(1).
+					Return(quote, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.FeeQuote
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, quote, got)
+			},
+		},
+		{
+			testName: "BadRequest/InvalidCurrency",
+			query:    "amount=10000&currency=XYZ",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PreviewFee(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "BadRequest/NegativeAmount",
+			query:    "amount=-5&currency=USD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PreviewFee(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/transfers/fees?"+testCase.query, nil)
+
+			addAuthorization(t, req, server.tokenCreator, authorizationType, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/AYehia0/go-bk-mst/api/helpers"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// the requests of the gin handlers the gateway routes replaced, they're still bound first
+// so the invalid requests get the same 400s, the grpc handlers stop at the first invalid field
+type userReq struct {
+	Username string `json:"username" binding:"required,alphanum"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=8"`
+	FullName string `json:"full_name" binding:"required"`
+}
+
+type userLoginReq struct {
+	Username string `json:"username" binding:"required,alphanum"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+type renewTokenReq struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type createAccountReq struct {
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+type getAccountsReq struct {
+	PageId   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// binds the json body like ShouldBindJSON, then puts it back for the gateway
+func bindGatewayJSON(newReq func() any) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			helpers.WriteError(ctx, problem.InvalidArgument(err))
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if err := binding.JSON.BindBody(body, newReq()); err != nil {
+			helpers.WriteError(ctx, problem.InvalidArgument(err))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func bindGatewayUri(newReq func() any) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := ctx.ShouldBindUri(newReq()); err != nil {
+			helpers.WriteError(ctx, problem.InvalidArgument(err))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+func bindGatewayQuery(newReq func() any) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := ctx.ShouldBindQuery(newReq()); err != nil {
+			helpers.WriteError(ctx, problem.InvalidArgument(err))
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,498 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// go test ./api -run TestGatewayGolden -update records the golden bodies again
+var updateGolden = flag.Bool("update", false, "record the golden bodies of the gateway routes")
+
+var (
+	goldenTokens  = regexp.MustCompile(`"(access|refresh)_token":"[^"]*"`)
+	goldenExpires = regexp.MustCompile(`"(access|refresh)_token_expire_at":"[^"]*?(Z|[+-]\d\d:\d\d)"`)
+)
+
+// the tokens and their expiries are random, only the zone of the expiries is kept
+func maskGolden(body []byte) []byte {
+	body = goldenTokens.ReplaceAll(body, []byte(`"${1}_token":"<token>"`))
+	return goldenExpires.ReplaceAll(body, []byte(`"${1}_token_expire_at":"<time>${2}"`))
+}
+
+// the gateway routes answer with the bytes of the gin handlers they replaced. the golden bodies in
+// testdata/gateway were recorded from those handlers with the same stubs, in a zone other than UTC.
+// the errors are compared by status only, their bodies are the problem documents of every route.
+func TestGatewayGolden(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("", 2*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	password := "secret123"
+	hashedPassword, err := utils.GenerateHash(password)
+	require.NoError(t, err)
+
+	user := db.User{Username: "alice", Email: "alice@example.com", FullName: "Alice Liddell", Password: hashedPassword}
+	sessionId := uuid.MustParse("6f1c3c4e-8a55-4c55-9d3a-2b8f3e1d7a10")
+
+	createdAt := time.Date(2023, 8, 1, 10, 30, 0, 123456000, time.Local)
+	account1 := db.Account{ID: 7, OwnerName: user.Username, Balance: 1000, Currency: utils.USD, CreatedAt: createdAt, AvailableBalance: 900, ChartCode: db.ChartCustomerDeposits}
+	account2 := db.Account{ID: 8, OwnerName: "bob", Balance: 50, Currency: utils.USD, CreatedAt: createdAt, AvailableBalance: 50, ChartCode: db.ChartCustomerDeposits}
+
+	// the entries of the fee as chargeFee books them, the fee account is the bank's fee income of the currency
+	fromFeeEntry := db.Entry{ID: 4, AccountID: account1.ID, Amount: -1, CreatedAt: createdAt, Description: "Fee: Dinner", Reference: "INV-1", Category: "fees"}
+	feeEntry := db.Entry{ID: 5, AccountID: 3, Amount: 1, CreatedAt: createdAt, Description: "Fee: Dinner", Reference: "INV-1", Category: "fees"}
+	result := db.TransferTxResult{
+		Transfer: db.Transfer{
+			ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10, CreatedAt: createdAt,
+			Description: "Dinner", Reference: "INV-1", Category: "food", Fee: 1,
+		},
+		FromAccount:  account1,
+		ToAccount:    account2,
+		FromEntry:    db.Entry{ID: 2, AccountID: account1.ID, Amount: -10, CreatedAt: createdAt, Description: "Dinner", Reference: "INV-1", Category: "food"},
+		ToEntry:      db.Entry{ID: 3, AccountID: account2.ID, Amount: 10, CreatedAt: createdAt, Description: "Dinner", Reference: "INV-1", Category: "food"},
+		Fee:          1,
+		FromFeeEntry: &fromFeeEntry,
+		FeeEntry:     &feeEntry,
+	}
+	transferReq := map[string]any{
+		"from_account_id": account1.ID,
+		"to_account_id":   account2.ID,
+		"amount":          10,
+		"currency":        utils.USD,
+		"description":     "Dinner",
+		"reference":       "INV-1",
+		"category":        "food",
+	}
+
+	testCases := []struct {
+		testName string
+		method   string
+		url      string
+		auth     bool
+		// sets the stubs and returns the body of the request, nil for none
+		buildStubs func(t *testing.T, server *Server, store *mockdb.MockStore) any
+		status     int
+		// the golden file of the successful ones
+		golden string
+	}{
+		{
+			testName: "CreateUser",
+			method:   http.MethodPost,
+			url:      "/users",
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().CreateUserTransaction(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				return map[string]any{"username": user.Username, "email": user.Email, "password": password, "full_name": user.FullName}
+			},
+			status: http.StatusOK,
+			golden: "create_user.json",
+		},
+		{
+			testName: "CreateUser/BadRequest",
+			method:   http.MethodPost,
+			url:      "/users",
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().CreateUserTransaction(gomock.Any(), gomock.Any()).Times(0)
+				return map[string]any{"username": "alice!", "email": "alice", "password": "short"}
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			testName: "LoginUser",
+			method:   http.MethodPost,
+			url:      "/users/login",
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{ID: sessionId, Username: user.Username}, nil)
+				return map[string]any{"username": user.Username, "password": password}
+			},
+			status: http.StatusOK,
+			golden: "login_user.json",
+		},
+		{
+			testName: "LoginUser/NotFound",
+			method:   http.MethodPost,
+			url:      "/users/login",
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				return map[string]any{"username": user.Username, "password": password}
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			testName: "LoginUser/WrongPassword",
+			method:   http.MethodPost,
+			url:      "/users/login",
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+				return map[string]any{"username": user.Username, "password": "wrong-password"}
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			testName: "LoginUser/BadRequest",
+			method:   http.MethodPost,
+			url:      "/users/login",
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(0)
+				return map[string]any{"username": user.Username}
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			testName: "RenewToken",
+			method:   http.MethodPost,
+			url:      "/token/renew_token",
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				refreshToken, payload, err := server.tokenCreator.Create(user.Username, time.Hour)
+				require.NoError(t, err)
+
+				store.EXPECT().GetSessionById(gomock.Any(), gomock.Eq(payload.Id)).Times(1).Return(db.Session{
+					ID:           payload.Id,
+					Username:     user.Username,
+					RefreshToken: refreshToken,
+					ExpiredAt:    payload.ExpiredAt,
+				}, nil)
+				return map[string]any{"refresh_token": refreshToken}
+			},
+			status: http.StatusOK,
+			golden: "renew_token.json",
+		},
+		{
+			testName: "RenewToken/Blocked",
+			method:   http.MethodPost,
+			url:      "/token/renew_token",
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				refreshToken, payload, err := server.tokenCreator.Create(user.Username, time.Hour)
+				require.NoError(t, err)
+
+				store.EXPECT().GetSessionById(gomock.Any(), gomock.Eq(payload.Id)).Times(1).Return(db.Session{
+					ID:           payload.Id,
+					Username:     user.Username,
+					RefreshToken: refreshToken,
+					IsBlocked:    true,
+					ExpiredAt:    payload.ExpiredAt,
+				}, nil)
+				return map[string]any{"refresh_token": refreshToken}
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			testName: "RenewToken/Expired",
+			method:   http.MethodPost,
+			url:      "/token/renew_token",
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				refreshToken, payload, err := server.tokenCreator.Create(user.Username, time.Hour)
+				require.NoError(t, err)
+
+				store.EXPECT().GetSessionById(gomock.Any(), gomock.Eq(payload.Id)).Times(1).Return(db.Session{
+					ID:           payload.Id,
+					Username:     user.Username,
+					RefreshToken: refreshToken,
+					ExpiredAt:    time.Now().Add(-time.Minute),
+				}, nil)
+				return map[string]any{"refresh_token": refreshToken}
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			testName: "RenewToken/InvalidToken",
+			method:   http.MethodPost,
+			url:      "/token/renew_token",
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetSessionById(gomock.Any(), gomock.Any()).Times(0)
+				return map[string]any{"refresh_token": "not-a-token"}
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			testName: "RenewToken/SessionNotFound",
+			method:   http.MethodPost,
+			url:      "/token/renew_token",
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				refreshToken, _, err := server.tokenCreator.Create(user.Username, time.Hour)
+				require.NoError(t, err)
+
+				store.EXPECT().GetSessionById(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, sql.ErrNoRows)
+				return map[string]any{"refresh_token": refreshToken}
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			testName: "CreateAccount",
+			method:   http.MethodPost,
+			url:      "/accounts",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().CreateAccountTransaction(gomock.Any(), gomock.Any()).Times(1).Return(account1, nil)
+				return map[string]any{"currency": utils.USD}
+			},
+			status: http.StatusOK,
+			golden: "create_account.json",
+		},
+		{
+			testName: "CreateAccount/BadRequest",
+			method:   http.MethodPost,
+			url:      "/accounts",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().CreateAccountTransaction(gomock.Any(), gomock.Any()).Times(0)
+				return map[string]any{"currency": "XYZ"}
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			testName: "CreateAccount/NoAuthorization",
+			method:   http.MethodPost,
+			url:      "/accounts",
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().CreateAccountTransaction(gomock.Any(), gomock.Any()).Times(0)
+				// the token is checked before the request
+				return map[string]any{"currency": "XYZ"}
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			testName: "GetAccount",
+			method:   http.MethodGet,
+			url:      "/accounts/7",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				return nil
+			},
+			status: http.StatusOK,
+			golden: "get_account.json",
+		},
+		{
+			testName: "GetAccount/NotFound",
+			method:   http.MethodGet,
+			url:      "/accounts/7",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				return nil
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			testName: "GetAccount/OtherOwner",
+			method:   http.MethodGet,
+			url:      "/accounts/8",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				return nil
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			testName: "GetAccount/BadRequest",
+			method:   http.MethodGet,
+			url:      "/accounts/0",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+				return nil
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			testName: "GetAccount/InternalError",
+			method:   http.MethodGet,
+			url:      "/accounts/7",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrConnDone)
+				return nil
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			testName: "ListAccounts",
+			method:   http.MethodGet,
+			url:      "/accounts?page_id=1&page_size=5",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().
+					GetAccounts(gomock.Any(), gomock.Eq(db.GetAccountsParams{OwnerName: user.Username, Limit: 5, Offset: 0})).
+					Times(1).
+					Return([]db.Account{account1}, nil)
+				return nil
+			},
+			status: http.StatusOK,
+			golden: "list_accounts.json",
+		},
+		{
+			testName: "ListAccounts/Empty",
+			method:   http.MethodGet,
+			url:      "/accounts?page_id=2&page_size=5",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{}, nil)
+				return nil
+			},
+			status: http.StatusOK,
+			golden: "list_accounts_empty.json",
+		},
+		{
+			testName: "ListAccounts/BadRequest",
+			method:   http.MethodGet,
+			url:      "/accounts?page_id=1&page_size=20",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(0)
+				return nil
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			testName: "CreateTransfer",
+			method:   http.MethodPost,
+			url:      "/transfers",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(1).Return(result, nil)
+				return transferReq
+			},
+			status: http.StatusOK,
+			golden: "create_transfer.json",
+		},
+		{
+			testName: "CreateTransfer/BadRequest",
+			method:   http.MethodPost,
+			url:      "/transfers",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+				// the numbers in strings were refused by the gin binding
+				return map[string]any{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": "10", "currency": utils.USD}
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			testName: "CreateTransfer/CurrencyMismatch",
+			method:   http.MethodPost,
+			url:      "/transfers",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				eur := account2
+				eur.Currency = utils.EUR
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(eur, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+				return transferReq
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			testName: "CreateTransfer/NotFound",
+			method:   http.MethodPost,
+			url:      "/transfers",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+				return transferReq
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			testName: "CreateTransfer/OtherOwner",
+			method:   http.MethodPost,
+			url:      "/transfers",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+				return transferReq
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			testName: "CreateTransfer/LimitExceeded",
+			method:   http.MethodPost,
+			url:      "/transfers",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, &db.LimitError{Limit: db.LimitDailyAmount, Max: 500000, Remaining: 5})
+				return transferReq
+			},
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			testName: "CreateTransfer/InternalError",
+			method:   http.MethodPost,
+			url:      "/transfers",
+			auth:     true,
+			buildStubs: func(t *testing.T, server *Server, store *mockdb.MockStore) any {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, errors.New("boom"))
+				return transferReq
+			},
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			server := newTestServer(t, store)
+
+			var body bytes.Buffer
+			if reqBody := testCase.buildStubs(t, server, store); reqBody != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(reqBody))
+			}
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(testCase.method, testCase.url, &body)
+			if testCase.auth {
+				addAuthorization(t, req, server.tokenCreator, authorizationType, user.Username, time.Minute)
+			}
+
+			server.router.ServeHTTP(recorder, req)
+			require.Equal(t, testCase.status, recorder.Code, recorder.Body.String())
+
+			if testCase.golden == "" {
+				return
+			}
+			golden := filepath.Join("testdata", "gateway", testCase.golden)
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(golden, maskGolden(recorder.Body.Bytes()), 0o644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+
+			require.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+			require.Equal(t, string(want), string(maskGolden(recorder.Body.Bytes())))
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// the probes shouldn't hang when the database does
+const readinessTimeout = 2 * time.Second
+
+const (
+	checkOk          = "ok"
+	checkUnavailable = "unavailable"
+)
+
+type healthResponse struct {
+	Status string `json:"status"`
+	// the result of every check of the readiness probe
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// the liveness probe, the process is up and serving
+func (server *Server) healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, healthResponse{Status: checkOk})
+}
+
+// the readiness probe, the database is reachable and migrated to the newest embedded migration
+func (server *Server) readyz(ctx *gin.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	res := healthResponse{
+		Status: checkOk,
+		Checks: map[string]string{"database": checkOk, "migrations": checkOk},
+	}
+
+	if err := server.store.Ping(checkCtx); err != nil {
+		// the cause is in the logs only
+		ctx.Error(err)
+		res.Checks["database"] = checkUnavailable
+		res.Checks["migrations"] = checkUnavailable
+	} else if migration, err := server.store.GetSchemaMigration(checkCtx); err != nil {
+		ctx.Error(err)
+		res.Checks["migrations"] = checkUnavailable
+	} else if migration.Dirty {
+		res.Checks["migrations"] = fmt.Sprintf("version %d is dirty", migration.Version)
+	} else if migration.Version < int64(server.schemaVersion) {
+		res.Checks["migrations"] = fmt.Sprintf("version %d, want %d", migration.Version, server.schemaVersion)
+	}
+
+	status := http.StatusOK
+	for _, check := range res.Checks {
+		if check != checkOk {
+			res.Status = checkUnavailable
+			status = http.StatusServiceUnavailable
+		}
+	}
+	ctx.JSON(status, res)
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AYehia0/go-bk-mst/db/migrations"
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthz(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	store := mockdb.NewMockStore(controller)
+	// the liveness doesn't depend on the database
+	store.EXPECT().Ping(gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"status": "ok"}`, recorder.Body.String())
+}
+
+func TestReadyz(t *testing.T) {
+	latest, err := migrations.Latest()
+	require.NoError(t, err)
+	schemaVersion := int64(latest)
+
+	testCases := []struct {
+		testName   string
+		buildStubs func(store *mockdb.MockStore)
+		status     int
+		checks     map[string]string
+	}{
+		{
+			testName: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().GetSchemaMigration(gomock.Any()).Times(1).Return(db.SchemaMigration{Version: schemaVersion}, nil)
+			},
+			status: http.StatusOK,
+			checks: map[string]string{"database": "ok", "migrations": "ok"},
+		},
+		{
+			testName: "DatabaseDown",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(errors.New("dial tcp: connection refused"))
+				store.EXPECT().GetSchemaMigration(gomock.Any()).Times(0)
+			},
+			status: http.StatusServiceUnavailable,
+			checks: map[string]string{"database": "unavailable", "migrations": "unavailable"},
+		},
+		{
+			testName: "NotMigrated",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().GetSchemaMigration(gomock.Any()).Times(1).Return(db.SchemaMigration{}, errors.New(`pq: relation "schema_migrations" does not exist`))
+			},
+			status: http.StatusServiceUnavailable,
+			checks: map[string]string{"database": "ok", "migrations": "unavailable"},
+		},
+		{
+			testName: "OldVersion",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().GetSchemaMigration(gomock.Any()).Times(1).Return(db.SchemaMigration{Version: 3}, nil)
+			},
+			status: http.StatusServiceUnavailable,
+			checks: map[string]string{"database": "ok", "migrations": fmt.Sprintf("version 3, want %d", schemaVersion)},
+		},
+		{
+			testName: "Dirty",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().GetSchemaMigration(gomock.Any()).Times(1).Return(db.SchemaMigration{Version: schemaVersion, Dirty: true}, nil)
+			},
+			status: http.StatusServiceUnavailable,
+			checks: map[string]string{"database": "ok", "migrations": fmt.Sprintf("version %d is dirty", schemaVersion)},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			require.Equal(t, testCase.status, recorder.Code)
+
+			var res healthResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+			require.Equal(t, testCase.checks, res.Checks)
+			if testCase.status == http.StatusOK {
+				require.Equal(t, "ok", res.Status)
+			} else {
+				require.Equal(t, "unavailable", res.Status)
+			}
+		})
+	}
+}
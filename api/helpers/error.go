@@ -1,9 +1,14 @@
 package helpers
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/gin-gonic/gin"
+)
 
-func ErrorResp(err error) gin.H {
-	return gin.H{
-		"error": err.Error(),
-	}
+// writes the application/problem+json response of the error and stops the chain,
+// see problem.From for how the errors are mapped. the error is kept in the context for the request logs
+func WriteError(ctx *gin.Context, err error) {
+	ctx.Error(err)
+	problem.Write(ctx.Writer, ctx.Request, err)
+	ctx.Abort()
 }
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/api/helpers"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/gin-gonic/gin"
+)
+
+type createHoldReq struct {
+	FromAccountId int64  `json:"from_account_id" binding:"required"`
+	ToAccountId   int64  `json:"to_account_id" binding:"required"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+}
+
+type holdResp struct {
+	ID            int64     `json:"id"`
+	FromAccountId int64     `json:"from_account_id"`
+	ToAccountId   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Fee           int64     `json:"fee"`
+	Status        string    `json:"status"`
+	TransferId    *int64    `json:"transfer_id,omitempty"`
+	ExpiredAt     time.Time `json:"expired_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func newHoldResp(hold db.Hold) holdResp {
+	resp := holdResp{
+		ID:            hold.ID,
+		FromAccountId: hold.FromAccountID,
+		ToAccountId:   hold.ToAccountID,
+		Amount:        hold.Amount,
+		Fee:           hold.Fee,
+		Status:        hold.Status,
+		ExpiredAt:     hold.ExpiredAt,
+		CreatedAt:     hold.CreatedAt,
+	}
+	if hold.TransferID.Valid {
+		resp.TransferId = &hold.TransferID.Int64
+	}
+	return resp
+}
+
+type createHoldResp struct {
+	Hold        holdResp   `json:"hold"`
+	FromAccount db.Account `json:"from_account"`
+}
+
+type captureHoldResp struct {
+	Hold holdResp `json:"hold"`
+	db.TransferTxResult
+}
+
+// reserves the funds on the from account, the money moves only after the hold is captured
+func (server *Server) createHold(ctx *gin.Context) {
+	var req createHoldReq
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	_, valid := server.validateAccount(ctx, req.ToAccountId, req.Currency)
+	if !valid {
+		return
+	}
+	fromAccount, valid := server.validateAccount(ctx, req.FromAccountId, req.Currency)
+	if !valid {
+		return
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if payload.Username != fromAccount.OwnerName {
+		helpers.WriteError(ctx, problem.New(http.StatusUnauthorized, problem.CodePermissionDenied, "from_account doesn't belong to the logged-in user!"))
+		return
+	}
+
+	arg := db.HoldTxParams{
+		FromAccountId: req.FromAccountId,
+		ToAccountId:   req.ToAccountId,
+		Amount:        req.Amount,
+		ExpiredAt:     time.Now().Add(server.config.HoldExpireDuration),
+		Limits:        db.DefaultTransferLimits(server.config),
+		ChargeFee:     true,
+	}
+
+	result, err := server.store.HoldTransaction(ctx, arg)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createHoldResp{
+		Hold:        newHoldResp(result.Hold),
+		FromAccount: result.FromAccount,
+	})
+}
+
+type holdReq struct {
+	Id int64 `uri:"id" binding:"required,min=1"`
+}
+
+// settles the hold, only the receiving side decides when to take the money
+func (server *Server) captureHold(ctx *gin.Context) {
+	var req holdReq
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	if !server.validateHoldOwner(ctx, req.Id, false) {
+		return
+	}
+
+	result, err := server.store.CaptureHoldTransaction(ctx, req.Id)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, captureHoldResp{
+		Hold:             newHoldResp(result.Hold),
+		TransferTxResult: result.TransferTxResult,
+	})
+}
+
+// releases the reserved funds back to the from account, the payer can call off its own hold too
+func (server *Server) voidHold(ctx *gin.Context) {
+	var req holdReq
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	if !server.validateHoldOwner(ctx, req.Id, true) {
+		return
+	}
+
+	hold, err := server.store.VoidHoldTransaction(ctx, req.Id)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newHoldResp(hold))
+}
+
+// the hold can only be settled by the owner of the account receiving the money,
+// or by the one paying it when payerAllowed
+func (server *Server) validateHoldOwner(ctx *gin.Context, holdId int64, payerAllowed bool) bool {
+	hold, err := server.store.GetHoldById(ctx, holdId)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return false
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	accountIds := []int64{hold.ToAccountID}
+	if payerAllowed {
+		accountIds = append(accountIds, hold.FromAccountID)
+	}
+	for _, accountId := range accountIds {
+		account, err := server.store.GetAccountById(ctx, accountId)
+		if err != nil {
+			helpers.WriteError(ctx, err)
+			return false
+		}
+		if payload.Username == account.OwnerName {
+			return true
+		}
+	}
+
+	helpers.WriteError(ctx, problem.New(http.StatusUnauthorized, problem.CodePermissionDenied, "Hold doesn't belong to the logged-in user!"))
+	return false
+}
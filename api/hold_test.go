@@ -0,0 +1,368 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateHoldAPI(t *testing.T) {
+	user1 := getRandomUser()
+	user2 := getRandomUser()
+
+	account1 := getRandomAccount(user1.Username)
+	account2 := getRandomAccount(user2.Username)
+	account1.Currency = utils.USD
+	account2.Currency = utils.USD
+
+	amount := int64(10)
+
+	testCases := []struct {
+		testName   string
+		body       gin.H
+		setupAuth  func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator)
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "OK",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					HoldTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.HoldTxParams) (db.HoldTxResult, error) {
+						// the fee is reserved with the amount
+						require.True(t, arg.ChargeFee)
+						return db.HoldTxResult{
+							Hold: db.Hold{
+								ID:            1,
+								FromAccountID: account1.ID,
+								ToAccountID:   account2.ID,
+								Amount:        amount,
+								Fee:           5,
+								Status:        db.HoldStatusPending,
+							},
+						}, nil
+					})
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp createHoldResp
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, db.HoldStatusPending, resp.Hold.Status)
+				require.Equal(t, amount, resp.Hold.Amount)
+				require.Equal(t, int64(5), resp.Hold.Fee)
+				require.Nil(t, resp.Hold.TransferId)
+			},
+		},
+		{
+			testName: "InsufficientFunds",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					HoldTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.HoldTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			testName: "Unauthorized/Only from logged-in account",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().HoldTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			testName: "BadRequest/NegativeAmount",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          -amount,
+				"currency":        utils.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().HoldTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/transfers/holds", bytes.NewReader(data))
+
+			testCase.setupAuth(t, req, server.tokenCreator)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
+
+func TestSettleHoldAPI(t *testing.T) {
+	user1 := getRandomUser()
+	user2 := getRandomUser()
+
+	account1 := getRandomAccount(user1.Username)
+	account2 := getRandomAccount(user2.Username)
+	// the ids are random, the stubs can't tell the accounts apart otherwise
+	account2.ID = account1.ID + 1000
+
+	hold := db.Hold{
+		ID:            utils.GetRandomAmount(),
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Status:        db.HoldStatusPending,
+		ExpiredAt:     time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		testName   string
+		action     string
+		setupAuth  func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator)
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "Capture/OK",
+			action:   "capture",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				captured := hold
+				captured.Status = db.HoldStatusCaptured
+				captured.TransferID = sql.NullInt64{Int64: 1, Valid: true}
+
+				store.EXPECT().GetHoldById(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					CaptureHoldTransaction(gomock.Any(), gomock.Eq(hold.ID)).
+					Times(1).
+					Return(db.CaptureHoldTxResult{Hold: captured}, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp captureHoldResp
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, db.HoldStatusCaptured, resp.Hold.Status)
+				require.NotNil(t, resp.Hold.TransferId)
+			},
+		},
+		{
+			testName: "Capture/Expired",
+			action:   "capture",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHoldById(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					CaptureHoldTransaction(gomock.Any(), gomock.Eq(hold.ID)).
+					Times(1).
+					Return(db.CaptureHoldTxResult{}, db.ErrHoldExpired)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			testName: "Capture/Unauthorized",
+			action:   "capture",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHoldById(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CaptureHoldTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			testName: "Void/OK",
+			action:   "void",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				voided := hold
+				voided.Status = db.HoldStatusVoided
+
+				store.EXPECT().GetHoldById(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					VoidHoldTransaction(gomock.Any(), gomock.Eq(hold.ID)).
+					Times(1).
+					Return(voided, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			testName: "Void/Payer",
+			action:   "void",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				voided := hold
+				voided.Status = db.HoldStatusVoided
+
+				store.EXPECT().GetHoldById(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+					VoidHoldTransaction(gomock.Any(), gomock.Eq(hold.ID)).
+					Times(1).
+					Return(voided, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			testName: "Void/Unauthorized",
+			action:   "void",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, getRandomUser().Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHoldById(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().VoidHoldTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			testName: "Void/NotPending",
+			action:   "void",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHoldById(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					VoidHoldTransaction(gomock.Any(), gomock.Eq(hold.ID)).
+					Times(1).
+					Return(db.Hold{}, db.ErrHoldNotPending)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			testName: "Void/NotFound",
+			action:   "void",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHoldById(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.Hold{}, sql.ErrNoRows)
+				store.EXPECT().VoidHoldTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			urlPath := fmt.Sprintf("/transfers/holds/%d/%s", hold.ID, testCase.action)
+			req := httptest.NewRequest(http.MethodPost, urlPath, nil)
+
+			testCase.setupAuth(t, req, server.tokenCreator)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
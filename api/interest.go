@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/AYehia0/go-bk-mst/api/helpers"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/gin-gonic/gin"
+)
+
+type getInterestPlansReq struct {
+	Currency string `form:"currency" binding:"required,currency"`
+}
+
+func (server *Server) getInterestPlans(ctx *gin.Context) {
+	var req getInterestPlansReq
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	plans, err := server.store.GetInterestPlans(ctx, req.Currency)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, plans)
+}
+
+type setInterestPlanReq struct {
+	PlanId int64 `json:"plan_id" binding:"required,min=1"`
+}
+
+// turns the account into a savings account, the interest is accrued from the end of this day on, never for the days before
+func (server *Server) setAccountInterestPlan(ctx *gin.Context) {
+	var uri getAccountReq
+	var req setInterestPlanReq
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	account, ok := server.getOwnedAccount(ctx, uri.Id)
+	if !ok {
+		return
+	}
+
+	plan, err := server.store.GetInterestPlanById(ctx, req.PlanId)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	if plan.Currency != account.Currency {
+		helpers.WriteError(ctx, problem.Newf(http.StatusBadRequest, problem.CodeInvalidArgument,
+			"Interest plan [%d] currency mismatch: %s vs %s", plan.ID, plan.Currency, account.Currency))
+		return
+	}
+
+	accountPlan, err := server.store.SetAccountInterestPlan(ctx, db.SetAccountInterestPlanParams{
+		AccountID: account.ID,
+		PlanID:    plan.ID,
+	})
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accountPlan)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetAccountInterestPlanAPI(t *testing.T) {
+	user1 := getRandomUser()
+	user2 := getRandomUser()
+
+	account := getRandomAccount(user1.Username)
+	account.Currency = utils.USD
+
+	plan := db.InterestPlan{ID: 1, Name: "Saver", Currency: utils.USD, AnnualRateBps: 250}
+	eurPlan := db.InterestPlan{ID: 2, Name: "Saver", Currency: utils.EUR, AnnualRateBps: 150}
+
+	testCases := []struct {
+		testName   string
+		username   string
+		body       gin.H
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "OK",
+			username: user1.Username,
+			body:     gin.H{"plan_id": plan.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetInterestPlanById(gomock.Any(), gomock.Eq(plan.ID)).Times(1).Return(plan, nil)
+				store.EXPECT().
+					SetAccountInterestPlan(gomock.Any(), gomock.Eq(db.SetAccountInterestPlanParams{AccountID: account.ID, PlanID: plan.ID})).
+					Times(1).
+					Return(db.AccountInterestPlan{AccountID: account.ID, PlanID: plan.ID}, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			testName: "CurrencyMismatch",
+			username: user1.Username,
+			body:     gin.H{"plan_id": eurPlan.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetInterestPlanById(gomock.Any(), gomock.Eq(eurPlan.ID)).Times(1).Return(eurPlan, nil)
+				store.EXPECT().SetAccountInterestPlan(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "Unauthorized",
+			username: user2.Username,
+			body:     gin.H{"plan_id": plan.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().SetAccountInterestPlan(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/accounts/%d/interest_plan", account.ID)
+			req := httptest.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+
+			addAuthorization(t, req, server.tokenCreator, authorizationType, testCase.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// the bigger bodies, like the payment files, aren't logged
+const maxLoggedBody = 16 << 10
+
+// logs a line per request, the bodies are only logged at the debug level and redacted
+func (server *Server) logRequest(ctx *gin.Context) {
+	start := time.Now()
+	logger := server.logger
+
+	var body []byte
+	if logger.Enabled(ctx, slog.LevelDebug) && ctx.Request.ContentLength > 0 && ctx.Request.ContentLength <= maxLoggedBody {
+		var err error
+		body, err = io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to read the request body", "error", err)
+		}
+		// restore the request body for the handlers
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	ctx.Next()
+
+	status := ctx.Writer.Status()
+	attrs := []any{
+		slog.String("request_id", utils.RequestId(ctx.Request.Context())),
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.Request.URL.Path),
+		slog.Int("status", status),
+		slog.Duration("latency", time.Since(start)),
+		slog.String("client_ip", ctx.ClientIP()),
+		slog.Int("size", ctx.Writer.Size()),
+	}
+	if query := ctx.Request.URL.Query(); len(query) > 0 {
+		attrs = append(attrs, slog.String("query", utils.RedactValues(query)))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx.Request.Context()); spanContext.IsValid() {
+		attrs = append(attrs, slog.String("trace_id", spanContext.TraceID().String()))
+	}
+	if username := server.requestUsername(ctx); username != "" {
+		attrs = append(attrs, slog.String("username", username))
+	}
+	if body != nil {
+		attrs = append(attrs, slog.String("body", utils.RedactBody(ctx.ContentType(), body)))
+	}
+	// the causes the problem responses don't show, see helpers.WriteError
+	if len(ctx.Errors) > 0 {
+		attrs = append(attrs, slog.String("error", ctx.Errors.Last().Error()))
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = slog.LevelError
+	case status >= http.StatusBadRequest:
+		level = slog.LevelWarn
+	}
+	logger.Log(ctx, level, "Request", attrs...)
+}
+
+// the user of the token, the gateway routes check it in the grpc interceptor so it's verified here again
+func (server *Server) requestUsername(ctx *gin.Context) string {
+	if payload, ok := ctx.Get(authorizationPayloadKey); ok {
+		return payload.(*token.Payload).Username
+	}
+
+	authorization := ctx.GetHeader(authorizationHeaderKey)
+	if authorization == "" {
+		return ""
+	}
+	payload, err := token.VerifyAuthorization(server.tokenCreator, authorization)
+	if err != nil {
+		return ""
+	}
+	return payload.Username
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRequest(t *testing.T) {
+	user := getRandomUser()
+	password := utils.GetRandomEmail()
+	account := getRandomAccount(user.Username)
+
+	hashedPassword, err := utils.GenerateHash(password)
+	require.NoError(t, err)
+	user.Password = hashedPassword
+
+	testCases := []struct {
+		testName   string
+		buildReq   func(t *testing.T, server *Server) *http.Request
+		buildStubs func(store *mockdb.MockStore)
+		checkLog   func(t *testing.T, output string, line map[string]any)
+	}{
+		{
+			testName: "LoginBodyRedacted",
+			buildReq: func(t *testing.T, server *Server) *http.Request {
+				data, err := json.Marshal(gin.H{"username": user.Username, "password": password})
+				require.NoError(t, err)
+
+				req := httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set(utils.RequestIdHeader, "login-request")
+				return req
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkLog: func(t *testing.T, output string, line map[string]any) {
+				require.NotContains(t, output, password)
+				require.Equal(t, "INFO", line["level"])
+				require.Equal(t, "login-request", line["request_id"])
+				require.Equal(t, float64(http.StatusOK), line["status"])
+				require.Contains(t, line["body"], user.Username)
+				require.Contains(t, line["body"], utils.Redacted)
+			},
+		},
+		{
+			testName: "InternalErrorWithUser",
+			buildReq: func(t *testing.T, server *Server) *http.Request {
+				url := fmt.Sprintf("/accounts/%d/limits?access_token=secret-token", account.ID)
+				req := httptest.NewRequest(http.MethodGet, url, nil)
+				addAuthorization(t, req, server.tokenCreator, authorizationType, user.Username, time.Minute)
+				return req
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, errors.New("pq: connection refused"))
+			},
+			checkLog: func(t *testing.T, output string, line map[string]any) {
+				require.NotContains(t, output, "secret-token")
+				require.Equal(t, "ERROR", line["level"])
+				require.Equal(t, user.Username, line["username"])
+				require.Equal(t, fmt.Sprintf("/accounts/%d/limits", account.ID), line["path"])
+				require.NotEmpty(t, line["request_id"])
+				// the cause hidden from the client
+				require.Contains(t, line["error"], "connection refused")
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			var output bytes.Buffer
+			server.logger, err = utils.NewLogger(&output, "debug", "json")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, testCase.buildReq(t, server))
+
+			var line map[string]any
+			require.NoError(t, json.Unmarshal(output.Bytes(), &line))
+			require.Equal(t, "Request", line[slog.MessageKey])
+			testCase.checkLog(t, output.String(), line)
+		})
+	}
+}
@@ -11,14 +11,26 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// the limits every user initiated transfer of the test server is sent with
+var testTransferLimits = db.TransferLimits{
+	MaxAmount:   100000,
+	DailyAmount: 500000,
+	HourlyCount: 10,
+}
+
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := utils.Config{
 		TokenKey:            utils.RandomString(32),
 		TokenExpireDuration: time.Minute,
+		TransferMaxAmount:   testTransferLimits.MaxAmount,
+		TransferDailyAmount: testTransferLimits.DailyAmount,
+		TransferHourlyCount: testTransferLimits.HourlyCount,
+		CashMaxAmount:       100000,
 	}
 	server, err := NewServer(config, store)
 
 	require.NoError(t, err)
+	t.Cleanup(server.Close)
 
 	return server
 }
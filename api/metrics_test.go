@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(controller))
+
+	// a request without a token
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/accounts/1/limits", nil))
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	require.Contains(t, body, `simple_bank_http_requests_total{method="GET",route="/accounts/:id/limits",status="401"}`)
+	require.Contains(t, body, `simple_bank_token_verification_failures_total{reason="missing"}`)
+	require.Contains(t, body, "go_goroutines")
+}
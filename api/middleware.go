@@ -1,19 +1,20 @@
 package api
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
-	"strings"
 
 	"github.com/AYehia0/go-bk-mst/api/helpers"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/metrics"
+	"github.com/AYehia0/go-bk-mst/problem"
 	"github.com/AYehia0/go-bk-mst/token"
 	"github.com/gin-gonic/gin"
 )
 
 var (
 	authorizationHeaderKey = "authorization"
-	authorizationType      = "bearer"
+	authorizationType      = token.AuthorizationTypeBearer
 
 	// to be able to store the token in the context, so we can access it later
 	authorizationPayloadKey = "authorization_payload_ctx"
@@ -21,40 +22,59 @@ var (
 
 func authMiddleware(tokenCreator token.TokenCreator) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		// implement the authentication here
 		// check the header : authentication
-		authHeader := ctx.Request.Header.Get(authorizationHeaderKey)
-		if len(authHeader) == 0 {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized,
-				helpers.ErrorResp(errors.New("Authentication header is empty")),
-			)
+		payload, err := token.VerifyAuthorization(tokenCreator, ctx.Request.Header.Get(authorizationHeaderKey))
+		if err != nil {
+			metrics.TokenFailure(err)
+			helpers.WriteError(ctx, problem.Wrap(http.StatusUnauthorized, problem.CodeUnauthenticated, err))
 			return
 		}
-		// get the token
-		authFields := strings.Fields(authHeader)
+		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Next()
+	}
+}
 
-		if len(authFields) < 2 {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized,
-				helpers.ErrorResp(errors.New("Invalid authentication header format")),
-			)
-			return
-		}
+// moves the access_token query param to the authorization header for the authMiddleware,
+// the EventSource and WebSocket of the browsers can't send headers
+func queryTokenMiddleware(ctx *gin.Context) {
+	if accessToken := ctx.Query("access_token"); accessToken != "" && ctx.GetHeader(authorizationHeaderKey) == "" {
+		ctx.Request.Header.Set(authorizationHeaderKey, authorizationType+" "+accessToken)
+	}
+	ctx.Next()
+}
 
-		// verifiy the token
-		authType := strings.ToLower(authFields[0])
-		if authType != authorizationType {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized,
-				helpers.ErrorResp(fmt.Errorf("Unspported authorization type %s", authType)),
-			)
-			return
-		}
+// only lets the logged-in users with one of the roles through, must come after the authMiddleware
+func roleMiddleware(store db.Store, roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-		payload, err := tokenCreator.Verify(authFields[1])
+		// the role is looked up on every request, so revoking it takes effect right away
+		user, err := store.GetUserByUsername(ctx, payload.Username)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, helpers.ErrorResp(err))
+			helpers.WriteError(ctx, err)
 			return
 		}
-		ctx.Set(authorizationPayloadKey, payload)
+
+		for _, role := range roles {
+			if user.Role == role {
+				ctx.Next()
+				return
+			}
+		}
+
+		helpers.WriteError(ctx, problem.Newf(http.StatusForbidden, problem.CodeForbidden, "The %s role isn't allowed to do this", user.Role))
+	}
+}
+
+// bounds the queries of the request, so it fails instead of piling up on the pool when the database is stuck
+func dbTimeoutsMiddleware(timeouts db.Timeouts) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Request = ctx.Request.WithContext(db.WithTimeouts(ctx.Request.Context(), timeouts))
 		ctx.Next()
 	}
 }
+
+// the panics answer with the same 500 as the other unexpected errors
+func recoverPanic(ctx *gin.Context, recovered any) {
+	helpers.WriteError(ctx, fmt.Errorf("panic: %v", recovered))
+}
@@ -1,14 +1,18 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
 	"github.com/AYehia0/go-bk-mst/token"
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -106,3 +110,41 @@ func TestAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestDbTimeoutsMiddleware(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	user := getRandomUser()
+	account := getRandomAccount(user.Username)
+	timeouts := db.Timeouts{Statement: 5 * time.Second, Lock: 2 * time.Second}
+
+	// the handlers pass the gin ctx, the timeouts of the request context have to reach the store through it
+	var storeCtx context.Context
+	store := mockdb.NewMockStore(controller)
+	store.EXPECT().
+		GetAccountById(gomock.Any(), gomock.Eq(account.ID)).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, id int64) (db.Account, error) {
+			storeCtx = ctx
+			return account, nil
+		})
+	store.EXPECT().
+		TransferAllowance(gomock.Any(), gomock.Eq(account), gomock.Any()).
+		Times(1).
+		Return(db.TransferAllowance{}, nil)
+
+	server := newTestServer(t, store)
+	server.config.DbStatementTimeout = timeouts.Statement
+	server.config.DbLockTimeout = timeouts.Lock
+	server.setupServer()
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/limits", account.ID), nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenCreator, authorizationType, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, timeouts, db.ContextTimeouts(storeCtx))
+}
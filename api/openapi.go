@@ -0,0 +1,86 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/AYehia0/go-bk-mst/doc/swagger"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+)
+
+// the OpenAPI 3 document of the routes in setupServer, keep it in sync when adding a route.
+// the responses of the gateway routes are merged in from the spec generated from the protos, see openAPIDocument
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// the document as served at /openapi.json
+func openAPIDocument() ([]byte, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	if err != nil {
+		return nil, err
+	}
+
+	gateway, err := gatewayOpenAPI()
+	if err != nil {
+		return nil, err
+	}
+	if err := mergeGatewayOpenAPI(doc, gateway); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// the spec generated from the protos, converted to OpenAPI 3
+func gatewayOpenAPI() (*openapi3.T, error) {
+	var doc openapi2.T
+	if err := json.Unmarshal(swagger.SimpleBank, &doc); err != nil {
+		return nil, err
+	}
+	return openapi2conv.ToV3(&doc)
+}
+
+// the gateway routes respond with the pb messages, their 200 responses and the schemas of the messages come from
+// the generated spec. the rest of the operations stays in openapi.yaml, the requests are bound by the gin
+// request structs and the errors are the problem documents of every route.
+func mergeGatewayOpenAPI(doc, gateway *openapi3.T) error {
+	for path, item := range gateway.Paths {
+		for method, generated := range item.Operations() {
+			var operation *openapi3.Operation
+			if documented := doc.Paths.Find(path); documented != nil {
+				operation = documented.GetOperation(method)
+			}
+			if operation == nil {
+				return fmt.Errorf("the gateway route %s %s is missing from openapi.yaml", method, path)
+			}
+
+			response := generated.Responses.Get(http.StatusOK)
+			if response == nil || response.Value == nil {
+				return fmt.Errorf("the gateway route %s %s has no 200 response", method, path)
+			}
+
+			documented := operation.Responses.Get(http.StatusOK)
+			if documented == nil || documented.Value == nil {
+				return fmt.Errorf("the gateway route %s %s has no 200 response in openapi.yaml", method, path)
+			}
+			documented.Value.Content = openapi3.NewContentWithJSONSchemaRef(response.Value.Content.Get("application/json").Schema)
+		}
+	}
+
+	for name, schema := range gateway.Components.Schemas {
+		if _, ok := doc.Components.Schemas[name]; ok {
+			return fmt.Errorf("the generated schema %s is already in openapi.yaml", name)
+		}
+		doc.Components.Schemas[name] = schema
+	}
+	return nil
+}
+
+func (server *Server) getOpenAPI(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", server.openAPI)
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// the routes serving the docs themselves
+var undocumentedRoutes = map[string]bool{
+	"GET /swagger/*any": true,
+}
+
+var ginPathParam = regexp.MustCompile(`:([^/]+)`)
+
+func loadOpenAPI(t *testing.T, server *Server) *openapi3.T {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	doc, err := openapi3.NewLoader().LoadFromData(recorder.Body.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+
+	return doc
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(controller))
+	doc := loadOpenAPI(t, server)
+
+	documented := map[string]bool{}
+	for path, item := range doc.Paths {
+		for method := range item.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+
+	for _, route := range server.router.Routes() {
+		key := route.Method + " " + route.Path
+		if undocumentedRoutes[key] {
+			continue
+		}
+
+		// /accounts/:id -> /accounts/{id}
+		key = route.Method + " " + ginPathParam.ReplaceAllString(route.Path, "{$1}")
+		require.True(t, documented[key], "route %s is missing from the OpenAPI document", key)
+		delete(documented, key)
+	}
+
+	require.Empty(t, documented, "the OpenAPI document has routes the server doesn't register")
+}
+
+// the gateway routes are documented with the schemas generated from the protos, and their requests and
+// parameters in openapi.yaml name the same fields as the generated ones
+func TestOpenAPIGatewaySchemas(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(controller))
+	doc := loadOpenAPI(t, server)
+
+	gateway, err := gatewayOpenAPI()
+	require.NoError(t, err)
+	require.NotEmpty(t, gateway.Paths)
+
+	for path, item := range gateway.Paths {
+		for method, generated := range item.Operations() {
+			documented := doc.Paths.Find(path).GetOperation(method)
+			require.NotNil(t, documented, "%s %s", method, path)
+
+			want, err := json.Marshal(generated.Responses.Get(http.StatusOK).Value.Content.Get("application/json").Schema)
+			require.NoError(t, err)
+			got, err := json.Marshal(documented.Responses.Get(http.StatusOK).Value.Content.Get("application/json").Schema)
+			require.NoError(t, err)
+			require.JSONEq(t, string(want), string(got), "%s %s", method, path)
+
+			if generated.RequestBody != nil {
+				require.NotNil(t, documented.RequestBody, "%s %s", method, path)
+				require.ElementsMatch(t,
+					schemaProperties(gateway, generated.RequestBody.Value.Content.Get("application/json").Schema),
+					schemaProperties(doc, documented.RequestBody.Value.Content.Get("application/json").Schema),
+					"%s %s", method, path,
+				)
+			}
+			require.ElementsMatch(t, parameterNames(generated.Parameters), parameterNames(documented.Parameters), "%s %s", method, path)
+		}
+	}
+}
+
+// the bodies recorded from the gin handlers match the generated schemas
+func TestOpenAPIGatewayGolden(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(controller))
+	doc := loadOpenAPI(t, server)
+
+	goldenOperations := map[string]string{
+		"create_user.json":         "POST /users",
+		"login_user.json":          "POST /users/login",
+		"renew_token.json":         "POST /token/renew_token",
+		"create_account.json":      "POST /accounts",
+		"get_account.json":         "GET /accounts/{id}",
+		"list_accounts.json":       "GET /accounts",
+		"list_accounts_empty.json": "GET /accounts",
+		"create_transfer.json":     "POST /transfers",
+	}
+
+	for golden, operation := range goldenOperations {
+		method, path, _ := strings.Cut(operation, " ")
+
+		data, err := os.ReadFile(filepath.Join("testdata", "gateway", golden))
+		require.NoError(t, err)
+		// the masked expiries keep their zone
+		data = bytes.ReplaceAll(data, []byte("<time>"), []byte("2023-08-01T10:30:00.123456"))
+
+		var body any
+		require.NoError(t, json.Unmarshal(data, &body))
+
+		schema := doc.Paths.Find(path).GetOperation(method).Responses.Get(http.StatusOK).Value.Content.Get("application/json").Schema
+		require.NoError(t, schema.Value.VisitJSON(body), golden)
+	}
+}
+
+func schemaProperties(doc *openapi3.T, schema *openapi3.SchemaRef) []string {
+	if schema.Value == nil {
+		schema = doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+
+	var names []string
+	for name := range schema.Value.Properties {
+		names = append(names, name)
+	}
+	return names
+}
+
+func parameterNames(parameters openapi3.Parameters) []string {
+	var names []string
+	for _, parameter := range parameters {
+		names = append(names, parameter.Value.In+" "+parameter.Value.Name)
+	}
+	return names
+}
+
+func TestSwaggerUI(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(controller))
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/swagger/index.html", nil)
+
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// the ui loads the served document
+	require.Contains(t, recorder.Body.String(), "openapi.json")
+}
@@ -0,0 +1,370 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/api/helpers"
+	"github.com/AYehia0/go-bk-mst/bulk"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/gin-gonic/gin"
+)
+
+const maxPaymentFileSize = 5 << 20
+
+type uploadPaymentFileReq struct {
+	// detected from the file name/content when missing
+	Format string `form:"format" binding:"omitempty,oneof=csv pain.001"`
+}
+
+type paymentInstructionResp struct {
+	ID            int64  `json:"id"`
+	EndToEndId    string `json:"end_to_end_id"`
+	FromAccountId int64  `json:"from_account_id"`
+	ToAccountId   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+	TransferId    *int64 `json:"transfer_id,omitempty"`
+}
+
+func newPaymentInstructionResp(instruction db.PaymentInstruction) paymentInstructionResp {
+	resp := paymentInstructionResp{
+		ID:            instruction.ID,
+		EndToEndId:    instruction.EndToEndID,
+		FromAccountId: instruction.FromAccountID,
+		ToAccountId:   instruction.ToAccountID,
+		Amount:        instruction.Amount,
+		Currency:      instruction.Currency,
+		Status:        instruction.Status,
+		Error:         instruction.Error,
+	}
+	if instruction.TransferID.Valid {
+		resp.TransferId = &instruction.TransferID.Int64
+	}
+	return resp
+}
+
+type paymentFileSummary struct {
+	Total       int   `json:"total"`
+	Valid       int   `json:"valid"`
+	Invalid     int   `json:"invalid"`
+	ValidAmount int64 `json:"valid_amount"`
+}
+
+type paymentFileResp struct {
+	File         db.PaymentFile           `json:"file"`
+	Summary      paymentFileSummary       `json:"summary"`
+	Instructions []paymentInstructionResp `json:"instructions"`
+}
+
+// parses and validates an uploaded payment file, nothing is executed until the file is confirmed
+func (server *Server) uploadPaymentFile(ctx *gin.Context) {
+	var req uploadPaymentFileReq
+
+	if err := ctx.ShouldBind(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+	if header.Size > maxPaymentFileSize {
+		helpers.WriteError(ctx, problem.Newf(http.StatusBadRequest, problem.CodeInvalidArgument, "Payment file is larger than %d bytes", maxPaymentFileSize))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = bulk.DetectFormat(header.Filename, content)
+	}
+
+	parsed, err := bulk.Parse(format, bytes.NewReader(content))
+	if err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.CreatePaymentFileTxParams{
+		CreatePaymentFileParams: db.CreatePaymentFileParams{
+			OwnerName: payload.Username,
+			Format:    parsed.Format,
+			MessageID: parsed.MessageId,
+		},
+	}
+	for _, instruction := range parsed.Instructions {
+		status := db.PaymentInstructionStatusValid
+		errMsg := ""
+		if err := server.checkInstruction(ctx, payload.Username, instruction.FromAccountId, instruction.ToAccountId, instruction.Amount, instruction.Currency); err != nil {
+			status = db.PaymentInstructionStatusInvalid
+			errMsg = problem.From(err).Message
+		}
+
+		arg.Instructions = append(arg.Instructions, db.CreatePaymentInstructionParams{
+			EndToEndID:    instruction.EndToEndId,
+			FromAccountID: instruction.FromAccountId,
+			ToAccountID:   instruction.ToAccountId,
+			Amount:        instruction.Amount,
+			Currency:      instruction.Currency,
+			Status:        status,
+			Error:         errMsg,
+		})
+	}
+
+	result, err := server.store.CreatePaymentFileTransaction(ctx, arg)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPaymentFileResp(result.File, result.Instructions))
+}
+
+type paymentFileReq struct {
+	Id int64 `uri:"id" binding:"required,min=1"`
+}
+
+// executes the valid instructions of a previewed file and responds with the pain.002 status report.
+// a file left processing, by a crash or a lost connection, is resumed by confirming it again
+func (server *Server) confirmPaymentFile(ctx *gin.Context) {
+	file, ok := server.getOwnedPaymentFile(ctx)
+	if !ok {
+		return
+	}
+
+	switch file.Status {
+	case db.PaymentFileStatusExecuted:
+		helpers.WriteError(ctx, problem.New(http.StatusConflict, problem.CodeConflict, "Payment file has already been confirmed"))
+		return
+	case db.PaymentFileStatusPreview:
+		// only one confirmation can move the file out of the preview
+		var err error
+		file, err = server.store.UpdatePaymentFileStatus(ctx, db.UpdatePaymentFileStatusParams{
+			ID:         file.ID,
+			FromStatus: db.PaymentFileStatusPreview,
+			Status:     db.PaymentFileStatusProcessing,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				helpers.WriteError(ctx, problem.New(http.StatusConflict, problem.CodeConflict, "Payment file has already been confirmed"))
+				return
+			}
+			helpers.WriteError(ctx, err)
+			return
+		}
+	}
+
+	// the client going away doesn't stop the file half way
+	execCtx := context.WithoutCancel(ctx)
+
+	instructions, err := server.executePaymentFile(execCtx, file)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	file, err = server.store.UpdatePaymentFileStatus(execCtx, db.UpdatePaymentFileStatusParams{
+		ID:         file.ID,
+		FromStatus: db.PaymentFileStatusProcessing,
+		Status:     db.PaymentFileStatusExecuted,
+	})
+	if err == sql.ErrNoRows {
+		// a concurrent resume finished it first
+		file, err = server.store.GetPaymentFileById(execCtx, file.ID)
+	}
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	writeStatusReport(ctx, file, instructions)
+}
+
+// runs the instructions still valid, each transfer is committed with its instruction.
+// the ones failing a check are marked failed, an internal error stops the file so it can be resumed
+func (server *Server) executePaymentFile(ctx context.Context, file db.PaymentFile) ([]db.PaymentInstruction, error) {
+	instructions, err := server.store.GetPaymentInstructions(ctx, file.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, instruction := range instructions {
+		if instruction.Status != db.PaymentInstructionStatusValid {
+			continue
+		}
+
+		// the accounts might have changed since the preview
+		err := server.checkInstruction(ctx, file.OwnerName, instruction.FromAccountID, instruction.ToAccountID, instruction.Amount, instruction.Currency)
+		if err == nil {
+			_, err = server.store.ExecutePaymentInstructionTransaction(ctx, instruction.ID, db.DefaultTransferLimits(server.config))
+		}
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, db.ErrPaymentInstructionNotValid) {
+			// executed by a concurrent resume
+			continue
+		}
+
+		failure := problem.From(err)
+		if failure.Status >= http.StatusInternalServerError {
+			return nil, err
+		}
+
+		_, err = server.store.UpdatePaymentInstruction(ctx, db.UpdatePaymentInstructionParams{
+			ID:     instruction.ID,
+			Status: db.PaymentInstructionStatusFailed,
+			Error:  failure.Message,
+		})
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
+	// read again, with the instructions a concurrent resume ran as they ended
+	return server.store.GetPaymentInstructions(ctx, file.ID)
+}
+
+// the pain.002 status report of a file, before or after it's confirmed
+func (server *Server) getPaymentFileReport(ctx *gin.Context) {
+	file, ok := server.getOwnedPaymentFile(ctx)
+	if !ok {
+		return
+	}
+
+	instructions, err := server.store.GetPaymentInstructions(ctx, file.ID)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	writeStatusReport(ctx, file, instructions)
+}
+
+func (server *Server) getOwnedPaymentFile(ctx *gin.Context) (db.PaymentFile, bool) {
+	var req paymentFileReq
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return db.PaymentFile{}, false
+	}
+
+	file, err := server.store.GetPaymentFileById(ctx, req.Id)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return file, false
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if file.OwnerName != payload.Username {
+		helpers.WriteError(ctx, problem.New(http.StatusUnauthorized, problem.CodePermissionDenied, "Payment file doesn't belong to the logged-in user!"))
+		return file, false
+	}
+	return file, true
+}
+
+// the same rules as a single transfer: both accounts exist with the same currency and the money leaves the user's account
+func (server *Server) checkInstruction(ctx context.Context, username string, fromAccountId, toAccountId, amount int64, currency string) error {
+	if amount <= 0 {
+		return problem.New(http.StatusBadRequest, problem.CodeInvalidArgument, "Amount must be positive")
+	}
+
+	if _, err := db.CheckTransferAccount(ctx, server.store, toAccountId, currency); err != nil {
+		return err
+	}
+
+	fromAccount, err := db.CheckTransferAccount(ctx, server.store, fromAccountId, currency)
+	if err != nil {
+		return err
+	}
+
+	if fromAccount.OwnerName != username {
+		return problem.New(http.StatusUnauthorized, problem.CodePermissionDenied, "from_account doesn't belong to the logged-in user!")
+	}
+	return nil
+}
+
+func newPaymentFileResp(file db.PaymentFile, instructions []db.PaymentInstruction) paymentFileResp {
+	resp := paymentFileResp{
+		File:         file,
+		Instructions: make([]paymentInstructionResp, 0, len(instructions)),
+	}
+
+	for _, instruction := range instructions {
+		resp.Instructions = append(resp.Instructions, newPaymentInstructionResp(instruction))
+
+		resp.Summary.Total++
+		if instruction.Status == db.PaymentInstructionStatusInvalid {
+			resp.Summary.Invalid++
+			continue
+		}
+		resp.Summary.Valid++
+		resp.Summary.ValidAmount += instruction.Amount
+	}
+	return resp
+}
+
+func writeStatusReport(ctx *gin.Context, file db.PaymentFile, instructions []db.PaymentInstruction) {
+	messageId := file.MessageID
+	if messageId == "" {
+		messageId = fmt.Sprintf("FILE-%d", file.ID)
+	}
+
+	report := bulk.StatusReport{
+		MessageId:         fmt.Sprintf("RPT-%d-%d", file.ID, time.Now().Unix()),
+		CreatedAt:         time.Now(),
+		OriginalMessageId: messageId,
+		OriginalFormat:    file.Format,
+	}
+
+	for _, instruction := range instructions {
+		status := bulk.StatusAccepted
+		switch instruction.Status {
+		case db.PaymentInstructionStatusCompleted:
+			status = bulk.StatusSettled
+		case db.PaymentInstructionStatusInvalid, db.PaymentInstructionStatusFailed:
+			status = bulk.StatusRejected
+		}
+
+		report.Transactions = append(report.Transactions, bulk.TransactionStatus{
+			EndToEndId: instruction.EndToEndID,
+			Status:     status,
+			Reason:     instruction.Error,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteXML(&buf); err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/xml; charset=utf-8", buf.Bytes())
+}
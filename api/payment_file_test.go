@@ -0,0 +1,450 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/bulk"
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// builds a multipart request uploading one of the bulk fixtures
+func newPaymentFileRequest(t *testing.T, fixture string) *http.Request {
+	content, err := os.ReadFile(filepath.Join("..", "bulk", "testdata", fixture))
+	require.NoError(t, err)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", fixture)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/transfers/files", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+// the statuses of the transactions in a pain.002 report
+func readStatusReport(t *testing.T, body io.Reader) (string, map[string]string) {
+	var doc struct {
+		GrpSts string `xml:"CstmrPmtStsRpt>OrgnlGrpInfAndSts>GrpSts"`
+		Txs    []struct {
+			EndToEndId string `xml:"OrgnlEndToEndId"`
+			Status     string `xml:"TxSts"`
+		} `xml:"CstmrPmtStsRpt>OrgnlPmtInfAndSts>TxInfAndSts"`
+	}
+	require.NoError(t, xml.NewDecoder(body).Decode(&doc))
+
+	statuses := map[string]string{}
+	for _, tx := range doc.Txs {
+		statuses[tx.EndToEndId] = tx.Status
+	}
+	return doc.GrpSts, statuses
+}
+
+func TestUploadPaymentFileAPI(t *testing.T) {
+	user := getRandomUser()
+	other := getRandomUser()
+
+	// payroll.csv pays from account 1 to the accounts 2, 3 and 4
+	account1 := db.Account{ID: 1, OwnerName: user.Username, Currency: utils.USD}
+	account2 := db.Account{ID: 2, OwnerName: other.Username, Currency: utils.USD}
+	account3 := db.Account{ID: 3, OwnerName: other.Username, Currency: utils.EUR}
+
+	stubAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).AnyTimes().Return(account1, nil)
+		store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).AnyTimes().Return(account2, nil)
+		store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account3.ID)).AnyTimes().Return(account3, nil)
+		store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(int64(4))).AnyTimes().Return(db.Account{}, sql.ErrNoRows)
+	}
+
+	testCases := []struct {
+		testName   string
+		fixture    string
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "CSV/Preview",
+			fixture:  "payroll.csv",
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().
+					CreatePaymentFileTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreatePaymentFileTxParams) (db.PaymentFileTxResult, error) {
+						require.Equal(t, user.Username, arg.OwnerName)
+						require.Equal(t, bulk.FormatCSV, arg.Format)
+						require.Len(t, arg.Instructions, 3)
+
+						result := db.PaymentFileTxResult{File: db.PaymentFile{ID: 1, OwnerName: arg.OwnerName, Format: arg.Format}}
+						for i, instruction := range arg.Instructions {
+							result.Instructions = append(result.Instructions, db.PaymentInstruction{
+								ID:            int64(i + 1),
+								EndToEndID:    instruction.EndToEndID,
+								FromAccountID: instruction.FromAccountID,
+								ToAccountID:   instruction.ToAccountID,
+								Amount:        instruction.Amount,
+								Currency:      instruction.Currency,
+								Status:        instruction.Status,
+								Error:         instruction.Error,
+							})
+						}
+						return result, nil
+					})
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp paymentFileResp
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, 3, resp.Summary.Total)
+				require.Equal(t, 1, resp.Summary.Valid)
+				require.Equal(t, 2, resp.Summary.Invalid)
+				require.Equal(t, int64(150000), resp.Summary.ValidAmount)
+
+				require.Equal(t, db.PaymentInstructionStatusValid, resp.Instructions[0].Status)
+				// currency mismatch
+				require.Equal(t, db.PaymentInstructionStatusInvalid, resp.Instructions[1].Status)
+				require.NotEmpty(t, resp.Instructions[1].Error)
+				// account not found
+				require.Equal(t, db.PaymentInstructionStatusInvalid, resp.Instructions[2].Status)
+			},
+		},
+		{
+			testName: "BadRequest/InvalidFile",
+			fixture:  "invalid_amount.csv",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePaymentFileTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "Pain001/Preview",
+			fixture:  "pain001.xml",
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(int64(5))).AnyTimes().Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreatePaymentFileTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreatePaymentFileTxParams) (db.PaymentFileTxResult, error) {
+						require.Equal(t, bulk.FormatPain001, arg.Format)
+						require.Equal(t, "MSG-2023-0001", arg.MessageID)
+						require.Len(t, arg.Instructions, 3)
+						return db.PaymentFileTxResult{}, nil
+					})
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			req := newPaymentFileRequest(t, testCase.fixture)
+			addAuthorization(t, req, server.tokenCreator, authorizationType, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
+
+func TestConfirmPaymentFileAPI(t *testing.T) {
+	user := getRandomUser()
+	other := getRandomUser()
+
+	account1 := db.Account{ID: 1, OwnerName: user.Username, Currency: utils.USD}
+	account2 := db.Account{ID: 2, OwnerName: other.Username, Currency: utils.USD}
+
+	file := db.PaymentFile{ID: 7, OwnerName: user.Username, Format: bulk.FormatCSV, Status: db.PaymentFileStatusPreview}
+	instructions := []db.PaymentInstruction{
+		{ID: 1, FileID: file.ID, EndToEndID: "PAYROLL-1", FromAccountID: 1, ToAccountID: 2, Amount: 100, Currency: utils.USD, Status: db.PaymentInstructionStatusValid},
+		{ID: 2, FileID: file.ID, EndToEndID: "PAYROLL-2", FromAccountID: 1, ToAccountID: 3, Amount: 100, Currency: utils.USD, Status: db.PaymentInstructionStatusInvalid, Error: "not found"},
+	}
+
+	testCases := []struct {
+		testName   string
+		username   string
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentFileById(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return(file, nil)
+				store.EXPECT().
+					UpdatePaymentFileStatus(gomock.Any(), gomock.Eq(db.UpdatePaymentFileStatusParams{
+						ID:         file.ID,
+						FromStatus: db.PaymentFileStatusPreview,
+						Status:     db.PaymentFileStatusProcessing,
+					})).
+					Times(1).
+					Return(file, nil)
+				store.EXPECT().GetPaymentInstructions(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return(instructions, nil)
+
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				// only the valid instruction is executed
+				completed := instructions[0]
+				completed.Status = db.PaymentInstructionStatusCompleted
+				completed.TransferID = sql.NullInt64{Int64: 99, Valid: true}
+				store.EXPECT().
+					ExecutePaymentInstructionTransaction(gomock.Any(), gomock.Eq(instructions[0].ID), gomock.Eq(&testTransferLimits)).
+					Times(1).
+					Return(completed, nil)
+				store.EXPECT().GetPaymentInstructions(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return([]db.PaymentInstruction{completed, instructions[1]}, nil)
+
+				executed := file
+				executed.Status = db.PaymentFileStatusExecuted
+				store.EXPECT().
+					UpdatePaymentFileStatus(gomock.Any(), gomock.Eq(db.UpdatePaymentFileStatusParams{
+						ID:         file.ID,
+						FromStatus: db.PaymentFileStatusProcessing,
+						Status:     db.PaymentFileStatusExecuted,
+					})).
+					Times(1).
+					Return(executed, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "application/xml")
+
+				group, statuses := readStatusReport(t, recorder.Body)
+				require.Equal(t, bulk.StatusPartial, group)
+				require.Equal(t, bulk.StatusSettled, statuses["PAYROLL-1"])
+				require.Equal(t, bulk.StatusRejected, statuses["PAYROLL-2"])
+			},
+		},
+		{
+			testName: "Resume",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				processing := file
+				processing.Status = db.PaymentFileStatusProcessing
+				store.EXPECT().GetPaymentFileById(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return(processing, nil)
+
+				// the file is already out of the preview, the instructions left valid are run
+				completed := instructions[0]
+				completed.Status = db.PaymentInstructionStatusCompleted
+				remaining := db.PaymentInstruction{ID: 3, FileID: file.ID, EndToEndID: "PAYROLL-3", FromAccountID: 1, ToAccountID: 2, Amount: 50, Currency: utils.USD, Status: db.PaymentInstructionStatusValid}
+				store.EXPECT().GetPaymentInstructions(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return([]db.PaymentInstruction{completed, instructions[1], remaining}, nil)
+
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().ExecutePaymentInstructionTransaction(gomock.Any(), gomock.Eq(instructions[0].ID), gomock.Any()).Times(0)
+
+				executedRemaining := remaining
+				executedRemaining.Status = db.PaymentInstructionStatusCompleted
+				store.EXPECT().
+					ExecutePaymentInstructionTransaction(gomock.Any(), gomock.Eq(remaining.ID), gomock.Any()).
+					Times(1).
+					Return(executedRemaining, nil)
+				store.EXPECT().GetPaymentInstructions(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return([]db.PaymentInstruction{completed, instructions[1], executedRemaining}, nil)
+
+				executed := file
+				executed.Status = db.PaymentFileStatusExecuted
+				store.EXPECT().
+					UpdatePaymentFileStatus(gomock.Any(), gomock.Eq(db.UpdatePaymentFileStatusParams{
+						ID:         file.ID,
+						FromStatus: db.PaymentFileStatusProcessing,
+						Status:     db.PaymentFileStatusExecuted,
+					})).
+					Times(1).
+					Return(executed, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				_, statuses := readStatusReport(t, recorder.Body)
+				require.Equal(t, bulk.StatusSettled, statuses["PAYROLL-1"])
+				require.Equal(t, bulk.StatusSettled, statuses["PAYROLL-3"])
+			},
+		},
+		{
+			testName: "InstructionFailed",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentFileById(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return(file, nil)
+				store.EXPECT().UpdatePaymentFileStatus(gomock.Any(), gomock.Any()).Times(1).Return(file, nil)
+				store.EXPECT().GetPaymentInstructions(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return(instructions, nil)
+
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					ExecutePaymentInstructionTransaction(gomock.Any(), gomock.Eq(instructions[0].ID), gomock.Any()).
+					Times(1).
+					Return(db.PaymentInstruction{}, db.ErrInsufficientFunds)
+
+				failed := instructions[0]
+				failed.Status = db.PaymentInstructionStatusFailed
+				failed.Error = db.ErrInsufficientFunds.Error()
+				store.EXPECT().
+					UpdatePaymentInstruction(gomock.Any(), gomock.Eq(db.UpdatePaymentInstructionParams{
+						ID:     instructions[0].ID,
+						Status: db.PaymentInstructionStatusFailed,
+						Error:  db.ErrInsufficientFunds.Error(),
+					})).
+					Times(1).
+					Return(failed, nil)
+				store.EXPECT().GetPaymentInstructions(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return([]db.PaymentInstruction{failed, instructions[1]}, nil)
+
+				store.EXPECT().UpdatePaymentFileStatus(gomock.Any(), gomock.Any()).Times(1).Return(file, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				group, statuses := readStatusReport(t, recorder.Body)
+				require.Equal(t, bulk.StatusRejected, group)
+				require.Equal(t, bulk.StatusRejected, statuses["PAYROLL-1"])
+			},
+		},
+		{
+			testName: "InternalErrorLeavesProcessing",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentFileById(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return(file, nil)
+				store.EXPECT().UpdatePaymentFileStatus(gomock.Any(), gomock.Any()).Times(1).Return(file, nil)
+				store.EXPECT().GetPaymentInstructions(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return(instructions, nil)
+
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					ExecutePaymentInstructionTransaction(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PaymentInstruction{}, sql.ErrConnDone)
+
+				// the instruction stays valid and the file processing, confirming again resumes it
+				store.EXPECT().UpdatePaymentInstruction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			testName: "Executed",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				executed := file
+				executed.Status = db.PaymentFileStatusExecuted
+				store.EXPECT().GetPaymentFileById(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return(executed, nil)
+				store.EXPECT().UpdatePaymentFileStatus(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetPaymentInstructions(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			testName: "AlreadyConfirmed",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentFileById(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return(file, nil)
+				store.EXPECT().
+					UpdatePaymentFileStatus(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PaymentFile{}, sql.ErrNoRows)
+				store.EXPECT().ExecutePaymentInstructionTransaction(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			testName: "Unauthorized",
+			username: other.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentFileById(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return(file, nil)
+				store.EXPECT().UpdatePaymentFileStatus(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			urlPath := fmt.Sprintf("/transfers/files/%d/confirm", file.ID)
+			req := httptest.NewRequest(http.MethodPost, urlPath, nil)
+			addAuthorization(t, req, server.tokenCreator, authorizationType, testCase.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
+
+func TestGetPaymentFileReportAPI(t *testing.T) {
+	user := getRandomUser()
+	file := db.PaymentFile{ID: 3, OwnerName: user.Username, Format: bulk.FormatPain001, MessageID: "MSG-1"}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	store := mockdb.NewMockStore(controller)
+	store.EXPECT().GetPaymentFileById(gomock.Any(), gomock.Eq(file.ID)).Times(1).Return(file, nil)
+	store.EXPECT().
+		GetPaymentInstructions(gomock.Any(), gomock.Eq(file.ID)).
+		Times(1).
+		Return([]db.PaymentInstruction{
+			{EndToEndID: "E2E-1", Status: db.PaymentInstructionStatusValid},
+			{EndToEndID: "E2E-2", Status: db.PaymentInstructionStatusValid},
+		}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/transfers/files/%d/report", file.ID), nil)
+	addAuthorization(t, req, server.tokenCreator, authorizationType, user.Username, time.Minute)
+
+	server.router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// nothing is executed before the confirmation
+	group, statuses := readStatusReport(t, recorder.Body)
+	require.Equal(t, bulk.StatusAccepted, group)
+	require.Equal(t, bulk.StatusAccepted, statuses["E2E-1"])
+}
@@ -1,14 +1,28 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
 
+	"github.com/AYehia0/go-bk-mst/api/helpers"
+	"github.com/AYehia0/go-bk-mst/db/migrations"
 	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/gapi"
+	"github.com/AYehia0/go-bk-mst/metrics"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/statement"
+	"github.com/AYehia0/go-bk-mst/stream"
 	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/AYehia0/go-bk-mst/tracing"
 	"github.com/AYehia0/go-bk-mst/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 type Server struct {
@@ -16,6 +30,17 @@ type Server struct {
 	tokenCreator token.TokenCreator
 	config       utils.Config
 	router       *gin.Engine
+	// the live account activity of the connected clients
+	hub *stream.Hub
+	// the routes generated from the protos, served by the gRPC handlers
+	gateway http.Handler
+	// stops the in-memory gRPC server of the gateway
+	stopGateway context.CancelFunc
+	// the json of the OpenAPI document
+	openAPI []byte
+	logger  *slog.Logger
+	// the version of the newest embedded migration, the readiness probe waits for the database to reach it
+	schemaVersion uint
 }
 
 func NewServer(config utils.Config, store db.Store) (*Server, error) {
@@ -26,16 +51,49 @@ func NewServer(config utils.Config, store db.Store) (*Server, error) {
 	if err != nil {
 		return nil, err
 	}
+	grpcServer, err := gapi.NewServer(config, store)
+
+	if err != nil {
+		return nil, err
+	}
+	openAPI, err := openAPIDocument()
+
+	if err != nil {
+		return nil, err
+	}
+	schemaVersion, err := migrations.Latest()
+
+	if err != nil {
+		return nil, err
+	}
+	// the gateway lives until the server is shut down or closed
+	gatewayCtx, stopGateway := context.WithCancel(context.Background())
+	gateway, err := grpcServer.Gateway(gatewayCtx)
+
+	if err != nil {
+		stopGateway()
+		return nil, err
+	}
 	server := &Server{
-		store:        store,
-		tokenCreator: creator,
-		config:       config,
+		store:         store,
+		tokenCreator:  creator,
+		config:        config,
+		hub:           stream.NewHub(),
+		gateway:       gateway,
+		stopGateway:   stopGateway,
+		openAPI:       openAPI,
+		logger:        slog.Default(),
+		schemaVersion: schemaVersion,
 	}
 
 	// registering validators
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		fmt.Println("Registering Validation Functions")
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("webhook_url", validWebhookUrl)
+		v.RegisterValidation("webhook_event", validWebhookEvent)
+		// the field errors name the fields as the clients send them
+		v.RegisterTagNameFunc(requestFieldName)
 	}
 
 	server.setupServer()
@@ -43,31 +101,149 @@ func NewServer(config utils.Config, store db.Store) (*Server, error) {
 	return server, nil
 }
 
-func (server *Server) StartServer(addressUrl string) error {
-	return server.router.Run(addressUrl)
+// the hub the account activity has to be broadcast to, see stream.Listen
+func (server *Server) ActivityHub() *stream.Hub {
+	return server.hub
+}
+
+// stops the gateway of a server that won't be started, StartServer stops it once shut down
+func (server *Server) Close() {
+	server.stopGateway()
+}
+
+// serves until the ctx is done, then stops taking new connections and waits up to the SHUTDOWN_TIMEOUT
+// for the requests in flight, the activity streams are ended so they don't hold the shutdown.
+func (server *Server) StartServer(ctx context.Context, addressUrl string) error {
+	listener, err := net.Listen("tcp", addressUrl)
+	if err != nil {
+		return err
+	}
+	return server.serve(ctx, listener)
+}
+
+func (server *Server) serve(ctx context.Context, listener net.Listener) error {
+	defer server.Close()
+
+	httpServer := &http.Server{
+		Handler:      server.router,
+		ReadTimeout:  server.config.ServerReadTimeout,
+		WriteTimeout: server.config.ServerWriteTimeout,
+		IdleTimeout:  server.config.ServerIdleTimeout,
+	}
+	httpServer.RegisterOnShutdown(server.hub.Close)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), server.config.ShutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to drain the requests: %w", err)
+	}
+	// http.ErrServerClosed, the server was shut down as asked
+	<-serveErr
+	return nil
 }
 
 // define the routes
 func (server *Server) setupServer() {
 	// methods
-	router := gin.Default()
-
-	// middlewares
-	router.Use(utils.LogRequestBodyMiddleware)
-
-	// requires no login
-	router.POST("/users", server.createUser)
-	router.POST("/users/login", server.loginUser)
-	router.POST("/token/renew_token", server.requestNewAccessToken)
+	router := gin.New()
+	// the handlers pass the gin ctx to the store, it has to carry the values and the deadline of the request context
+	router.ContextWithFallback = true
+
+	// middlewares, the request id comes first so every error body carries it
+	router.Use(
+		utils.RequestIdMiddleware, tracing.Middleware, server.logRequest, metrics.Middleware, gin.CustomRecovery(recoverPanic),
+		dbTimeoutsMiddleware(db.Timeouts{Statement: server.config.DbStatementTimeout, Lock: server.config.DbLockTimeout}),
+	)
+
+	router.NoRoute(func(ctx *gin.Context) {
+		helpers.WriteError(ctx, problem.New(http.StatusNotFound, problem.CodeNotFound, "Route not found"))
+	})
+
+	// the routes of the SimpleBank service, the grpc auth interceptor checks the tokens of the ones requiring a login.
+	// the requests are checked as the gin handlers did before reaching the gateway, the token first, then the binding
+	gateway := gin.WrapH(server.gateway)
+	gatewayAuth := authMiddleware(server.tokenCreator)
+
+	router.POST("/users", bindGatewayJSON(func() any { return &userReq{} }), gateway)
+	router.POST("/users/login", bindGatewayJSON(func() any { return &userLoginReq{} }), gateway)
+	router.POST("/token/renew_token", bindGatewayJSON(func() any { return &renewTokenReq{} }), gateway)
+	router.POST("/accounts", gatewayAuth, bindGatewayJSON(func() any { return &createAccountReq{} }), gateway)
+	router.GET("/accounts/:id", gatewayAuth, bindGatewayUri(func() any { return &getAccountReq{} }), gateway)
+	router.GET("/accounts", gatewayAuth, bindGatewayQuery(func() any { return &getAccountsReq{} }), gateway)
+	router.POST("/transfers", gatewayAuth, bindGatewayJSON(func() any { return &createTransferReq{} }), gateway)
+
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/healthz", server.healthz)
+	router.GET("/readyz", server.readyz)
+
+	// the docs
+	router.GET("/openapi.json", server.getOpenAPI)
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, ginSwagger.URL("/openapi.json")))
 
 	// create a group for them
 	authRequired := router.Group("/").Use(authMiddleware(server.tokenCreator))
 
-	authRequired.POST("/accounts", server.createAccount)
-	authRequired.GET("/accounts/:id", server.getAccount)
-	authRequired.GET("/accounts", server.getAccounts)
-
-	authRequired.POST("/transfers", server.createTransfer)
+	// the browsers can't set the headers of the streams, the token might be in the query instead
+	streamRequired := router.Group("/").Use(queryTokenMiddleware, authMiddleware(server.tokenCreator))
+
+	streamRequired.GET("/accounts/activity", server.streamActivity)
+	streamRequired.GET("/accounts/activity/ws", server.streamActivityWebSocket)
+
+	authRequired.GET("/accounts/:id/entries", server.getAccountEntries)
+	authRequired.GET("/accounts/:id/limits", server.getAccountLimits)
+	authRequired.GET("/accounts/:id/balance", server.getAccountBalance)
+	authRequired.GET("/accounts/:id/statement.csv", server.getStatement(statement.FormatCSV))
+	authRequired.GET("/accounts/:id/statement.ofx", server.getStatement(statement.FormatOFX))
+	authRequired.GET("/accounts/:id/statement.pdf", server.getStatement(statement.FormatPDF))
+	authRequired.PUT("/accounts/:id/interest_plan", server.setAccountInterestPlan)
+
+	authRequired.GET("/transfers", server.getTransfers)
+	authRequired.GET("/transfers/fees", server.previewFee)
+	authRequired.POST("/transfers/batch", server.createBatchTransfer)
+	authRequired.POST("/transfers/:id/reverse", server.reverseTransfer)
+	authRequired.POST("/transfers/files", server.uploadPaymentFile)
+	authRequired.POST("/transfers/files/:id/confirm", server.confirmPaymentFile)
+	authRequired.GET("/transfers/files/:id/report", server.getPaymentFileReport)
+	authRequired.POST("/transfers/holds", server.createHold)
+	authRequired.POST("/transfers/holds/:id/capture", server.captureHold)
+	authRequired.POST("/transfers/holds/:id/void", server.voidHold)
+
+	authRequired.GET("/interest/plans", server.getInterestPlans)
+
+	authRequired.POST("/categories/rules", server.createCategoryRule)
+	authRequired.GET("/categories/rules", server.getCategoryRules)
+	authRequired.DELETE("/categories/rules/:id", server.deleteCategoryRule)
+
+	authRequired.POST("/webhooks", server.createWebhookSubscription)
+	authRequired.GET("/webhooks", server.getWebhookSubscriptions)
+	authRequired.DELETE("/webhooks/:id", server.deleteWebhookSubscription)
+	authRequired.GET("/webhooks/:id/deliveries", server.getWebhookDeliveries)
+	authRequired.POST("/webhooks/deliveries/:id/replay", server.replayWebhookDelivery)
+
+	// the cash counter
+	tellerRequired := router.Group("/teller").Use(authMiddleware(server.tokenCreator), roleMiddleware(server.store, db.RoleTeller))
+
+	tellerRequired.POST("/deposits", server.deposit)
+	tellerRequired.POST("/withdrawals", server.withdraw)
+	tellerRequired.GET("/receipts/:id", server.getCashReceipt)
+
+	// the overrides of the configured transfer limits
+	adminRequired := router.Group("/admin").Use(authMiddleware(server.tokenCreator), roleMiddleware(server.store, db.RoleAdmin))
+
+	adminRequired.PUT("/limits/users/:username", server.setUserTransferLimit)
+	adminRequired.PUT("/limits/accounts/:id", server.setAccountTransferLimit)
 
 	server.router = router
 }
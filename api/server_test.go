@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGracefulShutdown(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	store := mockdb.NewMockStore(controller)
+	server := newTestServer(t, store)
+	server.config.ShutdownTimeout = 5 * time.Second
+
+	user := getRandomUser()
+	started := make(chan struct{})
+	server.router.GET("/slow", func(ctx *gin.Context) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		ctx.String(http.StatusOK, "done")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	baseUrl := fmt.Sprintf("http://%s", listener.Addr())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- server.serve(ctx, listener)
+	}()
+
+	// an open activity stream doesn't hold the shutdown
+	req, err := http.NewRequest(http.MethodGet, baseUrl+"/accounts/activity", nil)
+	require.NoError(t, err)
+	addAuthorization(t, req, server.tokenCreator, authorizationType, user.Username, time.Minute)
+	streamRes, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer streamRes.Body.Close()
+	require.Equal(t, http.StatusOK, streamRes.StatusCode)
+
+	slow := make(chan string, 1)
+	go func() {
+		res, err := http.Get(baseUrl + "/slow")
+		if err != nil {
+			slow <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		slow <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	// the request in flight is answered
+	require.Equal(t, "done", <-slow)
+	select {
+	case err := <-served:
+		require.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("The server didn't shut down")
+	}
+
+	// the stream was ended by the server
+	_, err = io.ReadAll(streamRes.Body)
+	require.NoError(t, err)
+
+	// and the new connections are refused
+	_, err = http.Get(baseUrl + "/healthz")
+	require.Error(t, err)
+
+	// the in-memory gRPC server of the gateway was stopped too, grpc-gateway answers the cancelled calls with a 499
+	body := bytes.NewBufferString(`{"username":"` + user.Username + `","password":"secret123"}`)
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users/login", body))
+	require.Equal(t, 499, recorder.Code)
+}
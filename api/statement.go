@@ -0,0 +1,126 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/api/helpers"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/statement"
+	"github.com/gin-gonic/gin"
+)
+
+// a year of entries at most in a single statement
+const maxStatementDays = 366
+
+type getStatementReq struct {
+	// both days are included, the previous calendar month when missing
+	From time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To   time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+}
+
+// the statement period in UTC, from the start of the first day to the end of the last one
+func (req getStatementReq) period(now time.Time) (time.Time, time.Time, error) {
+	if req.From.IsZero() && req.To.IsZero() {
+		thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return thisMonth.AddDate(0, -1, 0), thisMonth, nil
+	}
+	if req.From.IsZero() || req.To.IsZero() {
+		return time.Time{}, time.Time{}, errors.New("Both from and to are required for a custom period")
+	}
+	if req.To.Before(req.From) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+
+	to := req.To.AddDate(0, 0, 1)
+	if to.Sub(req.From) > maxStatementDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("A statement covers %d days at most", maxStatementDays)
+	}
+	return req.From, to, nil
+}
+
+// downloads the statement of the account in the given format
+func (server *Server) getStatement(format string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var uri getAccountReq
+		var req getStatementReq
+
+		if err := ctx.ShouldBindUri(&uri); err != nil {
+			helpers.WriteError(ctx, problem.InvalidArgument(err))
+			return
+		}
+
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			helpers.WriteError(ctx, problem.InvalidArgument(err))
+			return
+		}
+
+		from, to, err := req.period(time.Now())
+		if err != nil {
+			helpers.WriteError(ctx, problem.InvalidArgument(err))
+			return
+		}
+
+		account, ok := server.getOwnedAccount(ctx, uri.Id)
+		if !ok {
+			return
+		}
+
+		user, err := server.store.GetUserByUsername(ctx, account.OwnerName)
+		if err != nil {
+			helpers.WriteError(ctx, err)
+			return
+		}
+
+		opening, err := server.store.BalanceAt(ctx, account, from)
+		if err != nil {
+			helpers.WriteError(ctx, err)
+			return
+		}
+
+		entries, err := server.store.GetEntriesBetween(ctx, db.GetEntriesBetweenParams{
+			AccountID: account.ID,
+			From:      from,
+			To:        to,
+		})
+		if err != nil {
+			helpers.WriteError(ctx, err)
+			return
+		}
+
+		s := &statement.Statement{
+			AccountId:      account.ID,
+			OwnerName:      account.OwnerName,
+			FullName:       user.FullName,
+			Currency:       account.Currency,
+			From:           from,
+			To:             to,
+			OpeningBalance: opening,
+			Lines:          make([]statement.Line, 0, len(entries)),
+		}
+		for _, entry := range entries {
+			s.Lines = append(s.Lines, statement.Line{
+				EntryId:     entry.ID,
+				Date:        entry.CreatedAt,
+				Description: entry.Description,
+				Reference:   entry.Reference,
+				Category:    entry.Category,
+				Amount:      entry.Amount,
+			})
+		}
+		s.Balance()
+
+		var buf bytes.Buffer
+		if err := statement.Write(&buf, format, s); err != nil {
+			helpers.WriteError(ctx, err)
+			return
+		}
+
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", s.Filename(format)))
+		ctx.Data(http.StatusOK, statement.ContentType(format), buf.Bytes())
+	}
+}
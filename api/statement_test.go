@@ -0,0 +1,170 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStatementAPI(t *testing.T) {
+	user1 := getRandomUser()
+	user2 := getRandomUser()
+	account := getRandomAccount(user1.Username)
+
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	entries := []db.Entry{
+		{ID: 1, AccountID: account.ID, Amount: 5000, CreatedAt: from.Add(time.Hour), Description: "Salary"},
+		{ID: 2, AccountID: account.ID, Amount: -1250, CreatedAt: from.Add(48 * time.Hour), Reference: "INV-1"},
+	}
+
+	stubStatement := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+		store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+		store.EXPECT().BalanceAt(gomock.Any(), gomock.Eq(account), gomock.Eq(from)).Times(1).Return(int64(10000), nil)
+		store.EXPECT().
+			GetEntriesBetween(gomock.Any(), gomock.Eq(db.GetEntriesBetweenParams{AccountID: account.ID, From: from, To: to})).
+			Times(1).
+			Return(entries, nil)
+	}
+
+	testCases := []struct {
+		testName   string
+		username   string
+		path       string
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName:   "OK/CSV",
+			username:   user1.Username,
+			path:       "statement.csv?from=2024-01-01&to=2024-01-31",
+			buildStubs: stubStatement,
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Equal(t,
+					fmt.Sprintf(`attachment; filename="statement-%d-20240101-20240131.csv"`, account.ID),
+					recorder.Header().Get("Content-Disposition"),
+				)
+
+				records, err := csv.NewReader(recorder.Body).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, records, 3)
+				// the running balance starts from the opening one
+				require.Equal(t, "150.00", records[1][6])
+				require.Equal(t, "137.50", records[2][6])
+			},
+		},
+		{
+			testName:   "OK/OFX",
+			username:   user1.Username,
+			path:       "statement.ofx?from=2024-01-01&to=2024-01-31",
+			buildStubs: stubStatement,
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/x-ofx", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), "<BALAMT>137.50</BALAMT>")
+			},
+		},
+		{
+			testName:   "OK/PDF",
+			username:   user1.Username,
+			path:       "statement.pdf?from=2024-01-01&to=2024-01-31",
+			buildStubs: stubStatement,
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+				require.True(t, bytes.HasPrefix(recorder.Body.Bytes(), []byte("%PDF-")))
+			},
+		},
+		{
+			testName: "BadRequest/FromAfterTo",
+			username: user1.Username,
+			path:     "statement.csv?from=2024-02-01&to=2024-01-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "BadRequest/TooLong",
+			username: user1.Username,
+			path:     "statement.csv?from=2020-01-01&to=2024-01-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "BadRequest/InvalidDate",
+			username: user1.Username,
+			path:     "statement.csv?from=january&to=2024-01-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "Unauthorized",
+			username: user2.Username,
+			path:     "statement.pdf?from=2024-01-01&to=2024-01-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetEntriesBetween(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/accounts/%d/%s", account.ID, testCase.path)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+
+			addAuthorization(t, req, server.tokenCreator, authorizationType, testCase.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
+
+func TestStatementPeriod(t *testing.T) {
+	now := time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC)
+
+	// the previous calendar month by default
+	from, to, err := getStatementReq{}.period(now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), from)
+	require.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), to)
+
+	_, _, err = getStatementReq{From: from}.period(now)
+	require.Error(t, err)
+}
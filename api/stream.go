@@ -0,0 +1,171 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/api/helpers"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/stream"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// the activity buffered for a slow client before it's dropped
+	streamBuffer = 64
+	// keeps the idle connections open through the proxies
+	streamKeepAlive = 30 * time.Second
+	streamWriteWait = 10 * time.Second
+)
+
+type streamActivityReq struct {
+	// all the user's accounts when missing
+	AccountId int64 `form:"account_id" binding:"omitempty,min=1"`
+}
+
+// subscribes to the activity of the logged-in user's accounts, writes the error response when it can't
+func (server *Server) subscribeActivity(ctx *gin.Context) (*stream.Subscription, bool) {
+	var req streamActivityReq
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return nil, false
+	}
+
+	if req.AccountId != 0 {
+		if _, ok := server.getOwnedAccount(ctx, req.AccountId); !ok {
+			return nil, false
+		}
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	return server.hub.Subscribe(payload.Username, req.AccountId, streamBuffer), true
+}
+
+// the streams are open for as long as the clients want, the read and write timeouts of the server would cut them.
+// the websocket keeps the deadlines of the hijacked connection, so it's cleared before the upgrade.
+func clearDeadlines(ctx *gin.Context) {
+	controller := http.NewResponseController(ctx.Writer)
+	controller.SetReadDeadline(time.Time{})
+	controller.SetWriteDeadline(time.Time{})
+}
+
+// Server-Sent Events of the new entries and balances of the user's accounts
+func (server *Server) streamActivity(ctx *gin.Context) {
+	subscription, ok := server.subscribeActivity(ctx)
+	if !ok {
+		return
+	}
+	defer server.hub.Unsubscribe(subscription)
+
+	clearDeadlines(ctx)
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// nginx buffers the responses otherwise
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case activity, ok := <-subscription.C:
+			if !ok {
+				return
+			}
+			ctx.Render(-1, activityEvent(activity))
+		case <-keepAlive.C:
+			// a comment, ignored by the clients
+			if _, err := ctx.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		ctx.Writer.Flush()
+	}
+}
+
+// the token authenticates the connection, so any origin can open it
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// an `activity` event per new entry and a `hold` one per hold placed, voided or expired
+func activityEvent(activity db.AccountActivity) sse.Event {
+	if activity.Hold != nil {
+		return sse.Event{
+			Id:    fmt.Sprintf("hold-%d-%s", activity.Hold.ID, activity.Hold.Status),
+			Event: "hold",
+			Data:  activity,
+		}
+	}
+	return sse.Event{
+		Id:    strconv.FormatInt(activity.Entry.ID, 10),
+		Event: "activity",
+		Data:  activity,
+	}
+}
+
+// the same activity as the SSE stream, a JSON message per entry or hold over a WebSocket
+func (server *Server) streamActivityWebSocket(ctx *gin.Context) {
+	subscription, ok := server.subscribeActivity(ctx)
+	if !ok {
+		return
+	}
+	defer server.hub.Unsubscribe(subscription)
+
+	clearDeadlines(ctx)
+
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// the upgrader already responded
+		return
+	}
+	defer conn.Close()
+
+	// the clients don't send anything, reading only handles the control messages and notices the close
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case activity, ok := <-subscription.C:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+			if err := conn.WriteJSON(activity); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
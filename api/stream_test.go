@@ -0,0 +1,196 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamActivityAPI(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	user := getRandomUser()
+	account := getRandomAccount(user.Username)
+
+	store := mockdb.NewMockStore(controller)
+	server := newTestServer(t, store)
+
+	httpServer := httptest.NewServer(server.router)
+	defer httpServer.Close()
+
+	activity := db.AccountActivity{
+		AccountId: account.ID,
+		OwnerName: user.Username,
+		Currency:  account.Currency,
+		Balance:   150,
+		Entry:     &db.Entry{ID: 9, AccountID: account.ID, Amount: 50},
+	}
+	holdActivity := db.AccountActivity{
+		AccountId:        account.ID,
+		OwnerName:        user.Username,
+		Currency:         account.Currency,
+		Balance:          150,
+		AvailableBalance: 100,
+		Hold:             &db.Hold{ID: 4, FromAccountID: account.ID, Amount: 50, Status: db.HoldStatusPending},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, httpServer.URL+"/accounts/activity", nil)
+	require.NoError(t, err)
+	addAuthorization(t, req, server.tokenCreator, authorizationType, user.Username, time.Minute)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// subscribed once the headers are out, the activity of the other users isn't streamed
+	server.hub.Broadcast(db.AccountActivity{AccountId: 1, OwnerName: getRandomUser().Username})
+	server.hub.Broadcast(activity)
+	// the holds change the available balance without an entry
+	server.hub.Broadcast(holdActivity)
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() []string {
+		var lines []string
+		for len(lines) < 3 {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return lines
+	}
+
+	lines := readEvent()
+	require.Equal(t, "id:9", lines[0])
+	require.Equal(t, "event:activity", lines[1])
+
+	var got db.AccountActivity
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data:")), &got))
+	require.Equal(t, activity, got)
+
+	lines = readEvent()
+	require.Equal(t, "id:hold-4-pending", lines[0])
+	require.Equal(t, "event:hold", lines[1])
+
+	got = db.AccountActivity{}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data:")), &got))
+	require.Equal(t, holdActivity.AvailableBalance, got.AvailableBalance)
+	require.Nil(t, got.Entry)
+	require.Equal(t, holdActivity.Hold.ID, got.Hold.ID)
+}
+
+func TestStreamActivityWebSocketAPI(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	user := getRandomUser()
+	account := getRandomAccount(user.Username)
+	otherAccount := getRandomAccount(user.Username)
+
+	store := mockdb.NewMockStore(controller)
+	store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+	server := newTestServer(t, store)
+
+	httpServer := httptest.NewServer(server.router)
+	defer httpServer.Close()
+
+	// the browsers send the token in the query
+	accessToken, _, err := server.tokenCreator.Create(user.Username, time.Minute)
+	require.NoError(t, err)
+
+	url := fmt.Sprintf("ws%s/accounts/activity/ws?account_id=%d&access_token=%s",
+		strings.TrimPrefix(httpServer.URL, "http"), account.ID, accessToken)
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// only the requested account
+	server.hub.Broadcast(db.AccountActivity{AccountId: otherAccount.ID, OwnerName: user.Username})
+	server.hub.Broadcast(db.AccountActivity{AccountId: account.ID, OwnerName: user.Username, Balance: 150})
+
+	var got db.AccountActivity
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	require.NoError(t, conn.ReadJSON(&got))
+	require.Equal(t, account.ID, got.AccountId)
+	require.Equal(t, int64(150), got.Balance)
+}
+
+func TestStreamActivityUnauthorized(t *testing.T) {
+	user1 := getRandomUser()
+	user2 := getRandomUser()
+	account := getRandomAccount(user1.Username)
+
+	testCases := []struct {
+		testName   string
+		url        string
+		setupAuth  func(t *testing.T, request *http.Request, server *Server)
+		buildStubs func(store *mockdb.MockStore)
+		status     int
+	}{
+		{
+			testName:   "NoToken",
+			url:        "/accounts/activity",
+			setupAuth:  func(t *testing.T, request *http.Request, server *Server) {},
+			buildStubs: func(store *mockdb.MockStore) {},
+			status:     http.StatusUnauthorized,
+		},
+		{
+			testName: "OtherUsersAccount",
+			url:      fmt.Sprintf("/accounts/activity?account_id=%d", account.ID),
+			setupAuth: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server.tokenCreator, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			testName: "InvalidAccountId",
+			url:      "/accounts/activity/ws?account_id=-1",
+			setupAuth: func(t *testing.T, request *http.Request, server *Server) {
+				addAuthorization(t, request, server.tokenCreator, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, testCase.url, nil)
+
+			testCase.setupAuth(t, req, server)
+			server.router.ServeHTTP(recorder, req)
+			require.Equal(t, testCase.status, recorder.Code)
+		})
+	}
+}
@@ -1,13 +1,11 @@
 package api
 
 import (
-	"database/sql"
-	"errors"
-	"fmt"
 	"net/http"
 
 	"github.com/AYehia0/go-bk-mst/api/helpers"
 	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
 	"github.com/AYehia0/go-bk-mst/token"
 	"github.com/gin-gonic/gin"
 )
@@ -15,73 +13,116 @@ import (
 type createTransferReq struct {
 	FromAccountId int64  `json:"from_account_id" binding:"required"`
 	ToAccountId   int64  `json:"to_account_id" binding:"required"`
-	Amount        int64  `json:"amount" binding:"required"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
 	Currency      string `json:"currency" binding:"required,currency"`
+	Description   string `json:"description" binding:"max=255"`
+	// the end-to-end reference given by the sender, an invoice number for example
+	Reference string `json:"reference" binding:"max=64"`
+	// when missing, picked by the category rules of the sender
+	Category string `json:"category" binding:"max=64"`
 }
 
-func (server *Server) createTransfer(ctx *gin.Context) {
-	var req createTransferReq
+func (server *Server) validateAccount(ctx *gin.Context, accountId int64, currency string) (db.Account, bool) {
+	account, err := db.CheckTransferAccount(ctx, server.store, accountId, currency)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return account, false
+	}
+	return account, true
+}
 
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, helpers.ErrorResp(err))
+type getTransfersReq struct {
+	AccountId int64  `form:"account_id" binding:"required,min=1"`
+	PageId    int32  `form:"page_id" binding:"required,min=1"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=10"`
+	Query     string `form:"q" binding:"max=255"`
+	Category  string `form:"category" binding:"max=64"`
+}
+
+// the transfers in and out of one of the logged-in user's accounts, newest first
+func (server *Server) getTransfers(ctx *gin.Context) {
+	var req getTransfersReq
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
 		return
 	}
 
-	// check the currency matching
-	_, valid := server.validateAccount(ctx, req.ToAccountId, req.Currency)
-	if !valid {
+	if _, ok := server.getOwnedAccount(ctx, req.AccountId); !ok {
 		return
 	}
-	fromAccount, valid := server.validateAccount(ctx, req.FromAccountId, req.Currency)
-	if !valid {
+
+	transfers, err := server.store.SearchTransfers(ctx, db.SearchTransfersParams{
+		AccountID: req.AccountId,
+		Query:     req.Query,
+		Category:  req.Category,
+		Limit:     req.PageSize,
+		Offset:    (req.PageId - 1) * req.PageSize,
+	})
+	if err != nil {
+		helpers.WriteError(ctx, err)
 		return
 	}
-	// logged-in user can only transfer money from his account to others
-	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	if payload.Username != fromAccount.OwnerName {
-		ctx.JSON(http.StatusUnauthorized,
-			helpers.ErrorResp(errors.New("from_account doesn't belong to the logged-in user!")),
-		)
+	ctx.JSON(http.StatusOK, transfers)
+}
+
+type reverseTransferUri struct {
+	Id int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reverseTransferReq struct {
+	// when missing, whatever is left from the original transfer is reversed
+	Amount int64  `json:"amount" binding:"omitempty,gt=0"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+// sends the money back to the sender of the original transfer, fully or partially.
+// only the receiver of the transfer can give the money back.
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var uri reverseTransferUri
+	var req reverseTransferReq
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
 		return
 	}
 
-	arg := db.TransferTxParams{
-		FromAccountId: req.FromAccountId,
-		ToAccountId:   req.ToAccountId,
-		Amount:        req.Amount,
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
 	}
 
-	result, err := server.store.TransferTransaction(ctx, arg)
-
+	original, err := server.store.GetTransferById(ctx, uri.Id)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResp(err))
+		helpers.WriteError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, result)
-}
-
-func (server *Server) validateAccount(ctx *gin.Context, accountId int64, currency string) (db.Account, bool) {
+	toAccount, err := server.store.GetAccountById(ctx, original.ToAccountID)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
 
-	account, err := server.store.GetAccountById(ctx, accountId)
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if payload.Username != toAccount.OwnerName {
+		helpers.WriteError(ctx, problem.New(http.StatusUnauthorized, problem.CodePermissionDenied, "Only the receiver of the transfer can reverse it!"))
+		return
+	}
 
-	if err != nil {
-		// check if account not found
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, helpers.ErrorResp(err))
-			return account, false
-		}
-		ctx.JSON(http.StatusInternalServerError, helpers.ErrorResp(err))
-		return account, false
+	arg := db.ReverseTransferTxParams{
+		TransferId: original.ID,
+		Amount:     req.Amount,
+		Reason:     req.Reason,
+		Actor:      payload.Username,
 	}
 
-	// check the currency
-	if account.Currency != currency {
-		err := fmt.Errorf("Account [%d] currency mismatch: %s vs %s", accountId, currency, account.Currency)
-		ctx.JSON(http.StatusBadRequest, helpers.ErrorResp(err))
-		return account, false
+	result, err := server.store.ReverseTransferTransaction(ctx, arg)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
 	}
 
-	return account, true
+	ctx.JSON(http.StatusOK, result)
 }
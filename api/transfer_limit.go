@@ -0,0 +1,132 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/api/helpers"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// a missing limit falls back to the next level (the user's override, then the configured one), 0 means unlimited
+type transferLimitOverrideReq struct {
+	MaxAmount   *int64 `json:"max_amount" binding:"omitempty,min=0"`
+	DailyAmount *int64 `json:"daily_amount" binding:"omitempty,min=0"`
+	HourlyCount *int64 `json:"hourly_count" binding:"omitempty,min=0"`
+}
+
+type transferLimitOverrideResp struct {
+	ID          int64     `json:"id"`
+	Username    *string   `json:"username,omitempty"`
+	AccountId   *int64    `json:"account_id,omitempty"`
+	MaxAmount   *int64    `json:"max_amount"`
+	DailyAmount *int64    `json:"daily_amount"`
+	HourlyCount *int64    `json:"hourly_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type userTransferLimitReq struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+// overrides the configured transfer limits for all the accounts of a user
+func (server *Server) setUserTransferLimit(ctx *gin.Context) {
+	var uri userTransferLimitReq
+	var req transferLimitOverrideReq
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	user, err := server.store.GetUserByUsername(ctx, uri.Username)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	limit, err := server.store.SetUserTransferLimit(ctx, db.SetUserTransferLimitParams{
+		Username:    sql.NullString{String: user.Username, Valid: true},
+		MaxAmount:   nullInt64(req.MaxAmount),
+		DailyAmount: nullInt64(req.DailyAmount),
+		HourlyCount: nullInt64(req.HourlyCount),
+	})
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTransferLimitOverrideResp(limit))
+}
+
+// overrides the limits of a single account, on top of its owner's override
+func (server *Server) setAccountTransferLimit(ctx *gin.Context) {
+	var uri getAccountReq
+	var req transferLimitOverrideReq
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	account, err := server.store.GetAccountById(ctx, uri.Id)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	limit, err := server.store.SetAccountTransferLimit(ctx, db.SetAccountTransferLimitParams{
+		AccountID:   sql.NullInt64{Int64: account.ID, Valid: true},
+		MaxAmount:   nullInt64(req.MaxAmount),
+		DailyAmount: nullInt64(req.DailyAmount),
+		HourlyCount: nullInt64(req.HourlyCount),
+	})
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTransferLimitOverrideResp(limit))
+}
+
+func newTransferLimitOverrideResp(limit db.TransferLimit) transferLimitOverrideResp {
+	resp := transferLimitOverrideResp{
+		ID:          limit.ID,
+		AccountId:   int64Ptr(limit.AccountID),
+		MaxAmount:   int64Ptr(limit.MaxAmount),
+		DailyAmount: int64Ptr(limit.DailyAmount),
+		HourlyCount: int64Ptr(limit.HourlyCount),
+		CreatedAt:   limit.CreatedAt,
+	}
+	if limit.Username.Valid {
+		resp.Username = &limit.Username.String
+	}
+	return resp
+}
+
+func nullInt64(value *int64) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *value, Valid: true}
+}
+
+func int64Ptr(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int64
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTransferLimitAPI(t *testing.T) {
+	admin := getRandomUser()
+	admin.Role = db.RoleAdmin
+	teller := getRandomUser()
+	teller.Role = db.RoleTeller
+	customer := getRandomUser()
+
+	account := getRandomAccount(customer.Username)
+
+	testCases := []struct {
+		testName   string
+		url        string
+		user       db.User
+		body       gin.H
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "User/OK",
+			url:      "/admin/limits/users/" + customer.Username,
+			user:     admin,
+			body:     gin.H{"max_amount": 500, "hourly_count": 0},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(customer, nil)
+				store.EXPECT().
+					SetUserTransferLimit(gomock.Any(), gomock.Eq(db.SetUserTransferLimitParams{
+						Username:    sql.NullString{String: customer.Username, Valid: true},
+						MaxAmount:   sql.NullInt64{Int64: 500, Valid: true},
+						HourlyCount: sql.NullInt64{Int64: 0, Valid: true},
+					})).
+					Times(1).
+					Return(db.TransferLimit{
+						ID:          1,
+						Username:    sql.NullString{String: customer.Username, Valid: true},
+						MaxAmount:   sql.NullInt64{Int64: 500, Valid: true},
+						HourlyCount: sql.NullInt64{Int64: 0, Valid: true},
+					}, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp map[string]any
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, customer.Username, resp["username"])
+				require.Equal(t, float64(500), resp["max_amount"])
+				// the missing limit falls back to the configured one
+				require.Nil(t, resp["daily_amount"])
+				require.Equal(t, float64(0), resp["hourly_count"])
+				require.NotContains(t, resp, "account_id")
+			},
+		},
+		{
+			testName: "User/NotFound",
+			url:      "/admin/limits/users/" + customer.Username,
+			user:     admin,
+			body:     gin.H{"max_amount": 500},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().SetUserTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			testName: "Account/OK",
+			url:      fmt.Sprintf("/admin/limits/accounts/%d", account.ID),
+			user:     admin,
+			body:     gin.H{"daily_amount": 1000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					SetAccountTransferLimit(gomock.Any(), gomock.Eq(db.SetAccountTransferLimitParams{
+						AccountID:   sql.NullInt64{Int64: account.ID, Valid: true},
+						DailyAmount: sql.NullInt64{Int64: 1000, Valid: true},
+					})).
+					Times(1).
+					Return(db.TransferLimit{
+						ID:          2,
+						AccountID:   sql.NullInt64{Int64: account.ID, Valid: true},
+						DailyAmount: sql.NullInt64{Int64: 1000, Valid: true},
+					}, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp map[string]any
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, float64(account.ID), resp["account_id"])
+				require.Equal(t, float64(1000), resp["daily_amount"])
+			},
+		},
+		{
+			testName: "Account/NegativeLimit",
+			url:      fmt.Sprintf("/admin/limits/accounts/%d", account.ID),
+			user:     admin,
+			body:     gin.H{"daily_amount": -1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().SetAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "NotAdmin",
+			url:      fmt.Sprintf("/admin/limits/accounts/%d", account.ID),
+			user:     teller,
+			body:     gin.H{"daily_amount": 1000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(teller.Username)).Times(1).Return(teller, nil)
+				store.EXPECT().SetAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, testCase.url, bytes.NewReader(data))
+
+			addAuthorization(t, req, server.tokenCreator, authorizationType, testCase.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
@@ -2,7 +2,9 @@ package api
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,6 +12,7 @@ import (
 
 	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
 	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
 	"github.com/AYehia0/go-bk-mst/token"
 	"github.com/AYehia0/go-bk-mst/utils"
 	"github.com/gin-gonic/gin"
@@ -68,6 +71,8 @@ func TestCreateTransfer(t *testing.T) {
 					FromAccountId: account1.ID,
 					ToAccountId:   account2.ID,
 					Amount:        int64(amount),
+					Limits:        &testTransferLimits,
+					ChargeFee:     true,
 				}
 
 				store.EXPECT().
@@ -115,6 +120,184 @@ func TestCreateTransfer(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			testName: "OK/CategorizedByRule",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        account1.Currency,
+				"description":     "Netflix monthly plan",
+				"reference":       "INV-2041",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				store.EXPECT().
+					GetCategoryRules(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return([]db.CategoryRule{
+						{Pattern: "SPOTIFY", Category: "music"},
+						{Pattern: "NETFLIX", Category: "subscriptions"},
+					}, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountId: account1.ID,
+					ToAccountId:   account2.ID,
+					Amount:        int64(amount),
+					Description:   "Netflix monthly plan",
+					Reference:     "INV-2041",
+					Category:      "subscriptions",
+					Limits:        &testTransferLimits,
+					ChargeFee:     true,
+				}
+
+				store.EXPECT().
+					TransferTransaction(gomock.Any(), gomock.Eq(arg)).
+					Times(1)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			testName: "OK/CategoryGiven",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        account1.Currency,
+				"description":     "Netflix monthly plan",
+				"category":        "family",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				// the rules never override the category chosen by the user
+				store.EXPECT().GetCategoryRules(gomock.Any(), gomock.Any()).Times(0)
+
+				arg := db.TransferTxParams{
+					FromAccountId: account1.ID,
+					ToAccountId:   account2.ID,
+					Amount:        int64(amount),
+					Description:   "Netflix monthly plan",
+					Category:      "family",
+					Limits:        &testTransferLimits,
+					ChargeFee:     true,
+				}
+
+				store.EXPECT().
+					TransferTransaction(gomock.Any(), gomock.Eq(arg)).
+					Times(1)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			testName: "LimitExceeded",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				store.EXPECT().
+					TransferTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, &db.LimitError{Limit: db.LimitDailyAmount, Max: 500000, Remaining: 5})
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var resp struct {
+					Limit db.LimitError `json:"limit"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, db.LimitDailyAmount, resp.Limit.Limit)
+				require.Equal(t, int64(5), resp.Limit.Remaining)
+			},
+		},
+		{
+			testName: "UnprocessableEntity/SystemAccount",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				system := account2
+				system.OwnerName = db.SystemUsername
+				system.IsSystem = true
+
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(system, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusUnprocessableEntity, problem.CodeUnprocessable)
+			},
+		},
+		{
+			testName: "UnprocessableEntity/InsufficientFunds",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusUnprocessableEntity, problem.CodeInsufficientFunds)
+			},
+		},
+		{
+			testName: "BadRequest/NegativeAmount",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          -amount,
+				"currency":        account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -149,3 +332,271 @@ func TestCreateTransfer(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTransfersAPI(t *testing.T) {
+	user1 := getRandomUser()
+	user2 := getRandomUser()
+	account := getRandomAccount(user1.Username)
+
+	transfers := []db.Transfer{
+		{ID: 2, FromAccountID: account.ID, ToAccountID: account.ID + 1, Amount: 10, Description: "Netflix", Category: "subscriptions"},
+		{ID: 1, FromAccountID: account.ID + 1, ToAccountID: account.ID, Amount: 20, Description: "Netflix refund", Category: "subscriptions"},
+	}
+
+	testCases := []struct {
+		testName   string
+		query      string
+		setupAuth  func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator)
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "OK",
+			query:    fmt.Sprintf("account_id=%d&page_id=2&page_size=5&q=netflix&category=subscriptions", account.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					SearchTransfers(gomock.Any(), gomock.Eq(db.SearchTransfersParams{
+						AccountID: account.ID,
+						Query:     "netflix",
+						Category:  "subscriptions",
+						Limit:     5,
+						Offset:    5,
+					})).
+					Times(1).
+					Return(transfers, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []db.Transfer
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, transfers, got)
+			},
+		},
+		{
+			testName: "Unauthorized/AnotherUsersAccount",
+			query:    fmt.Sprintf("account_id=%d&page_id=1&page_size=5", account.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			testName: "BadRequest/MissingAccount",
+			query:    "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/transfers?"+testCase.query, nil)
+
+			testCase.setupAuth(t, req, server.tokenCreator)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
+
+func TestReverseTransferAPI(t *testing.T) {
+	user1 := getRandomUser()
+	user2 := getRandomUser()
+
+	account1 := getRandomAccount(user1.Username)
+	account2 := getRandomAccount(user2.Username)
+
+	original := db.Transfer{
+		ID:            utils.GetRandomAmount(),
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	}
+
+	testCases := []struct {
+		testName   string
+		transferId int64
+		body       gin.H
+		setupAuth  func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator)
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName:   "OK/Partial",
+			transferId: original.ID,
+			body: gin.H{
+				"amount": 40,
+				"reason": "duplicate payment",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferById(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(original, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.ReverseTransferTxParams{
+					TransferId: original.ID,
+					Amount:     40,
+					Reason:     "duplicate payment",
+					Actor:      user2.Username,
+				}
+				store.EXPECT().
+					ReverseTransferTransaction(gomock.Any(), gomock.Eq(arg)).
+					Times(1)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			testName:   "OK/Full",
+			transferId: original.ID,
+			body: gin.H{
+				"reason": "mistaken payment",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferById(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(original, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.ReverseTransferTxParams{
+					TransferId: original.ID,
+					Reason:     "mistaken payment",
+					Actor:      user2.Username,
+				}
+				store.EXPECT().
+					ReverseTransferTransaction(gomock.Any(), gomock.Eq(arg)).
+					Times(1)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			testName:   "ExceedsOriginalAmount",
+			transferId: original.ID,
+			body: gin.H{
+				"amount": 1000,
+				"reason": "dispute",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferById(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(original, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					ReverseTransferTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrReversalExceedsTransfer)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			testName:   "Unauthorized/Sender can't reverse",
+			transferId: original.ID,
+			body: gin.H{
+				"reason": "changed my mind",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferById(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(original, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().ReverseTransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			testName:   "NotFound",
+			transferId: original.ID,
+			body: gin.H{
+				"reason": "dispute",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferById(gomock.Any(), gomock.Eq(original.ID)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().ReverseTransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			testName:   "BadRequest/MissingReason",
+			transferId: original.ID,
+			body:       gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenCreator) {
+				addAuthorization(t, request, tokenMaker, authorizationType, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferById(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ReverseTransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			data, err := json.Marshal(testCase.body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			urlPath := fmt.Sprintf("/transfers/%d/reverse", testCase.transferId)
+			req := httptest.NewRequest(http.MethodPost, urlPath, bytes.NewReader(data))
+
+			testCase.setupAuth(t, req, server.tokenCreator)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
@@ -2,7 +2,13 @@
 package api
 
 import (
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+
 	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/AYehia0/go-bk-mst/webhook"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -13,3 +19,44 @@ var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
 	}
 	return false
 }
+
+// an absolute http(s) url the webhooks can be POSTed to
+var validWebhookUrl validator.Func = func(fl validator.FieldLevel) bool {
+	if rawUrl, ok := fl.Field().Interface().(string); ok {
+		parsed, err := url.Parse(rawUrl)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return false
+		}
+		// the obvious internal hosts are refused early, the dispatcher checks the resolved addresses when sending
+		if addr, err := netip.ParseAddr(parsed.Hostname()); err == nil {
+			return webhook.IsPublicAddress(addr)
+		}
+		return parsed.Hostname() != "localhost"
+	}
+	return false
+}
+
+var validWebhookEvent validator.Func = func(fl validator.FieldLevel) bool {
+	if event, ok := fl.Field().Interface().(string); ok {
+		for _, eventType := range webhook.EventTypes {
+			if event == eventType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// the json, form or uri name of the field, the go name when it has none
+func requestFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/api/helpers"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/gin-gonic/gin"
+)
+
+type createWebhookSubscriptionReq struct {
+	Url    string   `json:"url" binding:"required,max=2048,webhook_url"`
+	Events []string `json:"events" binding:"required,min=1,dive,webhook_event"`
+	// generated when missing
+	Secret string `json:"secret" binding:"omitempty,min=16,max=128"`
+}
+
+// the secret is only shown when the subscription is created
+type webhookSubscriptionResp struct {
+	ID        int64     `json:"id"`
+	Url       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newWebhookSubscriptionResp(subscription db.WebhookSubscription) webhookSubscriptionResp {
+	return webhookSubscriptionResp{
+		ID:        subscription.ID,
+		Url:       subscription.Url,
+		Events:    subscription.Events,
+		CreatedAt: subscription.CreatedAt,
+	}
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+func (server *Server) createWebhookSubscription(ctx *gin.Context) {
+	var req createWebhookSubscriptionReq
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = newWebhookSecret(); err != nil {
+			helpers.WriteError(ctx, err)
+			return
+		}
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	subscription, err := server.store.CreateWebhookSubscription(ctx, db.CreateWebhookSubscriptionParams{
+		OwnerName: payload.Username,
+		Url:       req.Url,
+		Events:    req.Events,
+		Secret:    secret,
+	})
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	resp := newWebhookSubscriptionResp(subscription)
+	resp.Secret = subscription.Secret
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (server *Server) getWebhookSubscriptions(ctx *gin.Context) {
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	subscriptions, err := server.store.GetWebhookSubscriptions(ctx, payload.Username)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	resp := make([]webhookSubscriptionResp, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		resp = append(resp, newWebhookSubscriptionResp(subscription))
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+type webhookReq struct {
+	Id int64 `uri:"id" binding:"required,min=1"`
+}
+
+// deletes the subscription with its delivery log
+func (server *Server) deleteWebhookSubscription(ctx *gin.Context) {
+	var req webhookReq
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	deleted, err := server.store.DeleteWebhookSubscription(ctx, db.DeleteWebhookSubscriptionParams{
+		ID:        req.Id,
+		OwnerName: payload.Username,
+	})
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+	if deleted == 0 {
+		helpers.WriteError(ctx, problem.New(http.StatusNotFound, problem.CodeNotFound, "Webhook subscription not found"))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type getWebhookDeliveriesReq struct {
+	PageId   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// the delivery log of the subscription, newest first
+func (server *Server) getWebhookDeliveries(ctx *gin.Context) {
+	var uri webhookReq
+	var req getWebhookDeliveriesReq
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	if _, ok := server.getOwnedWebhookSubscription(ctx, uri.Id); !ok {
+		return
+	}
+
+	deliveries, err := server.store.GetWebhookDeliveries(ctx, db.GetWebhookDeliveriesParams{
+		SubscriptionID: uri.Id,
+		Limit:          req.PageSize,
+		Offset:         (req.PageId - 1) * req.PageSize,
+	})
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+// sends the delivery again on the next dispatch, whatever its status, with a fresh set of attempts
+func (server *Server) replayWebhookDelivery(ctx *gin.Context) {
+	var req webhookReq
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		helpers.WriteError(ctx, problem.InvalidArgument(err))
+		return
+	}
+
+	delivery, err := server.store.GetWebhookDeliveryById(ctx, req.Id)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	if _, ok := server.getOwnedWebhookSubscription(ctx, delivery.SubscriptionID); !ok {
+		return
+	}
+
+	delivery, err = server.store.ReplayWebhookDelivery(ctx, delivery.ID)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, delivery)
+}
+
+func (server *Server) getOwnedWebhookSubscription(ctx *gin.Context, id int64) (db.WebhookSubscription, bool) {
+	subscription, err := server.store.GetWebhookSubscriptionById(ctx, id)
+	if err != nil {
+		helpers.WriteError(ctx, err)
+		return subscription, false
+	}
+
+	payload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if subscription.OwnerName != payload.Username {
+		helpers.WriteError(ctx, problem.New(http.StatusUnauthorized, problem.CodePermissionDenied, "Webhook subscription doesn't belong to the logged-in user!"))
+		return subscription, false
+	}
+	return subscription, true
+}
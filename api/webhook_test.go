@@ -0,0 +1,239 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhooksAPI(t *testing.T) {
+	user1 := getRandomUser()
+	user2 := getRandomUser()
+
+	subscription := db.WebhookSubscription{
+		ID:        1,
+		OwnerName: user1.Username,
+		Url:       "https://example.com/hooks",
+		Events:    []string{db.EventTransferCompleted},
+		Secret:    "whsec_0123456789abcdef",
+	}
+	delivery := db.WebhookDelivery{
+		ID:             7,
+		SubscriptionID: subscription.ID,
+		EventID:        3,
+		EventType:      db.EventTransferCompleted,
+		Status:         db.WebhookDeliveryFailed,
+		Attempts:       8,
+	}
+
+	testCases := []struct {
+		testName   string
+		username   string
+		method     string
+		url        string
+		body       gin.H
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			testName: "Create/OK",
+			username: user1.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks",
+			body:     gin.H{"url": subscription.Url, "events": subscription.Events, "secret": subscription.Secret},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookSubscription(gomock.Any(), gomock.Eq(db.CreateWebhookSubscriptionParams{
+						OwnerName: user1.Username,
+						Url:       subscription.Url,
+						Events:    subscription.Events,
+						Secret:    subscription.Secret,
+					})).
+					Times(1).
+					Return(subscription, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var resp webhookSubscriptionResp
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+				require.Equal(t, subscription.Secret, resp.Secret)
+			},
+		},
+		{
+			testName: "Create/GeneratedSecret",
+			username: user1.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks",
+			body:     gin.H{"url": subscription.Url, "events": subscription.Events},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookSubscription(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+						require.True(t, strings.HasPrefix(arg.Secret, "whsec_"))
+						return db.WebhookSubscription{ID: 2, OwnerName: arg.OwnerName, Url: arg.Url, Events: arg.Events, Secret: arg.Secret}, nil
+					})
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			testName: "Create/InvalidUrl",
+			username: user1.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks",
+			body:     gin.H{"url": "ftp://example.com", "events": subscription.Events},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "Create/PrivateUrl",
+			username: user1.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks",
+			body:     gin.H{"url": "http://169.254.169.254/latest/meta-data", "events": subscription.Events},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "Create/UnknownEvent",
+			username: user1.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks",
+			body:     gin.H{"url": subscription.Url, "events": []string{"user.registered"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			testName: "List/HidesSecret",
+			username: user1.Username,
+			method:   http.MethodGet,
+			url:      "/webhooks",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookSubscriptions(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return([]db.WebhookSubscription{subscription}, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), subscription.Secret)
+			},
+		},
+		{
+			testName: "Delete/NotFound",
+			username: user2.Username,
+			method:   http.MethodDelete,
+			url:      "/webhooks/1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteWebhookSubscription(gomock.Any(), gomock.Eq(db.DeleteWebhookSubscriptionParams{ID: 1, OwnerName: user2.Username})).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			testName: "Deliveries/OK",
+			username: user1.Username,
+			method:   http.MethodGet,
+			url:      "/webhooks/1/deliveries?page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscriptionById(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().
+					GetWebhookDeliveries(gomock.Any(), gomock.Eq(db.GetWebhookDeliveriesParams{SubscriptionID: 1, Limit: 5, Offset: 5})).
+					Times(1).
+					Return([]db.WebhookDelivery{delivery}, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			testName: "Replay/OK",
+			username: user1.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks/deliveries/7/replay",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookDeliveryById(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+				store.EXPECT().GetWebhookSubscriptionById(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+
+				replayed := delivery
+				replayed.Status = db.WebhookDeliveryPending
+				replayed.Attempts = 0
+				store.EXPECT().ReplayWebhookDelivery(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(replayed, nil)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.WebhookDelivery
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, db.WebhookDeliveryPending, got.Status)
+			},
+		},
+		{
+			testName: "Replay/Unauthorized",
+			username: user2.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks/deliveries/7/replay",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookDeliveryById(gomock.Any(), gomock.Eq(delivery.ID)).Times(1).Return(delivery, nil)
+				store.EXPECT().GetWebhookSubscriptionById(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+				store.EXPECT().ReplayWebhookDelivery(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			var body bytes.Buffer
+			if testCase.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(testCase.body))
+			}
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(testCase.method, testCase.url, &body)
+
+			addAuthorization(t, req, server.tokenCreator, authorizationType, testCase.username, time.Minute)
+			server.router.ServeHTTP(recorder, req)
+			testCase.checkResp(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func openFixture(t *testing.T, name string) *os.File {
+	file, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	t.Cleanup(func() { file.Close() })
+	return file
+}
+
+func TestParseCSV(t *testing.T) {
+	file, err := ParseCSV(openFixture(t, "payroll.csv"))
+	require.NoError(t, err)
+	require.Equal(t, FormatCSV, file.Format)
+
+	require.Equal(t, []Instruction{
+		{EndToEndId: "PAYROLL-1", FromAccountId: 1, ToAccountId: 2, Amount: 150000, Currency: "USD"},
+		{EndToEndId: "PAYROLL-2", FromAccountId: 1, ToAccountId: 3, Amount: 125050, Currency: "USD"},
+		// missing ids are numbered by their position
+		{EndToEndId: "3", FromAccountId: 1, ToAccountId: 4, Amount: 9900, Currency: "USD"},
+	}, file.Instructions)
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	_, err := ParseCSV(openFixture(t, "missing_column.csv"))
+	require.ErrorContains(t, err, "amount")
+
+	_, err = ParseCSV(openFixture(t, "invalid_amount.csv"))
+	require.ErrorContains(t, err, "Line 2")
+
+	_, err = ParseCSV(bytes.NewBufferString("from_account_id,to_account_id,amount,currency\n"))
+	require.Error(t, err)
+}
+
+func TestParsePain001(t *testing.T) {
+	file, err := ParsePain001(openFixture(t, "pain001.xml"))
+	require.NoError(t, err)
+	require.Equal(t, FormatPain001, file.Format)
+	require.Equal(t, "MSG-2023-0001", file.MessageId)
+
+	require.Equal(t, []Instruction{
+		{EndToEndId: "E2E-1", FromAccountId: 1, ToAccountId: 2, Amount: 150000, Currency: "USD"},
+		{EndToEndId: "E2E-2", FromAccountId: 1, ToAccountId: 3, Amount: 125050, Currency: "USD"},
+		{EndToEndId: "E2E-3", FromAccountId: 5, ToAccountId: 4, Amount: 10000, Currency: "EUR"},
+	}, file.Instructions)
+}
+
+func TestParsePain001Errors(t *testing.T) {
+	_, err := ParsePain001(openFixture(t, "pain001_bad_ctrlsum.xml"))
+	require.ErrorContains(t, err, "CtrlSum")
+
+	// without the check the amounts would wrap around to the CtrlSum of 0.00
+	_, err = ParsePain001(openFixture(t, "pain001_ctrlsum_overflow.xml"))
+	require.ErrorContains(t, err, "E2E-2")
+
+	_, err = ParsePain001(openFixture(t, "pain001_iban.xml"))
+	require.ErrorContains(t, err, "IBAN")
+
+	_, err = ParsePain001(bytes.NewBufferString("not xml"))
+	require.Error(t, err)
+}
+
+func TestDetectFormat(t *testing.T) {
+	require.Equal(t, FormatCSV, DetectFormat("payroll.csv", nil))
+	require.Equal(t, FormatPain001, DetectFormat("payments.XML", nil))
+	require.Equal(t, FormatPain001, DetectFormat("upload", []byte("  <?xml version=\"1.0\"?>")))
+	require.Equal(t, FormatCSV, DetectFormat("upload", []byte("from_account_id,to_account_id")))
+
+	_, err := Parse("xlsx", bytes.NewBuffer(nil))
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestStatusReport(t *testing.T) {
+	report := StatusReport{
+		MessageId:         "REPORT-1",
+		CreatedAt:         time.Date(2023, 9, 1, 10, 0, 0, 0, time.UTC),
+		OriginalMessageId: "MSG-2023-0001",
+		OriginalFormat:    FormatPain001,
+		Transactions: []TransactionStatus{
+			{EndToEndId: "E2E-1", Status: StatusSettled},
+			{EndToEndId: "E2E-2", Status: StatusRejected, Reason: "Account [3] currency mismatch"},
+		},
+	}
+	require.Equal(t, StatusPartial, report.GroupStatus())
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteXML(&buf))
+
+	// read it back
+	var doc pain002Document
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	require.Equal(t, "REPORT-1", doc.Report.GrpHdr.MsgId)
+	require.Equal(t, "2023-09-01T10:00:00", doc.Report.GrpHdr.CreDtTm)
+	require.Equal(t, "MSG-2023-0001", doc.Report.OrgnlGrpInfAndSts.OrgnlMsgId)
+	require.Equal(t, "2", doc.Report.OrgnlGrpInfAndSts.OrgnlNbOfTxs)
+	require.Equal(t, StatusPartial, doc.Report.OrgnlGrpInfAndSts.GrpSts)
+	require.Len(t, doc.Report.OrgnlPmtInfAndSts.TxInfAndSts, 2)
+	require.Equal(t, StatusRejected, doc.Report.OrgnlPmtInfAndSts.TxInfAndSts[1].TxSts)
+	require.Equal(t, "Account [3] currency mismatch", doc.Report.OrgnlPmtInfAndSts.TxInfAndSts[1].AddtlInf)
+
+	report.Transactions = report.Transactions[:1]
+	require.Equal(t, StatusSettled, report.GroupStatus())
+}
@@ -0,0 +1,93 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/AYehia0/go-bk-mst/utils"
+)
+
+// the columns every csv file must have, end_to_end_id is optional
+var csvColumns = []string{"from_account_id", "to_account_id", "amount", "currency"}
+
+// parses a csv file with a header line, the amounts are decimals (12.50)
+//
+//	end_to_end_id,from_account_id,to_account_id,amount,currency
+//	PAYROLL-1,1,2,1500.00,USD
+func ParseCSV(r io.Reader) (File, error) {
+	file := File{Format: FormatCSV}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return file, fmt.Errorf("Failed to read the csv header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvColumns {
+		if _, ok := columns[name]; !ok {
+			return file, fmt.Errorf("Missing csv column: %s", name)
+		}
+	}
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return file, fmt.Errorf("Failed to read the csv: %w", err)
+		}
+
+		instruction, err := parseCSVRecord(columns, record)
+		if err != nil {
+			return file, fmt.Errorf("Line %d: %w", line, err)
+		}
+		if instruction.EndToEndId == "" {
+			instruction.EndToEndId = strconv.Itoa(line - 1)
+		}
+		file.Instructions = append(file.Instructions, instruction)
+	}
+
+	if len(file.Instructions) == 0 {
+		return file, fmt.Errorf("The csv file has no payments")
+	}
+	return file, nil
+}
+
+func parseCSVRecord(columns map[string]int, record []string) (Instruction, error) {
+	var instruction Instruction
+	var err error
+
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	instruction.EndToEndId = field("end_to_end_id")
+	instruction.Currency = strings.ToUpper(field("currency"))
+
+	instruction.FromAccountId, err = strconv.ParseInt(field("from_account_id"), 10, 64)
+	if err != nil {
+		return instruction, fmt.Errorf("Invalid from_account_id: %q", field("from_account_id"))
+	}
+
+	instruction.ToAccountId, err = strconv.ParseInt(field("to_account_id"), 10, 64)
+	if err != nil {
+		return instruction, fmt.Errorf("Invalid to_account_id: %q", field("to_account_id"))
+	}
+
+	instruction.Amount, err = utils.ParseAmount(field("amount"))
+	return instruction, err
+}
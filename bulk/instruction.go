@@ -0,0 +1,59 @@
+// Parsing bulk payment files (CSV, ISO 20022 pain.001) into transfer instructions,
+// and reporting their status back as ISO 20022 pain.002.
+package bulk
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatPain001 = "pain.001"
+)
+
+var ErrUnsupportedFormat = errors.New("Unsupported payment file format, expected csv or pain.001")
+
+// a single transfer read from a payment file, the amount is in minor units
+type Instruction struct {
+	EndToEndId    string `json:"end_to_end_id"`
+	FromAccountId int64  `json:"from_account_id"`
+	ToAccountId   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+}
+
+// the parsed content of a payment file
+type File struct {
+	Format       string        `json:"format"`
+	MessageId    string        `json:"message_id"`
+	Instructions []Instruction `json:"instructions"`
+}
+
+// guesses the format of the file from its name, then from its content
+func DetectFormat(filename string, content []byte) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV
+	case ".xml":
+		return FormatPain001
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("<")) {
+		return FormatPain001
+	}
+	return FormatCSV
+}
+
+func Parse(format string, r io.Reader) (File, error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatPain001:
+		return ParsePain001(r)
+	}
+	return File{}, ErrUnsupportedFormat
+}
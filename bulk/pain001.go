@@ -0,0 +1,116 @@
+package bulk
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/AYehia0/go-bk-mst/utils"
+)
+
+// only the parts of the customer credit transfer initiation (pain.001.001.03) the bank needs,
+// the accounts are identified by the bank's account ids in Id/Othr/Id.
+type pain001Document struct {
+	XMLName xml.Name `xml:"Document"`
+	Initn   struct {
+		GrpHdr struct {
+			MsgId   string `xml:"MsgId"`
+			NbOfTxs string `xml:"NbOfTxs"`
+			CtrlSum string `xml:"CtrlSum"`
+		} `xml:"GrpHdr"`
+		PmtInf []pain001PaymentInfo `xml:"PmtInf"`
+	} `xml:"CstmrCdtTrfInitn"`
+}
+
+type pain001PaymentInfo struct {
+	PmtInfId string         `xml:"PmtInfId"`
+	DbtrAcct pain001Account `xml:"DbtrAcct"`
+	Txs      []struct {
+		EndToEndId string `xml:"PmtId>EndToEndId"`
+		InstdAmt   struct {
+			Ccy   string `xml:"Ccy,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"Amt>InstdAmt"`
+		CdtrAcct pain001Account `xml:"CdtrAcct"`
+	} `xml:"CdtTrfTxInf"`
+}
+
+type pain001Account struct {
+	IBAN string `xml:"Id>IBAN"`
+	Id   string `xml:"Id>Othr>Id"`
+}
+
+func (a pain001Account) accountId() (int64, error) {
+	if a.Id == "" && a.IBAN != "" {
+		return 0, fmt.Errorf("IBAN accounts are not supported: %s", a.IBAN)
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSpace(a.Id), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid account id: %q", a.Id)
+	}
+	return id, nil
+}
+
+func ParsePain001(r io.Reader) (File, error) {
+	file := File{Format: FormatPain001}
+
+	var doc pain001Document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return file, fmt.Errorf("Failed to read the pain.001 file: %w", err)
+	}
+	file.MessageId = doc.Initn.GrpHdr.MsgId
+
+	var controlSum int64
+	for _, payment := range doc.Initn.PmtInf {
+		fromAccountId, err := payment.DbtrAcct.accountId()
+		if err != nil {
+			return file, fmt.Errorf("Payment %s debtor account: %w", payment.PmtInfId, err)
+		}
+
+		for _, tx := range payment.Txs {
+			toAccountId, err := tx.CdtrAcct.accountId()
+			if err != nil {
+				return file, fmt.Errorf("Transaction %s creditor account: %w", tx.EndToEndId, err)
+			}
+
+			amount, err := utils.ParseAmount(tx.InstdAmt.Value)
+			if err != nil {
+				return file, fmt.Errorf("Transaction %s: %w", tx.EndToEndId, err)
+			}
+			// the amounts are positive, a sum wrapping around could match a crafted CtrlSum
+			if amount > math.MaxInt64-controlSum {
+				return file, fmt.Errorf("Transaction %s: the transactions add up to more than the largest amount", tx.EndToEndId)
+			}
+			controlSum += amount
+
+			file.Instructions = append(file.Instructions, Instruction{
+				EndToEndId:    tx.EndToEndId,
+				FromAccountId: fromAccountId,
+				ToAccountId:   toAccountId,
+				Amount:        amount,
+				Currency:      strings.ToUpper(tx.InstdAmt.Ccy),
+			})
+		}
+	}
+
+	if len(file.Instructions) == 0 {
+		return file, fmt.Errorf("The pain.001 file has no payments")
+	}
+
+	// the optional group header checks
+	if nb := doc.Initn.GrpHdr.NbOfTxs; nb != "" && nb != strconv.Itoa(len(file.Instructions)) {
+		return file, fmt.Errorf("NbOfTxs is %s but the file has %d transactions", nb, len(file.Instructions))
+	}
+	if sum := doc.Initn.GrpHdr.CtrlSum; sum != "" {
+		expected, err := utils.ParseAmount(sum)
+		if err != nil || expected != controlSum {
+			return file, fmt.Errorf("CtrlSum is %s but the transactions add up to %s", sum, utils.FormatAmount(controlSum))
+		}
+	}
+
+	return file, nil
+}
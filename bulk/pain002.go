@@ -0,0 +1,111 @@
+package bulk
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ISO 20022 transaction/group status codes
+const (
+	// accepted technical validation, waiting for confirmation
+	StatusAccepted = "ACTC"
+	// accepted settlement completed
+	StatusSettled  = "ACSC"
+	StatusRejected = "RJCT"
+	// only some of the transactions were accepted
+	StatusPartial = "PART"
+)
+
+const pain002Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.002.001.03"
+
+// the status of a single instruction in the report
+type TransactionStatus struct {
+	EndToEndId string
+	Status     string
+	Reason     string
+}
+
+// the status report of a payment file (pain.002.001.03)
+type StatusReport struct {
+	MessageId         string
+	CreatedAt         time.Time
+	OriginalMessageId string
+	OriginalFormat    string
+	Transactions      []TransactionStatus
+}
+
+type pain002Document struct {
+	XMLName xml.Name `xml:"Document"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Report  struct {
+		GrpHdr struct {
+			MsgId   string `xml:"MsgId"`
+			CreDtTm string `xml:"CreDtTm"`
+		} `xml:"GrpHdr"`
+		OrgnlGrpInfAndSts struct {
+			OrgnlMsgId   string `xml:"OrgnlMsgId"`
+			OrgnlMsgNmId string `xml:"OrgnlMsgNmId"`
+			OrgnlNbOfTxs string `xml:"OrgnlNbOfTxs"`
+			GrpSts       string `xml:"GrpSts"`
+		} `xml:"OrgnlGrpInfAndSts"`
+		OrgnlPmtInfAndSts struct {
+			OrgnlPmtInfId string            `xml:"OrgnlPmtInfId"`
+			TxInfAndSts   []pain002TxStatus `xml:"TxInfAndSts"`
+		} `xml:"OrgnlPmtInfAndSts"`
+	} `xml:"CstmrPmtStsRpt"`
+}
+
+type pain002TxStatus struct {
+	OrgnlEndToEndId string `xml:"OrgnlEndToEndId"`
+	TxSts           string `xml:"TxSts"`
+	AddtlInf        string `xml:"StsRsnInf>AddtlInf,omitempty"`
+}
+
+// the status of the whole group: the common status of all the transactions or partial
+func (r StatusReport) GroupStatus() string {
+	if len(r.Transactions) == 0 {
+		return StatusRejected
+	}
+
+	status := r.Transactions[0].Status
+	for _, tx := range r.Transactions[1:] {
+		if tx.Status != status {
+			return StatusPartial
+		}
+	}
+	return status
+}
+
+func (r StatusReport) WriteXML(w io.Writer) error {
+	var doc pain002Document
+	doc.Xmlns = pain002Namespace
+
+	doc.Report.GrpHdr.MsgId = r.MessageId
+	doc.Report.GrpHdr.CreDtTm = r.CreatedAt.UTC().Format("2006-01-02T15:04:05")
+
+	doc.Report.OrgnlGrpInfAndSts.OrgnlMsgId = r.OriginalMessageId
+	doc.Report.OrgnlGrpInfAndSts.OrgnlMsgNmId = "pain.001.001.03"
+	if r.OriginalFormat == FormatCSV {
+		doc.Report.OrgnlGrpInfAndSts.OrgnlMsgNmId = FormatCSV
+	}
+	doc.Report.OrgnlGrpInfAndSts.OrgnlNbOfTxs = strconv.Itoa(len(r.Transactions))
+	doc.Report.OrgnlGrpInfAndSts.GrpSts = r.GroupStatus()
+
+	doc.Report.OrgnlPmtInfAndSts.OrgnlPmtInfId = r.OriginalMessageId
+	for _, tx := range r.Transactions {
+		doc.Report.OrgnlPmtInfAndSts.TxInfAndSts = append(doc.Report.OrgnlPmtInfAndSts.TxInfAndSts, pain002TxStatus{
+			OrgnlEndToEndId: tx.EndToEndId,
+			TxSts:           tx.Status,
+			AddtlInf:        tx.Reason,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(doc)
+}
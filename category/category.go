@@ -0,0 +1,44 @@
+// The categories of the transfers, from the rules the users set on their descriptions.
+package category
+
+import (
+	"context"
+	"strings"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+)
+
+// the category of the first rule found in the description, or empty
+func Match(rules []db.CategoryRule, description string) string {
+	description = strings.ToLower(description)
+	for _, rule := range rules {
+		if strings.Contains(description, strings.ToLower(rule.Pattern)) {
+			return rule.Category
+		}
+	}
+	return ""
+}
+
+// fills in the missing categories of the transfers using the user's rules
+func Fill(ctx context.Context, q db.Querier, username string, args []db.TransferTxParams) error {
+	var rules []db.CategoryRule
+	loaded := false
+
+	for i := range args {
+		if args[i].Category != "" || args[i].Description == "" {
+			continue
+		}
+
+		// most transfers come with a category or without a description, load the rules only when needed
+		if !loaded {
+			var err error
+			rules, err = q.GetCategoryRules(ctx, username)
+			if err != nil {
+				return err
+			}
+			loaded = true
+		}
+		args[i].Category = Match(rules, args[i].Description)
+	}
+	return nil
+}
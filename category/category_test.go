@@ -0,0 +1,20 @@
+package category
+
+import (
+	"testing"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	rules := []db.CategoryRule{
+		{Pattern: "netflix", Category: "subscriptions"},
+		{Pattern: "NET", Category: "internet"},
+	}
+
+	// the first matching rule wins, regardless of the case
+	require.Equal(t, "subscriptions", Match(rules, "NETFLIX.COM 0923"))
+	require.Equal(t, "internet", Match(rules, "Vodafone net bill"))
+	require.Empty(t, Match(rules, "groceries"))
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "holds";
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "available_balance";
@@ -0,0 +1,31 @@
+-- funds reserved by pending holds are subtracted from the available balance only,
+-- the ledger balance changes once the hold is captured.
+ALTER TABLE "accounts" ADD COLUMN "available_balance" bigint NOT NULL DEFAULT 0;
+UPDATE "accounts" SET "available_balance" = "balance";
+
+CREATE TABLE "holds" (
+    "id" bigserial PRIMARY KEY,
+    "from_account_id" bigint NOT NULL,
+    "to_account_id" bigint NOT NULL,
+    "amount" bigint NOT NULL,
+    "status" varchar NOT NULL DEFAULT 'pending',
+    "transfer_id" bigint,
+    "expired_at" timestamptz NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "holds" ("from_account_id");
+
+CREATE INDEX ON "holds" ("to_account_id");
+
+CREATE INDEX ON "holds" ("status", "expired_at");
+
+COMMENT ON COLUMN "holds"."amount" IS 'Positive only!';
+
+COMMENT ON COLUMN "holds"."status" IS 'pending, captured, voided or expired';
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
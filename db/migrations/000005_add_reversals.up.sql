@@ -0,0 +1,22 @@
+-- a reversal is a compensating transfer (to -> from) linked to the original one
+CREATE TABLE "reversals" (
+    "id" bigserial PRIMARY KEY,
+    "transfer_id" bigint NOT NULL,
+    "reversal_transfer_id" bigint UNIQUE NOT NULL,
+    "amount" bigint NOT NULL,
+    "reason" varchar NOT NULL,
+    "actor" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "reversals" ("transfer_id");
+
+COMMENT ON COLUMN "reversals"."amount" IS 'Positive only!';
+
+COMMENT ON COLUMN "reversals"."actor" IS 'The user who requested the reversal';
+
+ALTER TABLE "reversals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "reversals" ADD FOREIGN KEY ("reversal_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "reversals" ADD FOREIGN KEY ("actor") REFERENCES "users" ("username");
@@ -0,0 +1,37 @@
+-- uploaded bulk payment files, kept between the preview and the confirmation
+CREATE TABLE "payment_files" (
+    "id" bigserial PRIMARY KEY,
+    "owner_name" varchar NOT NULL,
+    "format" varchar NOT NULL,
+    "message_id" varchar NOT NULL,
+    "status" varchar NOT NULL DEFAULT 'preview',
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "payment_instructions" (
+    "id" bigserial PRIMARY KEY,
+    "file_id" bigint NOT NULL,
+    "end_to_end_id" varchar NOT NULL,
+    "from_account_id" bigint NOT NULL,
+    "to_account_id" bigint NOT NULL,
+    "amount" bigint NOT NULL,
+    "currency" varchar NOT NULL,
+    "status" varchar NOT NULL,
+    "error" varchar NOT NULL DEFAULT '',
+    "transfer_id" bigint,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "payment_files" ("owner_name");
+
+CREATE INDEX ON "payment_instructions" ("file_id");
+
+COMMENT ON COLUMN "payment_files"."status" IS 'preview, processing or executed';
+
+COMMENT ON COLUMN "payment_instructions"."status" IS 'valid, invalid, completed or failed';
+
+ALTER TABLE "payment_files" ADD FOREIGN KEY ("owner_name") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_instructions" ADD FOREIGN KEY ("file_id") REFERENCES "payment_files" ("id");
+
+ALTER TABLE "payment_instructions" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
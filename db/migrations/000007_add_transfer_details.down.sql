@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "category_rules";
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "description";
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "reference";
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "category";
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "description";
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "reference";
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "category";
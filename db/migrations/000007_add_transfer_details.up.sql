@@ -0,0 +1,27 @@
+ALTER TABLE "transfers" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+ALTER TABLE "transfers" ADD COLUMN "reference" varchar NOT NULL DEFAULT '';
+ALTER TABLE "transfers" ADD COLUMN "category" varchar NOT NULL DEFAULT '';
+
+-- copied from the transfer, so the statements are readable on their own
+ALTER TABLE "entries" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+ALTER TABLE "entries" ADD COLUMN "reference" varchar NOT NULL DEFAULT '';
+ALTER TABLE "entries" ADD COLUMN "category" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "transfers" ("reference");
+
+CREATE INDEX ON "entries" ("account_id", "category");
+
+COMMENT ON COLUMN "transfers"."reference" IS 'End-to-end reference';
+
+-- user defined rules to categorise transfers: description contains pattern -> category
+CREATE TABLE "category_rules" (
+    "id" bigserial PRIMARY KEY,
+    "username" varchar NOT NULL,
+    "pattern" varchar NOT NULL,
+    "category" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "category_rules" ("username");
+
+ALTER TABLE "category_rules" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,25 @@
+-- overrides of the configured transfer limits, per user or per account (the account one wins).
+-- the NULL limits fall back to the next level, 0 means unlimited.
+CREATE TABLE "transfer_limits" (
+    "id" bigserial PRIMARY KEY,
+    "username" varchar UNIQUE,
+    "account_id" bigint UNIQUE,
+    "max_amount" bigint,
+    "daily_amount" bigint,
+    "hourly_count" bigint,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK (("username" IS NULL) <> ("account_id" IS NULL))
+);
+
+COMMENT ON COLUMN "transfer_limits"."max_amount" IS 'Max amount of a single transfer';
+
+COMMENT ON COLUMN "transfer_limits"."daily_amount" IS 'Max total sent in the last 24 hours';
+
+COMMENT ON COLUMN "transfer_limits"."hourly_count" IS 'Max number of transfers in the last hour';
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+-- the velocity checks look up the recent transfers of the sender
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
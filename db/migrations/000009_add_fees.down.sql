@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fee";
+DROP TABLE IF EXISTS "fee_schedules";
@@ -0,0 +1,22 @@
+-- a schedule row applies to the transfers of its currency from min_amount up to the next tier,
+-- fee = flat_fee + amount * percentage_bps / 10000
+CREATE TABLE "fee_schedules" (
+    "id" bigserial PRIMARY KEY,
+    "currency" varchar NOT NULL,
+    "min_amount" bigint NOT NULL DEFAULT 0,
+    "flat_fee" bigint NOT NULL DEFAULT 0,
+    "percentage_bps" bigint NOT NULL DEFAULT 0,
+    "fee_account_id" bigint NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "fee_schedules" ("currency", "min_amount");
+
+COMMENT ON COLUMN "fee_schedules"."percentage_bps" IS 'Basis points, 100 = 1%';
+
+COMMENT ON COLUMN "fee_schedules"."fee_account_id" IS 'The bank owned account credited with the fees';
+
+ALTER TABLE "fee_schedules" ADD FOREIGN KEY ("fee_account_id") REFERENCES "accounts" ("id");
+
+-- charged to the sender on top of the amount
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
@@ -0,0 +1,54 @@
+CREATE TABLE "interest_plans" (
+    "id" bigserial PRIMARY KEY,
+    "name" varchar NOT NULL,
+    "currency" varchar NOT NULL,
+    "annual_rate_bps" bigint NOT NULL,
+    "expense_account_id" bigint NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "interest_plans"."annual_rate_bps" IS 'Basis points a year, 250 = 2.5%';
+
+COMMENT ON COLUMN "interest_plans"."expense_account_id" IS 'The bank owned account paying the interest';
+
+ALTER TABLE "interest_plans" ADD FOREIGN KEY ("expense_account_id") REFERENCES "accounts" ("id");
+
+-- the savings accounts, an account has one plan at most
+CREATE TABLE "account_interest_plans" (
+    "account_id" bigint PRIMARY KEY,
+    "plan_id" bigint NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_interest_plans" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_interest_plans" ADD FOREIGN KEY ("plan_id") REFERENCES "interest_plans" ("id");
+
+-- one row per account and day, the daily accrual job can be rerun safely
+CREATE TABLE "interest_accruals" (
+    "id" bigserial PRIMARY KEY,
+    "account_id" bigint NOT NULL,
+    "plan_id" bigint NOT NULL,
+    "date" date NOT NULL,
+    "balance" bigint NOT NULL,
+    "amount" bigint NOT NULL,
+    "posted_at" timestamptz,
+    "transfer_id" bigint,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "date");
+
+CREATE INDEX ON "interest_accruals" ("posted_at", "date");
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'End of day balance';
+
+COMMENT ON COLUMN "interest_accruals"."amount" IS 'Millionths of the minor unit';
+
+COMMENT ON COLUMN "interest_accruals"."transfer_id" IS 'NULL until posted, or when the posted amount rounds to 0';
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("plan_id") REFERENCES "interest_plans" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
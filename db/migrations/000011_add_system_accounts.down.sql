@@ -0,0 +1,29 @@
+-- the system accounts can't be removed once the ledger or the bank's settings use them,
+-- the whole rollback is refused rather than leaving the books half way
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM "accounts" a
+        WHERE a."is_system" AND (
+            EXISTS (SELECT 1 FROM "entries" WHERE "account_id" = a."id") OR
+            EXISTS (SELECT 1 FROM "transfers" WHERE a."id" IN ("from_account_id", "to_account_id")) OR
+            EXISTS (SELECT 1 FROM "holds" WHERE a."id" IN ("from_account_id", "to_account_id")) OR
+            EXISTS (SELECT 1 FROM "transfer_limits" WHERE "account_id" = a."id") OR
+            EXISTS (SELECT 1 FROM "fee_schedules" WHERE "fee_account_id" = a."id") OR
+            EXISTS (SELECT 1 FROM "interest_plans" WHERE "expense_account_id" = a."id") OR
+            EXISTS (SELECT 1 FROM "account_interest_plans" WHERE "account_id" = a."id") OR
+            EXISTS (SELECT 1 FROM "interest_accruals" WHERE "account_id" = a."id")
+        )
+    ) THEN
+        RAISE EXCEPTION 'the system accounts are used by transfers, fee schedules or interest plans, they can''t be rolled back';
+    END IF;
+END $$;
+
+DELETE FROM "accounts" WHERE "is_system";
+DROP INDEX IF EXISTS "system_chart_currency_key";
+DROP INDEX IF EXISTS "owner_currency_key";
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner_name", "currency");
+DELETE FROM "users" WHERE "username" = '_bank';
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "is_system";
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "chart_code";
+DROP TABLE IF EXISTS "chart_of_accounts";
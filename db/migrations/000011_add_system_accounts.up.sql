@@ -0,0 +1,35 @@
+-- the bank's own books, every account is booked under one of these codes
+CREATE TABLE "chart_of_accounts" (
+    "code" varchar PRIMARY KEY,
+    "name" varchar NOT NULL,
+    "type" varchar NOT NULL,
+    CHECK ("type" IN ('asset', 'liability', 'income', 'expense'))
+);
+
+INSERT INTO "chart_of_accounts" ("code", "name", "type") VALUES
+    ('1000', 'Cash', 'asset'),
+    ('2000', 'Customer deposits', 'liability'),
+    ('4000', 'Fee income', 'income'),
+    ('4100', 'FX gains', 'income'),
+    ('5000', 'Interest expense', 'expense');
+
+-- the customers' money is a liability of the bank
+ALTER TABLE "accounts" ADD COLUMN "chart_code" varchar NOT NULL DEFAULT '2000';
+ALTER TABLE "accounts" ADD COLUMN "is_system" boolean NOT NULL DEFAULT false;
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("chart_code") REFERENCES "chart_of_accounts" ("code");
+
+-- the system accounts belong to a user nobody can sign up as or log in with (not alphanumeric, no valid password)
+INSERT INTO "users" ("username", "email", "password", "full_name")
+VALUES ('_bank', 'bank@system.invalid', '!', 'Bank');
+
+-- the bank has many accounts of the same currency, one per chart code
+ALTER TABLE "accounts" DROP CONSTRAINT "owner_currency_key";
+CREATE UNIQUE INDEX "owner_currency_key" ON "accounts" ("owner_name", "currency") WHERE NOT "is_system";
+CREATE UNIQUE INDEX "system_chart_currency_key" ON "accounts" ("chart_code", "currency") WHERE "is_system";
+
+INSERT INTO "accounts" ("owner_name", "balance", "available_balance", "currency", "chart_code", "is_system")
+SELECT '_bank', 0, 0, c.currency, coa.code, true
+FROM (VALUES ('USD'), ('EUR'), ('EGP'), ('CAD')) AS c (currency)
+CROSS JOIN "chart_of_accounts" coa
+WHERE coa.code <> '2000';
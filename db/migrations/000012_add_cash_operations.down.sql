@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "cash_receipts";
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,25 @@
+-- depositors are the regular customers, tellers handle the cash at the counter
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'depositor';
+
+COMMENT ON COLUMN "users"."role" IS 'depositor or teller';
+
+CREATE TABLE "cash_receipts" (
+    "id" bigserial PRIMARY KEY,
+    "kind" varchar NOT NULL,
+    "account_id" bigint NOT NULL,
+    "amount" bigint NOT NULL,
+    "currency" varchar NOT NULL,
+    "teller" varchar NOT NULL,
+    "transfer_id" bigint NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "cash_receipts" ("account_id");
+
+COMMENT ON COLUMN "cash_receipts"."kind" IS 'deposit or withdrawal';
+
+ALTER TABLE "cash_receipts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "cash_receipts" ADD FOREIGN KEY ("teller") REFERENCES "users" ("username");
+
+ALTER TABLE "cash_receipts" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
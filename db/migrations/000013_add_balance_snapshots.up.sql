@@ -0,0 +1,15 @@
+-- the balance of the account right after all the entries created until taken_at,
+-- the balance at any time is the closest snapshot before it plus the entries in between.
+CREATE TABLE "balance_snapshots" (
+    "id" bigserial PRIMARY KEY,
+    "account_id" bigint NOT NULL,
+    "taken_at" timestamptz NOT NULL,
+    "balance" bigint NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "balance_snapshots" ("account_id", "taken_at");
+
+ALTER TABLE "balance_snapshots" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
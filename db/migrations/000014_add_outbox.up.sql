@@ -0,0 +1,18 @@
+-- the domain events, written in the same transaction as the change they describe
+-- and published to the other systems afterwards by the relay (at least once, in id order).
+CREATE TABLE "outbox_events" (
+    "id" bigserial PRIMARY KEY,
+    -- transfer.completed, account.created, user.registered...
+    "event_type" varchar NOT NULL,
+    -- what the event is about, the transfer/account id or the username
+    "aggregate_type" varchar NOT NULL,
+    "aggregate_id" varchar NOT NULL,
+    "payload" jsonb NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "published_at" timestamptz,
+    "attempts" int NOT NULL DEFAULT 0,
+    "last_error" text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX ON "outbox_events" ("id") WHERE "published_at" IS NULL;
+CREATE INDEX ON "outbox_events" ("aggregate_type", "aggregate_id");
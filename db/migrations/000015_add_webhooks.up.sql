@@ -0,0 +1,41 @@
+-- where to POST the events of the user's accounts, signed with the secret
+CREATE TABLE "webhook_subscriptions" (
+    "id" bigserial PRIMARY KEY,
+    "owner_name" varchar NOT NULL,
+    "url" varchar NOT NULL,
+    -- the event types delivered, transfer.completed, account.created...
+    "events" varchar[] NOT NULL,
+    "secret" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "webhook_subscriptions" ("owner_name");
+
+ALTER TABLE "webhook_subscriptions" ADD FOREIGN KEY ("owner_name") REFERENCES "users" ("username");
+
+-- an event to deliver to a subscription, and the log of its attempts
+CREATE TABLE "webhook_deliveries" (
+    "id" bigserial PRIMARY KEY,
+    "subscription_id" bigint NOT NULL,
+    "event_id" bigint NOT NULL,
+    "event_type" varchar NOT NULL,
+    -- the body POSTed, the same on every attempt
+    "payload" jsonb NOT NULL,
+    -- pending, succeeded or failed (out of attempts)
+    "status" varchar NOT NULL DEFAULT 'pending',
+    "attempts" int NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+    "last_status_code" int NOT NULL DEFAULT 0,
+    "last_error" text NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "delivered_at" timestamptz
+);
+
+-- an event relayed twice is delivered once
+CREATE UNIQUE INDEX ON "webhook_deliveries" ("subscription_id", "event_id");
+
+CREATE INDEX ON "webhook_deliveries" ("next_attempt_at") WHERE "status" = 'pending';
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("event_id") REFERENCES "outbox_events" ("id");
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "users" DROP CONSTRAINT IF EXISTS "users_role_check";
+
+COMMENT ON COLUMN "users"."role" IS 'depositor or teller';
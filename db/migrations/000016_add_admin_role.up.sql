@@ -0,0 +1,4 @@
+-- the admins manage the transfer limit overrides
+COMMENT ON COLUMN "users"."role" IS 'depositor, teller or admin';
+
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('depositor', 'teller', 'admin'));
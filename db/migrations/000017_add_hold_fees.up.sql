@@ -0,0 +1,7 @@
+-- the fee quoted when the funds were reserved, it's charged as is once the hold is captured
+ALTER TABLE "holds" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "holds" ADD COLUMN "fee_account_id" bigint;
+
+COMMENT ON COLUMN "holds"."fee" IS 'Reserved on top of the amount';
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("fee_account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,93 @@
+// The sql migrations of the database, embedded in the binary and run by its migrate subcommand or on startup.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"io/fs"
+	"log"
+
+	"github.com/golang-migrate/migrate/v4"
+	// registers the postgres:// and postgresql:// urls
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// the key of the advisory lock of the auto-migration, it mustn't be the one migrate locks each run with,
+// which is derived from the database name
+const autoMigrateLockId = 7_351_208_114
+
+// the migrate instance of the embedded migrations, it opens its own connection which Close releases
+func New(dataSource string) (*migrate.Migrate, error) {
+	source, err := iofs.New(FS, ".")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.NewWithSourceInstance("iofs", source, dataSource)
+}
+
+// the version of the newest embedded migration, the one the code expects the database at
+func Latest() (uint, error) {
+	source, err := iofs.New(FS, ".")
+	if err != nil {
+		return 0, err
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// runs the migrations not applied yet. the replicas starting together wait on the advisory lock,
+// so only the first one migrates and the others find the database up to date.
+func AutoMigrate(ctx context.Context, conn *sql.DB, dataSource string) error {
+	lockConn, err := conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer lockConn.Close()
+
+	// released with the session if the process dies half way
+	if _, err := lockConn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", autoMigrateLockId); err != nil {
+		return err
+	}
+	defer lockConn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", autoMigrateLockId)
+
+	m, err := New(dataSource)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	err = m.Up()
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	version, _, err := m.Version()
+	if err != nil {
+		return err
+	}
+	log.Printf("Migrated the database to version %d", version)
+	return nil
+}
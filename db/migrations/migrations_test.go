@@ -0,0 +1,130 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/utils"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatest(t *testing.T) {
+	names, err := fs.Glob(FS, "*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, names)
+
+	var newest uint64
+	for _, name := range names {
+		// every migration can be rolled back
+		_, err := fs.Stat(FS, strings.TrimSuffix(name, ".up.sql")+".down.sql")
+		require.NoError(t, err, name)
+
+		version, err := strconv.ParseUint(strings.SplitN(name, "_", 2)[0], 10, 64)
+		require.NoError(t, err, name)
+		newest = max(newest, version)
+	}
+
+	latest, err := Latest()
+	require.NoError(t, err)
+	require.Equal(t, uint(newest), latest)
+}
+
+func TestAutoMigrate(t *testing.T) {
+	config, err := utils.ConfigStore("../..", "config", "env")
+	require.NoError(t, err)
+
+	conn, err := sql.Open(config.DbDriver, config.DbSource)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// the replicas starting together, the ones after the first find nothing to do
+	errs := make(chan error, 3)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			errs <- AutoMigrate(context.Background(), conn, config.DbSource)
+		}()
+	}
+	for i := 0; i < cap(errs); i++ {
+		require.NoError(t, <-errs)
+	}
+
+	m, err := New(config.DbSource)
+	require.NoError(t, err)
+	defer m.Close()
+
+	version, dirty, err := m.Version()
+	require.NoError(t, err)
+	require.False(t, dirty)
+
+	latest, err := Latest()
+	require.NoError(t, err)
+	require.Equal(t, latest, version)
+}
+
+// a database of its own, the rollbacks would break the tests of the other packages sharing the main one
+func createScratchDatabase(t *testing.T) (*sql.DB, string) {
+	config, err := utils.ConfigStore("../..", "config", "env")
+	require.NoError(t, err)
+
+	conn, err := sql.Open(config.DbDriver, config.DbSource)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	name := fmt.Sprintf("simple_bank_migrations_%d", time.Now().UnixNano())
+	_, err = conn.Exec("CREATE DATABASE " + name)
+	require.NoError(t, err)
+
+	source, err := url.Parse(config.DbSource)
+	require.NoError(t, err)
+	source.Path = "/" + name
+
+	scratch, err := sql.Open(config.DbDriver, source.String())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		scratch.Close()
+		conn.Exec("DROP DATABASE IF EXISTS " + name)
+	})
+	return scratch, source.String()
+}
+
+func TestDownSystemAccounts(t *testing.T) {
+	conn, dataSource := createScratchDatabase(t)
+
+	m, err := New(dataSource)
+	require.NoError(t, err)
+	defer m.Close()
+
+	// nothing uses the system accounts yet, they are removed with the schema
+	require.NoError(t, m.Migrate(11))
+	require.NoError(t, m.Migrate(10))
+	require.NoError(t, m.Migrate(11))
+
+	// a deposit from the bank's cash account
+	_, err = conn.Exec(`INSERT INTO users (username, email, password, full_name) VALUES ('alice', 'alice@example.com', 'secret', 'Alice')`)
+	require.NoError(t, err)
+
+	var customerId, cashId int64
+	require.NoError(t, conn.QueryRow(`INSERT INTO accounts (owner_name, balance, available_balance, currency) VALUES ('alice', 100, 100, 'USD') RETURNING id`).Scan(&customerId))
+	require.NoError(t, conn.QueryRow(`SELECT id FROM accounts WHERE is_system AND chart_code = '1000' AND currency = 'USD'`).Scan(&cashId))
+
+	_, err = conn.Exec(`INSERT INTO transfers (from_account_id, to_account_id, amount) VALUES ($1, $2, 100)`, cashId, customerId)
+	require.NoError(t, err)
+	_, err = conn.Exec(`INSERT INTO entries (account_id, amount) VALUES ($1, -100), ($2, 100)`, cashId, customerId)
+	require.NoError(t, err)
+
+	// the ledger would lose one side of the transfer, the rollback is refused as a whole
+	err = m.Migrate(10)
+	require.ErrorContains(t, err, "the system accounts are used")
+
+	var count int
+	require.NoError(t, conn.QueryRow(`SELECT COUNT(*) FROM accounts WHERE is_system`).Scan(&count))
+	require.NotZero(t, count)
+}
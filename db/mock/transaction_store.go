@@ -7,6 +7,7 @@ package storedb
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	db "github.com/AYehia0/go-bk-mst/db/sqlc"
 	gomock "github.com/golang/mock/gomock"
@@ -36,6 +37,51 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AccrueInterest mocks base method.
+func (m *MockStore) AccrueInterest(arg0 context.Context, arg1 db.AccrueInterestParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccrueInterest", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AccrueInterest indicates an expected call of AccrueInterest.
+func (mr *MockStoreMockRecorder) AccrueInterest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccrueInterest", reflect.TypeOf((*MockStore)(nil).AccrueInterest), arg0, arg1)
+}
+
+// AccrueInterestTransaction mocks base method.
+func (m *MockStore) AccrueInterestTransaction(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccrueInterestTransaction", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AccrueInterestTransaction indicates an expected call of AccrueInterestTransaction.
+func (mr *MockStoreMockRecorder) AccrueInterestTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccrueInterestTransaction", reflect.TypeOf((*MockStore)(nil).AccrueInterestTransaction), arg0, arg1)
+}
+
+// AddAccountAvailableBalance mocks base method.
+func (m *MockStore) AddAccountAvailableBalance(arg0 context.Context, arg1 db.AddAccountAvailableBalanceParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAccountAvailableBalance", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAccountAvailableBalance indicates an expected call of AddAccountAvailableBalance.
+func (mr *MockStoreMockRecorder) AddAccountAvailableBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountAvailableBalance", reflect.TypeOf((*MockStore)(nil).AddAccountAvailableBalance), arg0, arg1)
+}
+
 // AddAccountBalance mocks base method.
 func (m *MockStore) AddAccountBalance(arg0 context.Context, arg1 db.AddAccountBalanceParams) (db.Account, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +97,81 @@ func (mr *MockStoreMockRecorder) AddAccountBalance(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockStore)(nil).AddAccountBalance), arg0, arg1)
 }
 
+// BalanceAt mocks base method.
+func (m *MockStore) BalanceAt(arg0 context.Context, arg1 db.Account, arg2 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BalanceAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BalanceAt indicates an expected call of BalanceAt.
+func (mr *MockStoreMockRecorder) BalanceAt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BalanceAt", reflect.TypeOf((*MockStore)(nil).BalanceAt), arg0, arg1, arg2)
+}
+
+// BatchTransferTransaction mocks base method.
+func (m *MockStore) BatchTransferTransaction(arg0 context.Context, arg1 []db.TransferTxParams) ([]db.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchTransferTransaction", arg0, arg1)
+	ret0, _ := ret[0].([]db.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchTransferTransaction indicates an expected call of BatchTransferTransaction.
+func (mr *MockStoreMockRecorder) BatchTransferTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchTransferTransaction", reflect.TypeOf((*MockStore)(nil).BatchTransferTransaction), arg0, arg1)
+}
+
+// CaptureHoldTransaction mocks base method.
+func (m *MockStore) CaptureHoldTransaction(arg0 context.Context, arg1 int64) (db.CaptureHoldTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHoldTransaction", arg0, arg1)
+	ret0, _ := ret[0].(db.CaptureHoldTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHoldTransaction indicates an expected call of CaptureHoldTransaction.
+func (mr *MockStoreMockRecorder) CaptureHoldTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHoldTransaction", reflect.TypeOf((*MockStore)(nil).CaptureHoldTransaction), arg0, arg1)
+}
+
+// CashTransaction mocks base method.
+func (m *MockStore) CashTransaction(arg0 context.Context, arg1 db.CashTxParams) (db.CashTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CashTransaction", arg0, arg1)
+	ret0, _ := ret[0].(db.CashTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CashTransaction indicates an expected call of CashTransaction.
+func (mr *MockStoreMockRecorder) CashTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CashTransaction", reflect.TypeOf((*MockStore)(nil).CashTransaction), arg0, arg1)
+}
+
+// ClaimWebhookDeliveries mocks base method.
+func (m *MockStore) ClaimWebhookDeliveries(arg0 context.Context, arg1 db.ClaimWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDeliveries indicates an expected call of ClaimWebhookDeliveries.
+func (mr *MockStoreMockRecorder) ClaimWebhookDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).ClaimWebhookDeliveries), arg0, arg1)
+}
+
 // CreateAccount mocks base method.
 func (m *MockStore) CreateAccount(arg0 context.Context, arg1 db.CreateAccountParams) (db.Account, error) {
 	m.ctrl.T.Helper()
@@ -66,6 +187,66 @@ func (mr *MockStoreMockRecorder) CreateAccount(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockStore)(nil).CreateAccount), arg0, arg1)
 }
 
+// CreateAccountTransaction mocks base method.
+func (m *MockStore) CreateAccountTransaction(arg0 context.Context, arg1 db.CreateAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountTransaction", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountTransaction indicates an expected call of CreateAccountTransaction.
+func (mr *MockStoreMockRecorder) CreateAccountTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountTransaction", reflect.TypeOf((*MockStore)(nil).CreateAccountTransaction), arg0, arg1)
+}
+
+// CreateBalanceSnapshots mocks base method.
+func (m *MockStore) CreateBalanceSnapshots(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBalanceSnapshots", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBalanceSnapshots indicates an expected call of CreateBalanceSnapshots.
+func (mr *MockStoreMockRecorder) CreateBalanceSnapshots(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBalanceSnapshots", reflect.TypeOf((*MockStore)(nil).CreateBalanceSnapshots), arg0, arg1)
+}
+
+// CreateCashReceipt mocks base method.
+func (m *MockStore) CreateCashReceipt(arg0 context.Context, arg1 db.CreateCashReceiptParams) (db.CashReceipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCashReceipt", arg0, arg1)
+	ret0, _ := ret[0].(db.CashReceipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCashReceipt indicates an expected call of CreateCashReceipt.
+func (mr *MockStoreMockRecorder) CreateCashReceipt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCashReceipt", reflect.TypeOf((*MockStore)(nil).CreateCashReceipt), arg0, arg1)
+}
+
+// CreateCategoryRule mocks base method.
+func (m *MockStore) CreateCategoryRule(arg0 context.Context, arg1 db.CreateCategoryRuleParams) (db.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategoryRule", arg0, arg1)
+	ret0, _ := ret[0].(db.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategoryRule indicates an expected call of CreateCategoryRule.
+func (mr *MockStoreMockRecorder) CreateCategoryRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategoryRule", reflect.TypeOf((*MockStore)(nil).CreateCategoryRule), arg0, arg1)
+}
+
 // CreateEntry mocks base method.
 func (m *MockStore) CreateEntry(arg0 context.Context, arg1 db.CreateEntryParams) (db.Entry, error) {
 	m.ctrl.T.Helper()
@@ -81,6 +262,126 @@ func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
 }
 
+// CreateFeeSchedule mocks base method.
+func (m *MockStore) CreateFeeSchedule(arg0 context.Context, arg1 db.CreateFeeScheduleParams) (db.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFeeSchedule", arg0, arg1)
+	ret0, _ := ret[0].(db.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFeeSchedule indicates an expected call of CreateFeeSchedule.
+func (mr *MockStoreMockRecorder) CreateFeeSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFeeSchedule", reflect.TypeOf((*MockStore)(nil).CreateFeeSchedule), arg0, arg1)
+}
+
+// CreateHold mocks base method.
+func (m *MockStore) CreateHold(arg0 context.Context, arg1 db.CreateHoldParams) (db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockStoreMockRecorder) CreateHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockStore)(nil).CreateHold), arg0, arg1)
+}
+
+// CreateInterestPlan mocks base method.
+func (m *MockStore) CreateInterestPlan(arg0 context.Context, arg1 db.CreateInterestPlanParams) (db.InterestPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInterestPlan", arg0, arg1)
+	ret0, _ := ret[0].(db.InterestPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInterestPlan indicates an expected call of CreateInterestPlan.
+func (mr *MockStoreMockRecorder) CreateInterestPlan(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInterestPlan", reflect.TypeOf((*MockStore)(nil).CreateInterestPlan), arg0, arg1)
+}
+
+// CreateOutboxEvent mocks base method.
+func (m *MockStore) CreateOutboxEvent(arg0 context.Context, arg1 db.CreateOutboxEventParams) (db.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOutboxEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOutboxEvent indicates an expected call of CreateOutboxEvent.
+func (mr *MockStoreMockRecorder) CreateOutboxEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOutboxEvent", reflect.TypeOf((*MockStore)(nil).CreateOutboxEvent), arg0, arg1)
+}
+
+// CreatePaymentFile mocks base method.
+func (m *MockStore) CreatePaymentFile(arg0 context.Context, arg1 db.CreatePaymentFileParams) (db.PaymentFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentFile", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePaymentFile indicates an expected call of CreatePaymentFile.
+func (mr *MockStoreMockRecorder) CreatePaymentFile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentFile", reflect.TypeOf((*MockStore)(nil).CreatePaymentFile), arg0, arg1)
+}
+
+// CreatePaymentFileTransaction mocks base method.
+func (m *MockStore) CreatePaymentFileTransaction(arg0 context.Context, arg1 db.CreatePaymentFileTxParams) (db.PaymentFileTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentFileTransaction", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentFileTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePaymentFileTransaction indicates an expected call of CreatePaymentFileTransaction.
+func (mr *MockStoreMockRecorder) CreatePaymentFileTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentFileTransaction", reflect.TypeOf((*MockStore)(nil).CreatePaymentFileTransaction), arg0, arg1)
+}
+
+// CreatePaymentInstruction mocks base method.
+func (m *MockStore) CreatePaymentInstruction(arg0 context.Context, arg1 db.CreatePaymentInstructionParams) (db.PaymentInstruction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePaymentInstruction", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentInstruction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePaymentInstruction indicates an expected call of CreatePaymentInstruction.
+func (mr *MockStoreMockRecorder) CreatePaymentInstruction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePaymentInstruction", reflect.TypeOf((*MockStore)(nil).CreatePaymentInstruction), arg0, arg1)
+}
+
+// CreateReversal mocks base method.
+func (m *MockStore) CreateReversal(arg0 context.Context, arg1 db.CreateReversalParams) (db.Reversal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReversal", arg0, arg1)
+	ret0, _ := ret[0].(db.Reversal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReversal indicates an expected call of CreateReversal.
+func (mr *MockStoreMockRecorder) CreateReversal(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReversal", reflect.TypeOf((*MockStore)(nil).CreateReversal), arg0, arg1)
+}
+
 // CreateSession mocks base method.
 func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
 	m.ctrl.T.Helper()
@@ -96,6 +397,21 @@ func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
 }
 
+// CreateSystemAccount mocks base method.
+func (m *MockStore) CreateSystemAccount(arg0 context.Context, arg1 db.CreateSystemAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSystemAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSystemAccount indicates an expected call of CreateSystemAccount.
+func (mr *MockStoreMockRecorder) CreateSystemAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSystemAccount", reflect.TypeOf((*MockStore)(nil).CreateSystemAccount), arg0, arg1)
+}
+
 // CreateTransfer mocks base method.
 func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 db.CreateTransferParams) (db.Transfer, error) {
 	m.ctrl.T.Helper()
@@ -126,6 +442,65 @@ func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
 }
 
+// CreateUserTransaction mocks base method.
+func (m *MockStore) CreateUserTransaction(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserTransaction", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserTransaction indicates an expected call of CreateUserTransaction.
+func (mr *MockStoreMockRecorder) CreateUserTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserTransaction", reflect.TypeOf((*MockStore)(nil).CreateUserTransaction), arg0, arg1)
+}
+
+// CreateWebhookDeliveriesTransaction mocks base method.
+func (m *MockStore) CreateWebhookDeliveriesTransaction(arg0 context.Context, arg1 db.CreateWebhookDeliveriesTxParams) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDeliveriesTransaction", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookDeliveriesTransaction indicates an expected call of CreateWebhookDeliveriesTransaction.
+func (mr *MockStoreMockRecorder) CreateWebhookDeliveriesTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDeliveriesTransaction", reflect.TypeOf((*MockStore)(nil).CreateWebhookDeliveriesTransaction), arg0, arg1)
+}
+
+// CreateWebhookDelivery mocks base method.
+func (m *MockStore) CreateWebhookDelivery(arg0 context.Context, arg1 db.CreateWebhookDeliveryParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhookDelivery indicates an expected call of CreateWebhookDelivery.
+func (mr *MockStoreMockRecorder) CreateWebhookDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDelivery", reflect.TypeOf((*MockStore)(nil).CreateWebhookDelivery), arg0, arg1)
+}
+
+// CreateWebhookSubscription mocks base method.
+func (m *MockStore) CreateWebhookSubscription(arg0 context.Context, arg1 db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookSubscription", arg0, arg1)
+	ret0, _ := ret[0].(db.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookSubscription indicates an expected call of CreateWebhookSubscription.
+func (mr *MockStoreMockRecorder) CreateWebhookSubscription(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookSubscription", reflect.TypeOf((*MockStore)(nil).CreateWebhookSubscription), arg0, arg1)
+}
+
 // DeleteAccount mocks base method.
 func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 int64) error {
 	m.ctrl.T.Helper()
@@ -140,6 +515,80 @@ func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
 }
 
+// DeleteCategoryRule mocks base method.
+func (m *MockStore) DeleteCategoryRule(arg0 context.Context, arg1 db.DeleteCategoryRuleParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategoryRule", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteCategoryRule indicates an expected call of DeleteCategoryRule.
+func (mr *MockStoreMockRecorder) DeleteCategoryRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategoryRule", reflect.TypeOf((*MockStore)(nil).DeleteCategoryRule), arg0, arg1)
+}
+
+// DeleteFeeSchedule mocks base method.
+func (m *MockStore) DeleteFeeSchedule(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeeSchedule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFeeSchedule indicates an expected call of DeleteFeeSchedule.
+func (mr *MockStoreMockRecorder) DeleteFeeSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeeSchedule", reflect.TypeOf((*MockStore)(nil).DeleteFeeSchedule), arg0, arg1)
+}
+
+// DeleteWebhookSubscription mocks base method.
+func (m *MockStore) DeleteWebhookSubscription(arg0 context.Context, arg1 db.DeleteWebhookSubscriptionParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookSubscription", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWebhookSubscription indicates an expected call of DeleteWebhookSubscription.
+func (mr *MockStoreMockRecorder) DeleteWebhookSubscription(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookSubscription", reflect.TypeOf((*MockStore)(nil).DeleteWebhookSubscription), arg0, arg1)
+}
+
+// ExecutePaymentInstructionTransaction mocks base method.
+func (m *MockStore) ExecutePaymentInstructionTransaction(arg0 context.Context, arg1 int64, arg2 *db.TransferLimits) (db.PaymentInstruction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecutePaymentInstructionTransaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.PaymentInstruction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecutePaymentInstructionTransaction indicates an expected call of ExecutePaymentInstructionTransaction.
+func (mr *MockStoreMockRecorder) ExecutePaymentInstructionTransaction(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecutePaymentInstructionTransaction", reflect.TypeOf((*MockStore)(nil).ExecutePaymentInstructionTransaction), arg0, arg1, arg2)
+}
+
+// ExpireHoldsTransaction mocks base method.
+func (m *MockStore) ExpireHoldsTransaction(arg0 context.Context, arg1 int32) ([]db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHoldsTransaction", arg0, arg1)
+	ret0, _ := ret[0].([]db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireHoldsTransaction indicates an expected call of ExpireHoldsTransaction.
+func (mr *MockStoreMockRecorder) ExpireHoldsTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHoldsTransaction", reflect.TypeOf((*MockStore)(nil).ExpireHoldsTransaction), arg0, arg1)
+}
+
 // GetAccountById mocks base method.
 func (m *MockStore) GetAccountById(arg0 context.Context, arg1 int64) (db.Account, error) {
 	m.ctrl.T.Helper()
@@ -185,36 +634,381 @@ func (mr *MockStoreMockRecorder) GetAccounts(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccounts", reflect.TypeOf((*MockStore)(nil).GetAccounts), arg0, arg1)
 }
 
-// GetEntries mocks base method.
-func (m *MockStore) GetEntries(arg0 context.Context, arg1 db.GetEntriesParams) ([]db.Entry, error) {
+// GetAccountsWebhookSubscriptions mocks base method.
+func (m *MockStore) GetAccountsWebhookSubscriptions(arg0 context.Context, arg1 db.GetAccountsWebhookSubscriptionsParams) ([]db.WebhookSubscription, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntries", arg0, arg1)
-	ret0, _ := ret[0].([]db.Entry)
+	ret := m.ctrl.Call(m, "GetAccountsWebhookSubscriptions", arg0, arg1)
+	ret0, _ := ret[0].([]db.WebhookSubscription)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEntries indicates an expected call of GetEntries.
-func (mr *MockStoreMockRecorder) GetEntries(arg0, arg1 interface{}) *gomock.Call {
+// GetAccountsWebhookSubscriptions indicates an expected call of GetAccountsWebhookSubscriptions.
+func (mr *MockStoreMockRecorder) GetAccountsWebhookSubscriptions(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntries", reflect.TypeOf((*MockStore)(nil).GetEntries), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountsWebhookSubscriptions", reflect.TypeOf((*MockStore)(nil).GetAccountsWebhookSubscriptions), arg0, arg1)
 }
 
-// GetEntryById mocks base method.
-func (m *MockStore) GetEntryById(arg0 context.Context, arg1 int64) (db.Entry, error) {
+// GetCashReceiptById mocks base method.
+func (m *MockStore) GetCashReceiptById(arg0 context.Context, arg1 int64) (db.CashReceipt, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntryById", arg0, arg1)
-	ret0, _ := ret[0].(db.Entry)
+	ret := m.ctrl.Call(m, "GetCashReceiptById", arg0, arg1)
+	ret0, _ := ret[0].(db.CashReceipt)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEntryById indicates an expected call of GetEntryById.
+// GetCashReceiptById indicates an expected call of GetCashReceiptById.
+func (mr *MockStoreMockRecorder) GetCashReceiptById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCashReceiptById", reflect.TypeOf((*MockStore)(nil).GetCashReceiptById), arg0, arg1)
+}
+
+// GetCategoryRules mocks base method.
+func (m *MockStore) GetCategoryRules(arg0 context.Context, arg1 string) ([]db.CategoryRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryRules", arg0, arg1)
+	ret0, _ := ret[0].([]db.CategoryRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryRules indicates an expected call of GetCategoryRules.
+func (mr *MockStoreMockRecorder) GetCategoryRules(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryRules", reflect.TypeOf((*MockStore)(nil).GetCategoryRules), arg0, arg1)
+}
+
+// GetChartOfAccounts mocks base method.
+func (m *MockStore) GetChartOfAccounts(arg0 context.Context) ([]db.ChartOfAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChartOfAccounts", arg0)
+	ret0, _ := ret[0].([]db.ChartOfAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChartOfAccounts indicates an expected call of GetChartOfAccounts.
+func (mr *MockStoreMockRecorder) GetChartOfAccounts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChartOfAccounts", reflect.TypeOf((*MockStore)(nil).GetChartOfAccounts), arg0)
+}
+
+// GetEntries mocks base method.
+func (m *MockStore) GetEntries(arg0 context.Context, arg1 db.GetEntriesParams) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntries indicates an expected call of GetEntries.
+func (mr *MockStoreMockRecorder) GetEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntries", reflect.TypeOf((*MockStore)(nil).GetEntries), arg0, arg1)
+}
+
+// GetEntriesBetween mocks base method.
+func (m *MockStore) GetEntriesBetween(arg0 context.Context, arg1 db.GetEntriesBetweenParams) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntriesBetween", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntriesBetween indicates an expected call of GetEntriesBetween.
+func (mr *MockStoreMockRecorder) GetEntriesBetween(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntriesBetween", reflect.TypeOf((*MockStore)(nil).GetEntriesBetween), arg0, arg1)
+}
+
+// GetEntryById mocks base method.
+func (m *MockStore) GetEntryById(arg0 context.Context, arg1 int64) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntryById", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntryById indicates an expected call of GetEntryById.
 func (mr *MockStoreMockRecorder) GetEntryById(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntryById", reflect.TypeOf((*MockStore)(nil).GetEntryById), arg0, arg1)
 }
 
+// GetExpiredHoldsForUpdate mocks base method.
+func (m *MockStore) GetExpiredHoldsForUpdate(arg0 context.Context, arg1 int32) ([]db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredHoldsForUpdate", arg0, arg1)
+	ret0, _ := ret[0].([]db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredHoldsForUpdate indicates an expected call of GetExpiredHoldsForUpdate.
+func (mr *MockStoreMockRecorder) GetExpiredHoldsForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredHoldsForUpdate", reflect.TypeOf((*MockStore)(nil).GetExpiredHoldsForUpdate), arg0, arg1)
+}
+
+// GetFeeSchedule mocks base method.
+func (m *MockStore) GetFeeSchedule(arg0 context.Context, arg1 db.GetFeeScheduleParams) (db.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeeSchedule", arg0, arg1)
+	ret0, _ := ret[0].(db.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeeSchedule indicates an expected call of GetFeeSchedule.
+func (mr *MockStoreMockRecorder) GetFeeSchedule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeSchedule", reflect.TypeOf((*MockStore)(nil).GetFeeSchedule), arg0, arg1)
+}
+
+// GetFeeSchedules mocks base method.
+func (m *MockStore) GetFeeSchedules(arg0 context.Context, arg1 string) ([]db.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeeSchedules", arg0, arg1)
+	ret0, _ := ret[0].([]db.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeeSchedules indicates an expected call of GetFeeSchedules.
+func (mr *MockStoreMockRecorder) GetFeeSchedules(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeeSchedules", reflect.TypeOf((*MockStore)(nil).GetFeeSchedules), arg0, arg1)
+}
+
+// GetHoldById mocks base method.
+func (m *MockStore) GetHoldById(arg0 context.Context, arg1 int64) (db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHoldById", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHoldById indicates an expected call of GetHoldById.
+func (mr *MockStoreMockRecorder) GetHoldById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHoldById", reflect.TypeOf((*MockStore)(nil).GetHoldById), arg0, arg1)
+}
+
+// GetHoldByIdForUpdate mocks base method.
+func (m *MockStore) GetHoldByIdForUpdate(arg0 context.Context, arg1 int64) (db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHoldByIdForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHoldByIdForUpdate indicates an expected call of GetHoldByIdForUpdate.
+func (mr *MockStoreMockRecorder) GetHoldByIdForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHoldByIdForUpdate", reflect.TypeOf((*MockStore)(nil).GetHoldByIdForUpdate), arg0, arg1)
+}
+
+// GetInterestAccruals mocks base method.
+func (m *MockStore) GetInterestAccruals(arg0 context.Context, arg1 db.GetInterestAccrualsParams) ([]db.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInterestAccruals", arg0, arg1)
+	ret0, _ := ret[0].([]db.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInterestAccruals indicates an expected call of GetInterestAccruals.
+func (mr *MockStoreMockRecorder) GetInterestAccruals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInterestAccruals", reflect.TypeOf((*MockStore)(nil).GetInterestAccruals), arg0, arg1)
+}
+
+// GetInterestPlanById mocks base method.
+func (m *MockStore) GetInterestPlanById(arg0 context.Context, arg1 int64) (db.InterestPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInterestPlanById", arg0, arg1)
+	ret0, _ := ret[0].(db.InterestPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInterestPlanById indicates an expected call of GetInterestPlanById.
+func (mr *MockStoreMockRecorder) GetInterestPlanById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInterestPlanById", reflect.TypeOf((*MockStore)(nil).GetInterestPlanById), arg0, arg1)
+}
+
+// GetInterestPlans mocks base method.
+func (m *MockStore) GetInterestPlans(arg0 context.Context, arg1 string) ([]db.InterestPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInterestPlans", arg0, arg1)
+	ret0, _ := ret[0].([]db.InterestPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInterestPlans indicates an expected call of GetInterestPlans.
+func (mr *MockStoreMockRecorder) GetInterestPlans(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInterestPlans", reflect.TypeOf((*MockStore)(nil).GetInterestPlans), arg0, arg1)
+}
+
+// GetLatestBalanceSnapshot mocks base method.
+func (m *MockStore) GetLatestBalanceSnapshot(arg0 context.Context, arg1 db.GetLatestBalanceSnapshotParams) (db.BalanceSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestBalanceSnapshot", arg0, arg1)
+	ret0, _ := ret[0].(db.BalanceSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestBalanceSnapshot indicates an expected call of GetLatestBalanceSnapshot.
+func (mr *MockStoreMockRecorder) GetLatestBalanceSnapshot(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestBalanceSnapshot", reflect.TypeOf((*MockStore)(nil).GetLatestBalanceSnapshot), arg0, arg1)
+}
+
+// GetOutboxEventById mocks base method.
+func (m *MockStore) GetOutboxEventById(arg0 context.Context, arg1 int64) (db.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxEventById", arg0, arg1)
+	ret0, _ := ret[0].(db.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxEventById indicates an expected call of GetOutboxEventById.
+func (mr *MockStoreMockRecorder) GetOutboxEventById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxEventById", reflect.TypeOf((*MockStore)(nil).GetOutboxEventById), arg0, arg1)
+}
+
+// GetOutboxEventsByAggregate mocks base method.
+func (m *MockStore) GetOutboxEventsByAggregate(arg0 context.Context, arg1 db.GetOutboxEventsByAggregateParams) ([]db.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutboxEventsByAggregate", arg0, arg1)
+	ret0, _ := ret[0].([]db.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutboxEventsByAggregate indicates an expected call of GetOutboxEventsByAggregate.
+func (mr *MockStoreMockRecorder) GetOutboxEventsByAggregate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboxEventsByAggregate", reflect.TypeOf((*MockStore)(nil).GetOutboxEventsByAggregate), arg0, arg1)
+}
+
+// GetOutgoingTransferStats mocks base method.
+func (m *MockStore) GetOutgoingTransferStats(arg0 context.Context, arg1 db.GetOutgoingTransferStatsParams) (db.GetOutgoingTransferStatsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutgoingTransferStats", arg0, arg1)
+	ret0, _ := ret[0].(db.GetOutgoingTransferStatsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutgoingTransferStats indicates an expected call of GetOutgoingTransferStats.
+func (mr *MockStoreMockRecorder) GetOutgoingTransferStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutgoingTransferStats", reflect.TypeOf((*MockStore)(nil).GetOutgoingTransferStats), arg0, arg1)
+}
+
+// GetPaymentFileById mocks base method.
+func (m *MockStore) GetPaymentFileById(arg0 context.Context, arg1 int64) (db.PaymentFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentFileById", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentFileById indicates an expected call of GetPaymentFileById.
+func (mr *MockStoreMockRecorder) GetPaymentFileById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentFileById", reflect.TypeOf((*MockStore)(nil).GetPaymentFileById), arg0, arg1)
+}
+
+// GetPaymentInstructionForUpdate mocks base method.
+func (m *MockStore) GetPaymentInstructionForUpdate(arg0 context.Context, arg1 int64) (db.PaymentInstruction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentInstructionForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentInstruction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentInstructionForUpdate indicates an expected call of GetPaymentInstructionForUpdate.
+func (mr *MockStoreMockRecorder) GetPaymentInstructionForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentInstructionForUpdate", reflect.TypeOf((*MockStore)(nil).GetPaymentInstructionForUpdate), arg0, arg1)
+}
+
+// GetPaymentInstructions mocks base method.
+func (m *MockStore) GetPaymentInstructions(arg0 context.Context, arg1 int64) ([]db.PaymentInstruction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentInstructions", arg0, arg1)
+	ret0, _ := ret[0].([]db.PaymentInstruction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentInstructions indicates an expected call of GetPaymentInstructions.
+func (mr *MockStoreMockRecorder) GetPaymentInstructions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentInstructions", reflect.TypeOf((*MockStore)(nil).GetPaymentInstructions), arg0, arg1)
+}
+
+// GetReversals mocks base method.
+func (m *MockStore) GetReversals(arg0 context.Context, arg1 int64) ([]db.Reversal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReversals", arg0, arg1)
+	ret0, _ := ret[0].([]db.Reversal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReversals indicates an expected call of GetReversals.
+func (mr *MockStoreMockRecorder) GetReversals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReversals", reflect.TypeOf((*MockStore)(nil).GetReversals), arg0, arg1)
+}
+
+// GetReversedAmount mocks base method.
+func (m *MockStore) GetReversedAmount(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReversedAmount", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReversedAmount indicates an expected call of GetReversedAmount.
+func (mr *MockStoreMockRecorder) GetReversedAmount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReversedAmount", reflect.TypeOf((*MockStore)(nil).GetReversedAmount), arg0, arg1)
+}
+
+// GetSchemaMigration mocks base method.
+func (m *MockStore) GetSchemaMigration(arg0 context.Context) (db.SchemaMigration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchemaMigration", arg0)
+	ret0, _ := ret[0].(db.SchemaMigration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchemaMigration indicates an expected call of GetSchemaMigration.
+func (mr *MockStoreMockRecorder) GetSchemaMigration(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchemaMigration", reflect.TypeOf((*MockStore)(nil).GetSchemaMigration), arg0)
+}
+
 // GetSessionById mocks base method.
 func (m *MockStore) GetSessionById(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
 	m.ctrl.T.Helper()
@@ -230,6 +1024,36 @@ func (mr *MockStoreMockRecorder) GetSessionById(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionById", reflect.TypeOf((*MockStore)(nil).GetSessionById), arg0, arg1)
 }
 
+// GetSystemAccount mocks base method.
+func (m *MockStore) GetSystemAccount(arg0 context.Context, arg1 db.GetSystemAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSystemAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSystemAccount indicates an expected call of GetSystemAccount.
+func (mr *MockStoreMockRecorder) GetSystemAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemAccount", reflect.TypeOf((*MockStore)(nil).GetSystemAccount), arg0, arg1)
+}
+
+// GetSystemAccounts mocks base method.
+func (m *MockStore) GetSystemAccounts(arg0 context.Context) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSystemAccounts", arg0)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSystemAccounts indicates an expected call of GetSystemAccounts.
+func (mr *MockStoreMockRecorder) GetSystemAccounts(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemAccounts", reflect.TypeOf((*MockStore)(nil).GetSystemAccounts), arg0)
+}
+
 // GetTransferById mocks base method.
 func (m *MockStore) GetTransferById(arg0 context.Context, arg1 int64) (db.Transfer, error) {
 	m.ctrl.T.Helper()
@@ -245,6 +1069,36 @@ func (mr *MockStoreMockRecorder) GetTransferById(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferById", reflect.TypeOf((*MockStore)(nil).GetTransferById), arg0, arg1)
 }
 
+// GetTransferByIdForUpdate mocks base method.
+func (m *MockStore) GetTransferByIdForUpdate(arg0 context.Context, arg1 int64) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferByIdForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferByIdForUpdate indicates an expected call of GetTransferByIdForUpdate.
+func (mr *MockStoreMockRecorder) GetTransferByIdForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferByIdForUpdate", reflect.TypeOf((*MockStore)(nil).GetTransferByIdForUpdate), arg0, arg1)
+}
+
+// GetTransferLimitOverrides mocks base method.
+func (m *MockStore) GetTransferLimitOverrides(arg0 context.Context, arg1 db.GetTransferLimitOverridesParams) ([]db.TransferLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferLimitOverrides", arg0, arg1)
+	ret0, _ := ret[0].([]db.TransferLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferLimitOverrides indicates an expected call of GetTransferLimitOverrides.
+func (mr *MockStoreMockRecorder) GetTransferLimitOverrides(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferLimitOverrides", reflect.TypeOf((*MockStore)(nil).GetTransferLimitOverrides), arg0, arg1)
+}
+
 // GetTransfers mocks base method.
 func (m *MockStore) GetTransfers(arg0 context.Context, arg1 db.GetTransfersParams) ([]db.Transfer, error) {
 	m.ctrl.T.Helper()
@@ -260,6 +1114,51 @@ func (mr *MockStoreMockRecorder) GetTransfers(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfers", reflect.TypeOf((*MockStore)(nil).GetTransfers), arg0, arg1)
 }
 
+// GetUnpostedAccrualsForUpdate mocks base method.
+func (m *MockStore) GetUnpostedAccrualsForUpdate(arg0 context.Context, arg1 db.GetUnpostedAccrualsForUpdateParams) ([]db.InterestAccrual, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnpostedAccrualsForUpdate", arg0, arg1)
+	ret0, _ := ret[0].([]db.InterestAccrual)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnpostedAccrualsForUpdate indicates an expected call of GetUnpostedAccrualsForUpdate.
+func (mr *MockStoreMockRecorder) GetUnpostedAccrualsForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnpostedAccrualsForUpdate", reflect.TypeOf((*MockStore)(nil).GetUnpostedAccrualsForUpdate), arg0, arg1)
+}
+
+// GetUnpostedInterest mocks base method.
+func (m *MockStore) GetUnpostedInterest(arg0 context.Context, arg1 time.Time) ([]db.GetUnpostedInterestRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnpostedInterest", arg0, arg1)
+	ret0, _ := ret[0].([]db.GetUnpostedInterestRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnpostedInterest indicates an expected call of GetUnpostedInterest.
+func (mr *MockStoreMockRecorder) GetUnpostedInterest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnpostedInterest", reflect.TypeOf((*MockStore)(nil).GetUnpostedInterest), arg0, arg1)
+}
+
+// GetUnpublishedOutboxEvents mocks base method.
+func (m *MockStore) GetUnpublishedOutboxEvents(arg0 context.Context, arg1 int32) ([]db.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnpublishedOutboxEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnpublishedOutboxEvents indicates an expected call of GetUnpublishedOutboxEvents.
+func (mr *MockStoreMockRecorder) GetUnpublishedOutboxEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnpublishedOutboxEvents", reflect.TypeOf((*MockStore)(nil).GetUnpublishedOutboxEvents), arg0, arg1)
+}
+
 // GetUserByUsername mocks base method.
 func (m *MockStore) GetUserByUsername(arg0 context.Context, arg1 string) (db.User, error) {
 	m.ctrl.T.Helper()
@@ -275,6 +1174,361 @@ func (mr *MockStoreMockRecorder) GetUserByUsername(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockStore)(nil).GetUserByUsername), arg0, arg1)
 }
 
+// GetWebhookDeliveries mocks base method.
+func (m *MockStore) GetWebhookDeliveries(arg0 context.Context, arg1 db.GetWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", arg0, arg1)
+	ret0, _ := ret[0].([]db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockStoreMockRecorder) GetWebhookDeliveries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).GetWebhookDeliveries), arg0, arg1)
+}
+
+// GetWebhookDeliveryById mocks base method.
+func (m *MockStore) GetWebhookDeliveryById(arg0 context.Context, arg1 int64) (db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveryById", arg0, arg1)
+	ret0, _ := ret[0].(db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveryById indicates an expected call of GetWebhookDeliveryById.
+func (mr *MockStoreMockRecorder) GetWebhookDeliveryById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveryById", reflect.TypeOf((*MockStore)(nil).GetWebhookDeliveryById), arg0, arg1)
+}
+
+// GetWebhookSubscriptionById mocks base method.
+func (m *MockStore) GetWebhookSubscriptionById(arg0 context.Context, arg1 int64) (db.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscriptionById", arg0, arg1)
+	ret0, _ := ret[0].(db.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscriptionById indicates an expected call of GetWebhookSubscriptionById.
+func (mr *MockStoreMockRecorder) GetWebhookSubscriptionById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscriptionById", reflect.TypeOf((*MockStore)(nil).GetWebhookSubscriptionById), arg0, arg1)
+}
+
+// GetWebhookSubscriptions mocks base method.
+func (m *MockStore) GetWebhookSubscriptions(arg0 context.Context, arg1 string) ([]db.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSubscriptions", arg0, arg1)
+	ret0, _ := ret[0].([]db.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSubscriptions indicates an expected call of GetWebhookSubscriptions.
+func (mr *MockStoreMockRecorder) GetWebhookSubscriptions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSubscriptions", reflect.TypeOf((*MockStore)(nil).GetWebhookSubscriptions), arg0, arg1)
+}
+
+// HoldTransaction mocks base method.
+func (m *MockStore) HoldTransaction(arg0 context.Context, arg1 db.HoldTxParams) (db.HoldTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HoldTransaction", arg0, arg1)
+	ret0, _ := ret[0].(db.HoldTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HoldTransaction indicates an expected call of HoldTransaction.
+func (mr *MockStoreMockRecorder) HoldTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HoldTransaction", reflect.TypeOf((*MockStore)(nil).HoldTransaction), arg0, arg1)
+}
+
+// MarkAccrualsPosted mocks base method.
+func (m *MockStore) MarkAccrualsPosted(arg0 context.Context, arg1 db.MarkAccrualsPostedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAccrualsPosted", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAccrualsPosted indicates an expected call of MarkAccrualsPosted.
+func (mr *MockStoreMockRecorder) MarkAccrualsPosted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAccrualsPosted", reflect.TypeOf((*MockStore)(nil).MarkAccrualsPosted), arg0, arg1)
+}
+
+// MarkOutboxEventFailed mocks base method.
+func (m *MockStore) MarkOutboxEventFailed(arg0 context.Context, arg1 db.MarkOutboxEventFailedParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventFailed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventFailed indicates an expected call of MarkOutboxEventFailed.
+func (mr *MockStoreMockRecorder) MarkOutboxEventFailed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventFailed", reflect.TypeOf((*MockStore)(nil).MarkOutboxEventFailed), arg0, arg1)
+}
+
+// MarkOutboxEventPublished mocks base method.
+func (m *MockStore) MarkOutboxEventPublished(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventPublished", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventPublished indicates an expected call of MarkOutboxEventPublished.
+func (mr *MockStoreMockRecorder) MarkOutboxEventPublished(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventPublished", reflect.TypeOf((*MockStore)(nil).MarkOutboxEventPublished), arg0, arg1)
+}
+
+// NotifyAccountActivity mocks base method.
+func (m *MockStore) NotifyAccountActivity(arg0 context.Context, arg1 db.NotifyAccountActivityParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyAccountActivity", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyAccountActivity indicates an expected call of NotifyAccountActivity.
+func (mr *MockStoreMockRecorder) NotifyAccountActivity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyAccountActivity", reflect.TypeOf((*MockStore)(nil).NotifyAccountActivity), arg0, arg1)
+}
+
+// Ping mocks base method.
+func (m *MockStore) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockStoreMockRecorder) Ping(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStore)(nil).Ping), arg0)
+}
+
+// PostInterestTransaction mocks base method.
+func (m *MockStore) PostInterestTransaction(arg0 context.Context, arg1 db.PostInterestTxParams) (db.PostInterestTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostInterestTransaction", arg0, arg1)
+	ret0, _ := ret[0].(db.PostInterestTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostInterestTransaction indicates an expected call of PostInterestTransaction.
+func (mr *MockStoreMockRecorder) PostInterestTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostInterestTransaction", reflect.TypeOf((*MockStore)(nil).PostInterestTransaction), arg0, arg1)
+}
+
+// PreviewFee mocks base method.
+func (m *MockStore) PreviewFee(arg0 context.Context, arg1 string, arg2 int64) (db.FeeQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewFee", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.FeeQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewFee indicates an expected call of PreviewFee.
+func (mr *MockStoreMockRecorder) PreviewFee(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewFee", reflect.TypeOf((*MockStore)(nil).PreviewFee), arg0, arg1, arg2)
+}
+
+// RelayOutboxTransaction mocks base method.
+func (m *MockStore) RelayOutboxTransaction(arg0 context.Context, arg1 int32, arg2 func(db.OutboxEvent) error) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelayOutboxTransaction", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RelayOutboxTransaction indicates an expected call of RelayOutboxTransaction.
+func (mr *MockStoreMockRecorder) RelayOutboxTransaction(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelayOutboxTransaction", reflect.TypeOf((*MockStore)(nil).RelayOutboxTransaction), arg0, arg1, arg2)
+}
+
+// ReplayWebhookDelivery mocks base method.
+func (m *MockStore) ReplayWebhookDelivery(arg0 context.Context, arg1 int64) (db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplayWebhookDelivery indicates an expected call of ReplayWebhookDelivery.
+func (mr *MockStoreMockRecorder) ReplayWebhookDelivery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayWebhookDelivery", reflect.TypeOf((*MockStore)(nil).ReplayWebhookDelivery), arg0, arg1)
+}
+
+// ReverseTransferTransaction mocks base method.
+func (m *MockStore) ReverseTransferTransaction(arg0 context.Context, arg1 db.ReverseTransferTxParams) (db.ReverseTransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReverseTransferTransaction", arg0, arg1)
+	ret0, _ := ret[0].(db.ReverseTransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransferTransaction indicates an expected call of ReverseTransferTransaction.
+func (mr *MockStoreMockRecorder) ReverseTransferTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransferTransaction", reflect.TypeOf((*MockStore)(nil).ReverseTransferTransaction), arg0, arg1)
+}
+
+// SearchEntries mocks base method.
+func (m *MockStore) SearchEntries(arg0 context.Context, arg1 db.SearchEntriesParams) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchEntries indicates an expected call of SearchEntries.
+func (mr *MockStoreMockRecorder) SearchEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchEntries", reflect.TypeOf((*MockStore)(nil).SearchEntries), arg0, arg1)
+}
+
+// SearchTransfers mocks base method.
+func (m *MockStore) SearchTransfers(arg0 context.Context, arg1 db.SearchTransfersParams) ([]db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchTransfers indicates an expected call of SearchTransfers.
+func (mr *MockStoreMockRecorder) SearchTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchTransfers", reflect.TypeOf((*MockStore)(nil).SearchTransfers), arg0, arg1)
+}
+
+// SetAccountInterestPlan mocks base method.
+func (m *MockStore) SetAccountInterestPlan(arg0 context.Context, arg1 db.SetAccountInterestPlanParams) (db.AccountInterestPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccountInterestPlan", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountInterestPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetAccountInterestPlan indicates an expected call of SetAccountInterestPlan.
+func (mr *MockStoreMockRecorder) SetAccountInterestPlan(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountInterestPlan", reflect.TypeOf((*MockStore)(nil).SetAccountInterestPlan), arg0, arg1)
+}
+
+// SetAccountTransferLimit mocks base method.
+func (m *MockStore) SetAccountTransferLimit(arg0 context.Context, arg1 db.SetAccountTransferLimitParams) (db.TransferLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccountTransferLimit", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetAccountTransferLimit indicates an expected call of SetAccountTransferLimit.
+func (mr *MockStoreMockRecorder) SetAccountTransferLimit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountTransferLimit", reflect.TypeOf((*MockStore)(nil).SetAccountTransferLimit), arg0, arg1)
+}
+
+// SetUserTransferLimit mocks base method.
+func (m *MockStore) SetUserTransferLimit(arg0 context.Context, arg1 db.SetUserTransferLimitParams) (db.TransferLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserTransferLimit", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserTransferLimit indicates an expected call of SetUserTransferLimit.
+func (mr *MockStoreMockRecorder) SetUserTransferLimit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserTransferLimit", reflect.TypeOf((*MockStore)(nil).SetUserTransferLimit), arg0, arg1)
+}
+
+// SnapshotBalances mocks base method.
+func (m *MockStore) SnapshotBalances(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SnapshotBalances", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SnapshotBalances indicates an expected call of SnapshotBalances.
+func (mr *MockStoreMockRecorder) SnapshotBalances(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnapshotBalances", reflect.TypeOf((*MockStore)(nil).SnapshotBalances), arg0, arg1)
+}
+
+// SumEntriesAfter mocks base method.
+func (m *MockStore) SumEntriesAfter(arg0 context.Context, arg1 db.SumEntriesAfterParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumEntriesAfter", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumEntriesAfter indicates an expected call of SumEntriesAfter.
+func (mr *MockStoreMockRecorder) SumEntriesAfter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumEntriesAfter", reflect.TypeOf((*MockStore)(nil).SumEntriesAfter), arg0, arg1)
+}
+
+// SumEntriesBetween mocks base method.
+func (m *MockStore) SumEntriesBetween(arg0 context.Context, arg1 db.SumEntriesBetweenParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumEntriesBetween", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumEntriesBetween indicates an expected call of SumEntriesBetween.
+func (mr *MockStoreMockRecorder) SumEntriesBetween(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumEntriesBetween", reflect.TypeOf((*MockStore)(nil).SumEntriesBetween), arg0, arg1)
+}
+
+// TransferAllowance mocks base method.
+func (m *MockStore) TransferAllowance(arg0 context.Context, arg1 db.Account, arg2 db.TransferLimits) (db.TransferAllowance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferAllowance", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.TransferAllowance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferAllowance indicates an expected call of TransferAllowance.
+func (mr *MockStoreMockRecorder) TransferAllowance(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferAllowance", reflect.TypeOf((*MockStore)(nil).TransferAllowance), arg0, arg1, arg2)
+}
+
 // TransferTransaction mocks base method.
 func (m *MockStore) TransferTransaction(arg0 context.Context, arg1 db.TransferTxParams) (db.TransferTxResult, error) {
 	m.ctrl.T.Helper()
@@ -290,17 +1544,92 @@ func (mr *MockStoreMockRecorder) TransferTransaction(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferTransaction", reflect.TypeOf((*MockStore)(nil).TransferTransaction), arg0, arg1)
 }
 
-// UpdateAccount mocks base method.
-func (m *MockStore) UpdateAccount(arg0 context.Context, arg1 db.UpdateAccountParams) (db.Account, error) {
+// UpdateHoldStatus mocks base method.
+func (m *MockStore) UpdateHoldStatus(arg0 context.Context, arg1 db.UpdateHoldStatusParams) (db.Hold, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAccount", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "UpdateHoldStatus", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateHoldStatus indicates an expected call of UpdateHoldStatus.
+func (mr *MockStoreMockRecorder) UpdateHoldStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHoldStatus", reflect.TypeOf((*MockStore)(nil).UpdateHoldStatus), arg0, arg1)
+}
+
+// UpdatePaymentFileStatus mocks base method.
+func (m *MockStore) UpdatePaymentFileStatus(arg0 context.Context, arg1 db.UpdatePaymentFileStatusParams) (db.PaymentFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentFileStatus", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePaymentFileStatus indicates an expected call of UpdatePaymentFileStatus.
+func (mr *MockStoreMockRecorder) UpdatePaymentFileStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentFileStatus", reflect.TypeOf((*MockStore)(nil).UpdatePaymentFileStatus), arg0, arg1)
+}
+
+// UpdatePaymentInstruction mocks base method.
+func (m *MockStore) UpdatePaymentInstruction(arg0 context.Context, arg1 db.UpdatePaymentInstructionParams) (db.PaymentInstruction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentInstruction", arg0, arg1)
+	ret0, _ := ret[0].(db.PaymentInstruction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePaymentInstruction indicates an expected call of UpdatePaymentInstruction.
+func (mr *MockStoreMockRecorder) UpdatePaymentInstruction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentInstruction", reflect.TypeOf((*MockStore)(nil).UpdatePaymentInstruction), arg0, arg1)
+}
+
+// UpdateUserRole mocks base method.
+func (m *MockStore) UpdateUserRole(arg0 context.Context, arg1 db.UpdateUserRoleParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRole", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockStoreMockRecorder) UpdateUserRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockStore)(nil).UpdateUserRole), arg0, arg1)
+}
+
+// UpdateWebhookDeliveryAttempt mocks base method.
+func (m *MockStore) UpdateWebhookDeliveryAttempt(arg0 context.Context, arg1 db.UpdateWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhookDeliveryAttempt", arg0, arg1)
+	ret0, _ := ret[0].(db.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWebhookDeliveryAttempt indicates an expected call of UpdateWebhookDeliveryAttempt.
+func (mr *MockStoreMockRecorder) UpdateWebhookDeliveryAttempt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhookDeliveryAttempt", reflect.TypeOf((*MockStore)(nil).UpdateWebhookDeliveryAttempt), arg0, arg1)
+}
+
+// VoidHoldTransaction mocks base method.
+func (m *MockStore) VoidHoldTransaction(arg0 context.Context, arg1 int64) (db.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidHoldTransaction", arg0, arg1)
+	ret0, _ := ret[0].(db.Hold)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateAccount indicates an expected call of UpdateAccount.
-func (mr *MockStoreMockRecorder) UpdateAccount(arg0, arg1 interface{}) *gomock.Call {
+// VoidHoldTransaction indicates an expected call of VoidHoldTransaction.
+func (mr *MockStoreMockRecorder) VoidHoldTransaction(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccount", reflect.TypeOf((*MockStore)(nil).UpdateAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidHoldTransaction", reflect.TypeOf((*MockStore)(nil).VoidHoldTransaction), arg0, arg1)
 }
@@ -1,8 +1,8 @@
 -- name: CreateAccount :one
 INSERT INTO accounts (
-  owner_name, balance, currency 
+  owner_name, balance, available_balance, currency 
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $2, $3
 )
 RETURNING *;
 
@@ -22,18 +22,37 @@ ORDER BY id
 LIMIT $2
 OFFSET $3;
 
--- name: UpdateAccount :one
+-- name: AddAccountBalance :one
 UPDATE accounts 
-SET balance = $2
-WHERE id = $1
+SET balance = balance + sqlc.arg(amount),
+    available_balance = available_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
 RETURNING *;
 
--- name: AddAccountBalance :one
+-- name: AddAccountAvailableBalance :one
 UPDATE accounts 
-SET balance = balance + sqlc.arg(amount)
+SET available_balance = available_balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
 -- name: DeleteAccount :exec
 DELETE FROM accounts
 WHERE id = $1;
+
+-- name: GetSystemAccount :one
+SELECT * FROM accounts
+WHERE is_system AND chart_code = $1 AND currency = $2
+LIMIT 1;
+
+-- name: GetSystemAccounts :many
+SELECT * FROM accounts
+WHERE is_system
+ORDER BY chart_code, currency;
+
+-- name: CreateSystemAccount :one
+INSERT INTO accounts (
+  owner_name, balance, available_balance, currency, chart_code, is_system
+) VALUES (
+  '_bank', 0, 0, $1, $2, true
+)
+RETURNING *;
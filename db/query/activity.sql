@@ -0,0 +1,3 @@
+-- name: NotifyAccountActivity :exec
+-- delivered to the listeners when the transaction commits, dropped if it rolls back
+SELECT pg_notify(sqlc.arg(channel)::text, sqlc.arg(payload)::text);
@@ -0,0 +1,28 @@
+-- name: CreateBalanceSnapshots :execrows
+-- the entries created after taken_at are taken out of the current balance, the accounts already snapshotted are skipped
+INSERT INTO balance_snapshots (
+  account_id, taken_at, balance
+)
+SELECT
+  a.id, sqlc.arg(taken_at), a.balance - (
+    SELECT COALESCE(SUM(e.amount), 0)::bigint FROM entries e
+    WHERE e.account_id = a.id AND e.created_at > sqlc.arg(taken_at)
+  )
+FROM accounts a
+WHERE a.created_at <= sqlc.arg(taken_at)
+ON CONFLICT (account_id, taken_at) DO NOTHING;
+
+-- name: GetLatestBalanceSnapshot :one
+SELECT * FROM balance_snapshots
+WHERE account_id = $1 AND taken_at <= sqlc.arg(at)
+ORDER BY taken_at DESC
+LIMIT 1;
+
+-- name: SumEntriesBetween :one
+-- from is exclusive, to is inclusive
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1 AND created_at > sqlc.arg('from') AND created_at <= sqlc.arg('to');
+
+-- name: SumEntriesAfter :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1 AND created_at > sqlc.arg(after);
@@ -0,0 +1,11 @@
+-- name: CreateCashReceipt :one
+INSERT INTO cash_receipts (
+  kind, account_id, amount, currency, teller, transfer_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetCashReceiptById :one
+SELECT * FROM cash_receipts
+WHERE id = $1 LIMIT 1;
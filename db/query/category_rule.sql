@@ -0,0 +1,16 @@
+-- name: CreateCategoryRule :one
+INSERT INTO category_rules (
+  username, pattern, category
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetCategoryRules :many
+SELECT * FROM category_rules
+WHERE username = $1
+ORDER BY id;
+
+-- name: DeleteCategoryRule :execrows
+DELETE FROM category_rules
+WHERE id = $1 AND username = $2;
@@ -0,0 +1,3 @@
+-- name: GetChartOfAccounts :many
+SELECT * FROM chart_of_accounts
+ORDER BY code;
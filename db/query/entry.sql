@@ -1,8 +1,8 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  account_id, amount, description, reference, category
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4, $5
 )
 RETURNING *;
 
@@ -17,3 +17,20 @@ WHERE account_id = $1
 ORDER BY id
 LIMIT $2
 OFFSET $3;
+
+-- name: SearchEntries :many
+-- the empty query/category match everything, the wildcards of the query are matched as they are
+SELECT * FROM entries
+WHERE 
+    account_id = sqlc.arg(account_id) AND
+    (sqlc.arg(query)::varchar = '' OR description ILIKE '%' || replace(replace(replace(sqlc.arg(query), '\', '\\'), '%', '\%'), '_', '\_') || '%' OR reference = sqlc.arg(query)) AND
+    (sqlc.arg(category)::varchar = '' OR category = sqlc.arg(category))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetEntriesBetween :many
+-- from is exclusive and to is inclusive, same as the balances at those times
+SELECT * FROM entries
+WHERE account_id = $1 AND created_at > sqlc.arg('from') AND created_at <= sqlc.arg('to')
+ORDER BY created_at, id;
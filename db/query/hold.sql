@@ -0,0 +1,29 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+  from_account_id, to_account_id, amount, expired_at, fee, fee_account_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetHoldById :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldByIdForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: GetExpiredHoldsForUpdate :many
+SELECT * FROM holds
+WHERE status = 'pending' AND expired_at <= now()
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: UpdateHoldStatus :one
+UPDATE holds
+SET status = $2, transfer_id = $3
+WHERE id = $1
+RETURNING *;
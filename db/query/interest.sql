@@ -0,0 +1,75 @@
+-- name: CreateInterestPlan :one
+INSERT INTO interest_plans (
+  name, currency, annual_rate_bps, expense_account_id
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetInterestPlanById :one
+SELECT * FROM interest_plans
+WHERE id = $1 LIMIT 1;
+
+-- name: GetInterestPlans :many
+SELECT * FROM interest_plans
+WHERE currency = $1
+ORDER BY id;
+
+-- name: SetAccountInterestPlan :one
+INSERT INTO account_interest_plans (
+  account_id, plan_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (account_id) DO UPDATE
+SET plan_id = EXCLUDED.plan_id
+RETURNING *;
+
+-- name: AccrueInterest :execrows
+-- the end of day balance is the current one minus whatever was booked after the day ended,
+-- the accounts already accrued for the day are skipped, so are the ones which got their plan after the day ended.
+WITH eod AS (
+  SELECT
+    a.id AS account_id,
+    p.id AS plan_id,
+    p.annual_rate_bps,
+    a.balance - (
+      SELECT COALESCE(SUM(e.amount), 0)::bigint FROM entries e
+      WHERE e.account_id = a.id AND e.created_at >= sqlc.arg(end_of_day)
+    ) AS balance
+  FROM accounts a
+  JOIN account_interest_plans ap ON ap.account_id = a.id
+  JOIN interest_plans p ON p.id = ap.plan_id
+  WHERE a.created_at < sqlc.arg(end_of_day) AND ap.created_at < sqlc.arg(end_of_day)
+)
+INSERT INTO interest_accruals (
+  account_id, plan_id, date, balance, amount
+)
+SELECT
+  account_id, plan_id, sqlc.arg(date)::date, balance,
+  GREATEST(balance, 0) * annual_rate_bps * 100 / 365
+FROM eod
+ON CONFLICT (account_id, date) DO NOTHING;
+
+-- name: GetInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+ORDER BY date DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: GetUnpostedInterest :many
+SELECT account_id, plan_id FROM interest_accruals
+WHERE posted_at IS NULL AND date < sqlc.arg(before)::date
+GROUP BY account_id, plan_id;
+
+-- name: GetUnpostedAccrualsForUpdate :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1 AND plan_id = $2 AND posted_at IS NULL AND date < sqlc.arg(before)::date
+ORDER BY date
+FOR UPDATE;
+
+-- name: MarkAccrualsPosted :exec
+UPDATE interest_accruals
+SET posted_at = now(), transfer_id = sqlc.arg(transfer_id)
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
@@ -0,0 +1,34 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox_events (
+  event_type, aggregate_type, aggregate_id, payload
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetOutboxEventById :one
+SELECT * FROM outbox_events
+WHERE id = $1 LIMIT 1;
+
+-- name: GetUnpublishedOutboxEvents :many
+-- locked until the relay's transaction is done, another relay skips them instead of publishing them twice
+SELECT * FROM outbox_events
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events
+SET published_at = now(), attempts = attempts + 1, last_error = ''
+WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events
+SET attempts = attempts + 1, last_error = $2
+WHERE id = $1;
+
+-- name: GetOutboxEventsByAggregate :many
+SELECT * FROM outbox_events
+WHERE aggregate_type = $1 AND aggregate_id = $2
+ORDER BY id;
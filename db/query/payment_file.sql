@@ -0,0 +1,42 @@
+-- name: CreatePaymentFile :one
+INSERT INTO payment_files (
+  owner_name, format, message_id
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetPaymentFileById :one
+SELECT * FROM payment_files
+WHERE id = $1 LIMIT 1;
+
+-- name: UpdatePaymentFileStatus :one
+UPDATE payment_files
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: CreatePaymentInstruction :one
+INSERT INTO payment_instructions (
+  file_id, end_to_end_id, from_account_id, to_account_id, amount, currency, status, error
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: GetPaymentInstructions :many
+SELECT * FROM payment_instructions
+WHERE file_id = $1
+ORDER BY id;
+
+-- name: GetPaymentInstructionForUpdate :one
+SELECT * FROM payment_instructions
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: UpdatePaymentInstruction :one
+-- only a valid instruction is executed, once
+UPDATE payment_instructions
+SET status = $2, error = $3, transfer_id = $4
+WHERE id = $1 AND status = 'valid'
+RETURNING *;
@@ -0,0 +1,16 @@
+-- name: CreateReversal :one
+INSERT INTO reversals (
+  transfer_id, reversal_transfer_id, amount, reason, actor
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetReversals :many
+SELECT * FROM reversals
+WHERE transfer_id = $1
+ORDER BY id;
+
+-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM reversals
+WHERE transfer_id = $1;
@@ -1,8 +1,8 @@
 -- name: CreateTransfer :one
 INSERT INTO transfers (
-  from_account_id, to_account_id, amount 
+  from_account_id, to_account_id, amount, description, reference, category, fee
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7
 )
 RETURNING *;
 
@@ -19,3 +19,20 @@ WHERE
 ORDER BY id
 LIMIT $3
 OFFSET $4;
+
+-- name: SearchTransfers :many
+-- the empty query/category match everything, the wildcards of the query are matched as they are
+SELECT * FROM transfers
+WHERE 
+    (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id)) AND
+    (sqlc.arg(query)::varchar = '' OR description ILIKE '%' || replace(replace(replace(sqlc.arg(query), '\', '\\'), '%', '\%'), '_', '\_') || '%' OR reference = sqlc.arg(query)) AND
+    (sqlc.arg(category)::varchar = '' OR category = sqlc.arg(category))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: GetTransferByIdForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1
+LIMIT 1
+FOR UPDATE;
@@ -0,0 +1,42 @@
+-- name: GetTransferLimitOverrides :many
+-- the user's override comes first, so the account's one is applied on top of it
+SELECT * FROM transfer_limits
+WHERE username = sqlc.arg(username) OR account_id = sqlc.arg(account_id)
+ORDER BY account_id NULLS FIRST;
+
+-- name: SetUserTransferLimit :one
+INSERT INTO transfer_limits (
+  username, max_amount, daily_amount, hourly_count
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (username) DO UPDATE
+SET max_amount = EXCLUDED.max_amount,
+    daily_amount = EXCLUDED.daily_amount,
+    hourly_count = EXCLUDED.hourly_count
+RETURNING *;
+
+-- name: SetAccountTransferLimit :one
+INSERT INTO transfer_limits (
+  account_id, max_amount, daily_amount, hourly_count
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (account_id) DO UPDATE
+SET max_amount = EXCLUDED.max_amount,
+    daily_amount = EXCLUDED.daily_amount,
+    hourly_count = EXCLUDED.hourly_count
+RETURNING *;
+
+-- name: GetOutgoingTransferStats :one
+-- the pending holds are counted too, they become transfers once captured
+SELECT
+  COALESCE(SUM(amount), 0)::bigint AS total,
+  COUNT(*) AS count
+FROM (
+  SELECT t.amount FROM transfers t
+  WHERE t.from_account_id = sqlc.arg(account_id) AND t.created_at >= sqlc.arg(since)
+  UNION ALL
+  SELECT h.amount FROM holds h
+  WHERE h.from_account_id = sqlc.arg(account_id) AND h.status = 'pending' AND h.created_at >= sqlc.arg(since)
+) AS outgoing;
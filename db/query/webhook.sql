@@ -0,0 +1,79 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+  owner_name, url, events, secret
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetWebhookSubscriptionById :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: GetWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE owner_name = $1
+ORDER BY id;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1 AND owner_name = $2;
+
+-- name: GetAccountsWebhookSubscriptions :many
+-- the subscriptions to the event of the owners of the accounts
+SELECT DISTINCT s.* FROM webhook_subscriptions s
+JOIN accounts a ON a.owner_name = s.owner_name
+WHERE a.id = ANY(sqlc.arg(account_ids)::bigint[])
+  AND sqlc.arg(event_type)::varchar = ANY(s.events)
+ORDER BY s.id;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (
+  subscription_id, event_id, event_type, payload
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: GetWebhookDeliveryById :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 LIMIT 1;
+
+-- name: GetWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ClaimWebhookDeliveries :many
+-- leases the due deliveries by pushing their next attempt back, another dispatcher won't pick them
+-- until the lease is over, even if this one dies in the middle.
+UPDATE webhook_deliveries
+SET next_attempt_at = now() + sqlc.arg(lease_seconds)::int * interval '1 second'
+WHERE id IN (
+  SELECT id FROM webhook_deliveries
+  WHERE status = 'pending' AND next_attempt_at <= now()
+  ORDER BY next_attempt_at
+  LIMIT sqlc.arg(limit_count)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateWebhookDeliveryAttempt :one
+UPDATE webhook_deliveries
+SET
+  status = $2,
+  attempts = attempts + 1,
+  next_attempt_at = $3,
+  last_status_code = $4,
+  last_error = $5,
+  delivered_at = sqlc.narg(delivered_at)
+WHERE id = $1
+RETURNING *;
+
+-- name: ReplayWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = now()
+WHERE id = $1
+RETURNING *;
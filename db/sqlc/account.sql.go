@@ -9,11 +9,40 @@ import (
 	"context"
 )
 
+const addAccountAvailableBalance = `-- name: AddAccountAvailableBalance :one
+UPDATE accounts 
+SET available_balance = available_balance + $1
+WHERE id = $2
+RETURNING id, owner_name, balance, currency, created_at, available_balance, chart_code, is_system
+`
+
+type AddAccountAvailableBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountAvailableBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerName,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.ChartCode,
+		&i.IsSystem,
+	)
+	return i, err
+}
+
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts 
-SET balance = balance + $1
+SET balance = balance + $1,
+    available_balance = available_balance + $1
 WHERE id = $2
-RETURNING id, owner_name, balance, currency, created_at
+RETURNING id, owner_name, balance, currency, created_at, available_balance, chart_code, is_system
 `
 
 type AddAccountBalanceParams struct {
@@ -30,17 +59,20 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.ChartCode,
+		&i.IsSystem,
 	)
 	return i, err
 }
 
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
-  owner_name, balance, currency 
+  owner_name, balance, available_balance, currency 
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $2, $3
 )
-RETURNING id, owner_name, balance, currency, created_at
+RETURNING id, owner_name, balance, currency, created_at, available_balance, chart_code, is_system
 `
 
 type CreateAccountParams struct {
@@ -58,6 +90,39 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.ChartCode,
+		&i.IsSystem,
+	)
+	return i, err
+}
+
+const createSystemAccount = `-- name: CreateSystemAccount :one
+INSERT INTO accounts (
+  owner_name, balance, available_balance, currency, chart_code, is_system
+) VALUES (
+  '_bank', 0, 0, $1, $2, true
+)
+RETURNING id, owner_name, balance, currency, created_at, available_balance, chart_code, is_system
+`
+
+type CreateSystemAccountParams struct {
+	Currency  string `json:"currency"`
+	ChartCode string `json:"chart_code"`
+}
+
+func (q *Queries) CreateSystemAccount(ctx context.Context, arg CreateSystemAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, createSystemAccount, arg.Currency, arg.ChartCode)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerName,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.ChartCode,
+		&i.IsSystem,
 	)
 	return i, err
 }
@@ -73,7 +138,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccountById = `-- name: GetAccountById :one
-SELECT id, owner_name, balance, currency, created_at FROM accounts 
+SELECT id, owner_name, balance, currency, created_at, available_balance, chart_code, is_system FROM accounts 
 WHERE id = $1 LIMIT 1
 `
 
@@ -86,12 +151,15 @@ func (q *Queries) GetAccountById(ctx context.Context, id int64) (Account, error)
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.ChartCode,
+		&i.IsSystem,
 	)
 	return i, err
 }
 
 const getAccountByIdForUpdate = `-- name: GetAccountByIdForUpdate :one
-SELECT id, owner_name, balance, currency, created_at FROM accounts 
+SELECT id, owner_name, balance, currency, created_at, available_balance, chart_code, is_system FROM accounts 
 WHERE id = $1 LIMIT 1 
 FOR NO KEY UPDATE
 `
@@ -105,12 +173,15 @@ func (q *Queries) GetAccountByIdForUpdate(ctx context.Context, id int64) (Accoun
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.ChartCode,
+		&i.IsSystem,
 	)
 	return i, err
 }
 
 const getAccounts = `-- name: GetAccounts :many
-SELECT id, owner_name, balance, currency, created_at FROM accounts 
+SELECT id, owner_name, balance, currency, created_at, available_balance, chart_code, is_system FROM accounts 
 WHERE owner_name = $1
 ORDER BY id
 LIMIT $2
@@ -138,6 +209,9 @@ func (q *Queries) GetAccounts(ctx context.Context, arg GetAccountsParams) ([]Acc
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.AvailableBalance,
+			&i.ChartCode,
+			&i.IsSystem,
 		); err != nil {
 			return nil, err
 		}
@@ -152,20 +226,19 @@ func (q *Queries) GetAccounts(ctx context.Context, arg GetAccountsParams) ([]Acc
 	return items, nil
 }
 
-const updateAccount = `-- name: UpdateAccount :one
-UPDATE accounts 
-SET balance = $2
-WHERE id = $1
-RETURNING id, owner_name, balance, currency, created_at
+const getSystemAccount = `-- name: GetSystemAccount :one
+SELECT id, owner_name, balance, currency, created_at, available_balance, chart_code, is_system FROM accounts
+WHERE is_system AND chart_code = $1 AND currency = $2
+LIMIT 1
 `
 
-type UpdateAccountParams struct {
-	ID      int64 `json:"id"`
-	Balance int64 `json:"balance"`
+type GetSystemAccountParams struct {
+	ChartCode string `json:"chart_code"`
+	Currency  string `json:"currency"`
 }
 
-func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, updateAccount, arg.ID, arg.Balance)
+func (q *Queries) GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getSystemAccount, arg.ChartCode, arg.Currency)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -173,6 +246,47 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AvailableBalance,
+		&i.ChartCode,
+		&i.IsSystem,
 	)
 	return i, err
 }
+
+const getSystemAccounts = `-- name: GetSystemAccounts :many
+SELECT id, owner_name, balance, currency, created_at, available_balance, chart_code, is_system FROM accounts
+WHERE is_system
+ORDER BY chart_code, currency
+`
+
+func (q *Queries) GetSystemAccounts(ctx context.Context) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, getSystemAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerName,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.AvailableBalance,
+			&i.ChartCode,
+			&i.IsSystem,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -14,8 +14,9 @@ func createRandomAccount(t *testing.T) Account {
 	user := createRandomUser(t)
 	arg := CreateAccountParams{
 		OwnerName: user.Username,
-		Balance:   utils.GetRandomAmount(),
-		Currency:  utils.GetRandomCurrency(),
+		// enough for the transfers of the tests, they can't overdraw
+		Balance:  10000 + utils.GetRandomAmount(),
+		Currency: utils.GetRandomCurrency(),
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -25,6 +26,7 @@ func createRandomAccount(t *testing.T) Account {
 	// account checking
 	require.Equal(t, arg.OwnerName, account.OwnerName)
 	require.Equal(t, arg.Balance, account.Balance)
+	require.Equal(t, arg.Balance, account.AvailableBalance)
 	require.Equal(t, arg.Currency, account.Currency)
 
 	// database specific
@@ -77,20 +79,6 @@ func TestGetAccounts(t *testing.T) {
 	}
 }
 
-func TestUpdateAccount(t *testing.T) {
-	acc := createRandomAccount(t)
-	updateParams := UpdateAccountParams{
-		ID:      acc.ID,
-		Balance: 0,
-	}
-	account, err := testQueries.UpdateAccount(context.Background(), updateParams)
-
-	require.NoError(t, err)
-	require.NotEmpty(t, account)
-	require.Equal(t, acc.ID, account.ID)
-	require.Equal(t, account.Balance, int64(0))
-}
-
 func TestDeleteAccount(t *testing.T) {
 	acc := createRandomAccount(t)
 	err := testQueries.DeleteAccount(context.Background(), acc.ID)
@@ -0,0 +1,58 @@
+// Live account activity, sent through LISTEN/NOTIFY so every API replica can stream it.
+package db
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// the channel the activity is NOTIFYed on
+const AccountActivityChannel = "account_activity"
+
+// a change of the account and its balances once the transaction is done, either a new entry or a hold
+// placed, voided or expired. a captured hold shows up as the entries of its transfer.
+type AccountActivity struct {
+	AccountId        int64  `json:"account_id"`
+	OwnerName        string `json:"owner_name"`
+	Currency         string `json:"currency"`
+	Balance          int64  `json:"balance"`
+	AvailableBalance int64  `json:"available_balance"`
+	Entry            *Entry `json:"entry,omitempty"`
+	Hold             *Hold  `json:"hold,omitempty"`
+}
+
+// notifies the listeners about the entry when the running transaction commits
+func notifyActivity(ctx context.Context, q *Queries, account Account, entry Entry) error {
+	activity := newAccountActivity(account)
+	activity.Entry = &entry
+	return notify(ctx, q, activity)
+}
+
+// notifies the listeners about the hold changing the available balance of its payer
+func notifyHold(ctx context.Context, q *Queries, account Account, hold Hold) error {
+	activity := newAccountActivity(account)
+	activity.Hold = &hold
+	return notify(ctx, q, activity)
+}
+
+func newAccountActivity(account Account) AccountActivity {
+	return AccountActivity{
+		AccountId:        account.ID,
+		OwnerName:        account.OwnerName,
+		Currency:         account.Currency,
+		Balance:          account.Balance,
+		AvailableBalance: account.AvailableBalance,
+	}
+}
+
+func notify(ctx context.Context, q *Queries, activity AccountActivity) error {
+	payload, err := json.Marshal(activity)
+	if err != nil {
+		return err
+	}
+
+	return q.NotifyAccountActivity(ctx, NotifyAccountActivityParams{
+		Channel: AccountActivityChannel,
+		Payload: string(payload),
+	})
+}
@@ -0,0 +1,25 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: activity.sql
+
+package db
+
+import (
+	"context"
+)
+
+const notifyAccountActivity = `-- name: NotifyAccountActivity :exec
+SELECT pg_notify($1::text, $2::text)
+`
+
+type NotifyAccountActivityParams struct {
+	Channel string `json:"channel"`
+	Payload string `json:"payload"`
+}
+
+// delivered to the listeners when the transaction commits, dropped if it rolls back
+func (q *Queries) NotifyAccountActivity(ctx context.Context, arg NotifyAccountActivityParams) error {
+	_, err := q.db.ExecContext(ctx, notifyAccountActivity, arg.Channel, arg.Payload)
+	return err
+}
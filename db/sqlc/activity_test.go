@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// pq keeps reconnecting while the database is down, the Listen would wait for it forever
+func listenActivity(t *testing.T) *pq.Listener {
+	listener := pq.NewListener(testConfig.DbSource, time.Second, time.Second, nil)
+	t.Cleanup(func() { listener.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	listened := make(chan error, 1)
+	go func() {
+		listened <- listener.Listen(AccountActivityChannel)
+	}()
+
+	select {
+	case err := <-listened:
+		require.NoError(t, err)
+	case <-ctx.Done():
+		t.Fatal("Timed out listening for the account activity, is the database up?")
+	}
+	return listener
+}
+
+func TestTransferNotifiesActivity(t *testing.T) {
+	listener := listenActivity(t)
+
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	res, err := store.TransferTransaction(context.Background(), TransferTxParams{
+		FromAccountId: acc1.ID,
+		ToAccountId:   acc2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// other tests might be transferring too, keep the activity of our accounts only
+	activities := map[int64]AccountActivity{}
+	timeout := time.After(5 * time.Second)
+	for len(activities) < 2 {
+		select {
+		case notification := <-listener.Notify:
+			if notification == nil {
+				continue
+			}
+			var activity AccountActivity
+			require.NoError(t, json.Unmarshal([]byte(notification.Extra), &activity))
+			if activity.AccountId == acc1.ID || activity.AccountId == acc2.ID {
+				activities[activity.AccountId] = activity
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for the account activity")
+		}
+	}
+
+	require.Equal(t, res.FromEntry.ID, activities[acc1.ID].Entry.ID)
+	require.Equal(t, res.FromAccount.Balance, activities[acc1.ID].Balance)
+	require.Equal(t, acc1.OwnerName, activities[acc1.ID].OwnerName)
+	require.Equal(t, res.ToEntry.ID, activities[acc2.ID].Entry.ID)
+	require.Equal(t, res.ToAccount.Balance, activities[acc2.ID].Balance)
+}
+
+func TestHoldNotifiesActivity(t *testing.T) {
+	listener := listenActivity(t)
+
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	placed, err := store.HoldTransaction(context.Background(), HoldTxParams{
+		FromAccountId: acc1.ID,
+		ToAccountId:   acc2.ID,
+		Amount:        10,
+		ExpiredAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	voided, err := store.VoidHoldTransaction(context.Background(), placed.Hold.ID)
+	require.NoError(t, err)
+
+	// the payer's available balance goes down then back up, the receiver sees nothing until a capture
+	var activities []AccountActivity
+	timeout := time.After(5 * time.Second)
+	for len(activities) < 2 {
+		select {
+		case notification := <-listener.Notify:
+			if notification == nil {
+				continue
+			}
+			var activity AccountActivity
+			require.NoError(t, json.Unmarshal([]byte(notification.Extra), &activity))
+			require.NotEqual(t, acc2.ID, activity.AccountId)
+			if activity.AccountId == acc1.ID {
+				activities = append(activities, activity)
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for the account activity")
+		}
+	}
+
+	require.Nil(t, activities[0].Entry)
+	require.Equal(t, placed.Hold.ID, activities[0].Hold.ID)
+	require.Equal(t, HoldStatusPending, activities[0].Hold.Status)
+	require.Equal(t, acc1.AvailableBalance-10, activities[0].AvailableBalance)
+
+	require.Equal(t, voided.ID, activities[1].Hold.ID)
+	require.Equal(t, HoldStatusVoided, activities[1].Hold.Status)
+	require.Equal(t, acc1.AvailableBalance, activities[1].AvailableBalance)
+}
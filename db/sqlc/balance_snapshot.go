@@ -0,0 +1,44 @@
+// Balances in the past, from the snapshots and the entries booked since.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// snapshots the balances of all the accounts as they were at the given time, reruns are skipped.
+// takenAt should be a bit in the past, so the transactions still running at it are already committed.
+func (store *SQLStore) SnapshotBalances(ctx context.Context, takenAt time.Time) (int64, error) {
+	return store.CreateBalanceSnapshots(ctx, takenAt)
+}
+
+// the balance right after all the entries created until at
+func (store *SQLStore) BalanceAt(ctx context.Context, account Account, at time.Time) (int64, error) {
+	if at.Before(account.CreatedAt) {
+		return 0, nil
+	}
+
+	snapshot, err := store.GetLatestBalanceSnapshot(ctx, GetLatestBalanceSnapshotParams{
+		AccountID: account.ID,
+		At:        at,
+	})
+	if err == nil {
+		total, err := store.SumEntriesBetween(ctx, SumEntriesBetweenParams{
+			AccountID: account.ID,
+			From:      snapshot.TakenAt,
+			To:        at,
+		})
+		return snapshot.Balance + total, err
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	// not snapshotted yet, walk back from the current balance
+	total, err := store.SumEntriesAfter(ctx, SumEntriesAfterParams{
+		AccountID: account.ID,
+		After:     at,
+	})
+	return account.Balance - total, err
+}
@@ -0,0 +1,95 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: balance_snapshot.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createBalanceSnapshots = `-- name: CreateBalanceSnapshots :execrows
+INSERT INTO balance_snapshots (
+  account_id, taken_at, balance
+)
+SELECT
+  a.id, $1, a.balance - (
+    SELECT COALESCE(SUM(e.amount), 0)::bigint FROM entries e
+    WHERE e.account_id = a.id AND e.created_at > $1
+  )
+FROM accounts a
+WHERE a.created_at <= $1
+ON CONFLICT (account_id, taken_at) DO NOTHING
+`
+
+// the entries created after taken_at are taken out of the current balance, the accounts already snapshotted are skipped
+func (q *Queries) CreateBalanceSnapshots(ctx context.Context, takenAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createBalanceSnapshots, takenAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getLatestBalanceSnapshot = `-- name: GetLatestBalanceSnapshot :one
+SELECT id, account_id, taken_at, balance, created_at FROM balance_snapshots
+WHERE account_id = $1 AND taken_at <= $2
+ORDER BY taken_at DESC
+LIMIT 1
+`
+
+type GetLatestBalanceSnapshotParams struct {
+	AccountID int64     `json:"account_id"`
+	At        time.Time `json:"at"`
+}
+
+func (q *Queries) GetLatestBalanceSnapshot(ctx context.Context, arg GetLatestBalanceSnapshotParams) (BalanceSnapshot, error) {
+	row := q.db.QueryRowContext(ctx, getLatestBalanceSnapshot, arg.AccountID, arg.At)
+	var i BalanceSnapshot
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.TakenAt,
+		&i.Balance,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const sumEntriesAfter = `-- name: SumEntriesAfter :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1 AND created_at > $2
+`
+
+type SumEntriesAfterParams struct {
+	AccountID int64     `json:"account_id"`
+	After     time.Time `json:"after"`
+}
+
+func (q *Queries) SumEntriesAfter(ctx context.Context, arg SumEntriesAfterParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumEntriesAfter, arg.AccountID, arg.After)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const sumEntriesBetween = `-- name: SumEntriesBetween :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1 AND created_at > $2 AND created_at <= $3
+`
+
+type SumEntriesBetweenParams struct {
+	AccountID int64     `json:"account_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+// from is exclusive, to is inclusive
+func (q *Queries) SumEntriesBetween(ctx context.Context, arg SumEntriesBetweenParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumEntriesBetween, arg.AccountID, arg.From, arg.To)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
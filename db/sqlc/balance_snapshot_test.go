@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceAt(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	transfer := func(amount int64) TransferTxResult {
+		res, err := store.TransferTransaction(context.Background(), TransferTxParams{
+			FromAccountId: acc1.ID,
+			ToAccountId:   acc2.ID,
+			Amount:        amount,
+		})
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+		return res
+	}
+
+	first := transfer(10)
+	second := transfer(20)
+	afterFirst := first.ToEntry.CreatedAt
+
+	// walked back from the current balance before any snapshot
+	balance, err := store.BalanceAt(context.Background(), acc2, afterFirst)
+	require.NoError(t, err)
+	require.Equal(t, acc2.Balance+10, balance)
+
+	_, err = store.SnapshotBalances(context.Background(), afterFirst)
+	require.NoError(t, err)
+	// a rerun is skipped
+	_, err = store.SnapshotBalances(context.Background(), afterFirst)
+	require.NoError(t, err)
+
+	third := transfer(30)
+
+	account, err := store.GetAccountById(context.Background(), acc2.ID)
+	require.NoError(t, err)
+
+	for at, expected := range map[time.Time]int64{
+		acc2.CreatedAt.Add(-time.Hour): 0,
+		afterFirst:                     acc2.Balance + 10,
+		second.ToEntry.CreatedAt:       acc2.Balance + 30,
+		third.ToEntry.CreatedAt:        acc2.Balance + 60,
+	} {
+		balance, err := store.BalanceAt(context.Background(), account, at)
+		require.NoError(t, err)
+		require.Equal(t, expected, balance)
+	}
+}
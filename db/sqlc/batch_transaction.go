@@ -0,0 +1,71 @@
+// Executing many transfers at once (bulk payouts) in a single database transaction.
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// executes all the transfers or none of them.
+func (store *SQLStore) BatchTransferTransaction(ctx context.Context, args []TransferTxParams) ([]TransferTxResult, error) {
+	res := make([]TransferTxResult, 0, len(args))
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		// the fees are quoted first, their accounts are locked with the others
+		quoted := make([]TransferTxParams, len(args))
+		ids := make([]int64, 0, len(args)*3)
+		for i, arg := range args {
+			if arg.ChargeFee && arg.Quote == nil {
+				fromAccount, err := q.GetAccountById(ctx, arg.FromAccountId)
+				if err != nil {
+					return fmt.Errorf("Transfer [%d] failed: %w", i, err)
+				}
+				quote, err := quoteFee(ctx, q, fromAccount.Currency, arg.Amount)
+				if err != nil {
+					return fmt.Errorf("Transfer [%d] failed: %w", i, err)
+				}
+				arg.Quote = &quote
+			}
+			quoted[i] = arg
+
+			ids = append(ids, arg.FromAccountId, arg.ToAccountId)
+			if arg.Quote != nil && arg.Quote.Fee > 0 {
+				ids = append(ids, arg.Quote.FeeAccountId)
+			}
+		}
+
+		// every account is locked before moving any money, so the order of the transfers doesn't matter
+		if err := lockAccounts(ctx, q, ids...); err != nil {
+			return err
+		}
+
+		for i, arg := range quoted {
+			result, err := transfer(ctx, q, arg)
+			if err != nil {
+				return fmt.Errorf("Transfer [%d] failed: %w", i, err)
+			}
+			res = append(res, result)
+		}
+		return nil
+	})
+	return res, err
+}
+
+// locks the given accounts in ascending id order.
+// transactions touching the same accounts always wait on each other in the same order, hence no deadlocks.
+func lockAccounts(ctx context.Context, q *Queries, ids ...int64) error {
+	sorted := make([]int64, len(ids))
+	copy(sorted, ids)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	for i, id := range sorted {
+		if i > 0 && id == sorted[i-1] {
+			continue
+		}
+		if _, err := q.GetAccountByIdForUpdate(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: cash_receipt.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createCashReceipt = `-- name: CreateCashReceipt :one
+INSERT INTO cash_receipts (
+  kind, account_id, amount, currency, teller, transfer_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING id, kind, account_id, amount, currency, teller, transfer_id, created_at
+`
+
+type CreateCashReceiptParams struct {
+	Kind       string `json:"kind"`
+	AccountID  int64  `json:"account_id"`
+	Amount     int64  `json:"amount"`
+	Currency   string `json:"currency"`
+	Teller     string `json:"teller"`
+	TransferID int64  `json:"transfer_id"`
+}
+
+func (q *Queries) CreateCashReceipt(ctx context.Context, arg CreateCashReceiptParams) (CashReceipt, error) {
+	row := q.db.QueryRowContext(ctx, createCashReceipt,
+		arg.Kind,
+		arg.AccountID,
+		arg.Amount,
+		arg.Currency,
+		arg.Teller,
+		arg.TransferID,
+	)
+	var i CashReceipt
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.AccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Teller,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getCashReceiptById = `-- name: GetCashReceiptById :one
+SELECT id, kind, account_id, amount, currency, teller, transfer_id, created_at FROM cash_receipts
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetCashReceiptById(ctx context.Context, id int64) (CashReceipt, error) {
+	row := q.db.QueryRowContext(ctx, getCashReceiptById, id)
+	var i CashReceipt
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.AccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Teller,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashTransaction(t *testing.T) {
+	store := NewStore(testDb)
+
+	teller, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Username: createRandomUser(t).Username,
+		Role:     RoleTeller,
+	})
+	require.NoError(t, err)
+
+	account := createRandomAccount(t)
+	cash, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		ChartCode: ChartCash,
+		Currency:  account.Currency,
+	})
+	require.NoError(t, err)
+
+	deposit, err := store.CashTransaction(context.Background(), CashTxParams{
+		Kind:      CashDeposit,
+		AccountId: account.ID,
+		Amount:    100,
+		Teller:    teller.Username,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, CashDeposit, deposit.Receipt.Kind)
+	require.Equal(t, deposit.Transfer.ID, deposit.Receipt.TransferID)
+	require.Equal(t, cash.ID, deposit.Transfer.FromAccountID)
+	require.Equal(t, account.Balance+100, deposit.ToAccount.Balance)
+	require.Equal(t, int64(100), deposit.ToEntry.Amount)
+
+	withdrawal, err := store.CashTransaction(context.Background(), CashTxParams{
+		Kind:      CashWithdrawal,
+		AccountId: account.ID,
+		Amount:    40,
+		Teller:    teller.Username,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, cash.ID, withdrawal.Transfer.ToAccountID)
+	require.Equal(t, account.Balance+60, withdrawal.FromAccount.Balance)
+
+	// can't take more than what's there
+	_, err = store.CashTransaction(context.Background(), CashTxParams{
+		Kind:      CashWithdrawal,
+		AccountId: account.ID,
+		Amount:    account.Balance + 61,
+		Teller:    teller.Username,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.CashTransaction(context.Background(), CashTxParams{
+		Kind:      CashDeposit,
+		AccountId: cash.ID,
+		Amount:    1,
+		Teller:    teller.Username,
+	})
+	require.ErrorIs(t, err, ErrSystemAccount)
+
+	// the receipt can be looked up later
+	receipt, err := testQueries.GetCashReceiptById(context.Background(), deposit.Receipt.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Currency, receipt.Currency)
+	require.Equal(t, teller.Username, receipt.Teller)
+}
@@ -0,0 +1,108 @@
+// Cash at the counter: deposits and withdrawals booked against the bank's cash account.
+package db
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	RoleDepositor = "depositor"
+	RoleTeller    = "teller"
+	// sets the transfer limit overrides
+	RoleAdmin = "admin"
+)
+
+const (
+	CashDeposit    = "deposit"
+	CashWithdrawal = "withdrawal"
+)
+
+var ErrSystemAccount = newStatusError(http.StatusUnprocessableEntity, codeUnprocessable, "System accounts can't take cash")
+
+// contains the input params of a cash operation
+type CashTxParams struct {
+	// deposit or withdrawal
+	Kind        string `json:"kind"`
+	AccountId   int64  `json:"account_id"`
+	Amount      int64  `json:"amount"`
+	Teller      string `json:"teller"`
+	Description string `json:"description"`
+	// the limits of the account owner, applied to the withdrawals only
+	Limits *TransferLimits `json:"limits"`
+}
+
+// contains the output result of a cash operation
+type CashTxResult struct {
+	Receipt CashReceipt `json:"receipt"`
+	TransferTxResult
+}
+
+// moves the money between the account and the cash account of its currency, through the normal entries.
+// a deposit is a transfer from the cash account, a withdrawal is one to it.
+func (store *SQLStore) CashTransaction(ctx context.Context, arg CashTxParams) (CashTxResult, error) {
+	var res CashTxResult
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		account, err := q.GetAccountById(ctx, arg.AccountId)
+		if err != nil {
+			return err
+		}
+		if account.IsSystem {
+			return ErrSystemAccount
+		}
+
+		cash, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+			ChartCode: ChartCash,
+			Currency:  account.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err = lockAccounts(ctx, q, account.ID, cash.ID); err != nil {
+			return err
+		}
+
+		transferArg := TransferTxParams{
+			FromAccountId: cash.ID,
+			ToAccountId:   account.ID,
+			Amount:        arg.Amount,
+			Description:   arg.Description,
+			Category:      "cash",
+		}
+		if transferArg.Description == "" {
+			transferArg.Description = "Cash " + arg.Kind
+		}
+
+		if arg.Kind == CashWithdrawal {
+			// the balance might have changed until the lock
+			account, err = q.GetAccountById(ctx, arg.AccountId)
+			if err != nil {
+				return err
+			}
+			if account.AvailableBalance < arg.Amount {
+				return ErrInsufficientFunds
+			}
+
+			transferArg.FromAccountId, transferArg.ToAccountId = account.ID, cash.ID
+			transferArg.Limits = arg.Limits
+		}
+
+		res.TransferTxResult, err = transfer(ctx, q, transferArg)
+		if err != nil {
+			return err
+		}
+
+		res.Receipt, err = q.CreateCashReceipt(ctx, CreateCashReceiptParams{
+			Kind:       arg.Kind,
+			AccountID:  account.ID,
+			Amount:     arg.Amount,
+			Currency:   account.Currency,
+			Teller:     arg.Teller,
+			TransferID: res.Transfer.ID,
+		})
+		return err
+	})
+	return res, err
+}
@@ -0,0 +1,91 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: category_rule.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createCategoryRule = `-- name: CreateCategoryRule :one
+INSERT INTO category_rules (
+  username, pattern, category
+) VALUES (
+  $1, $2, $3
+)
+RETURNING id, username, pattern, category, created_at
+`
+
+type CreateCategoryRuleParams struct {
+	Username string `json:"username"`
+	Pattern  string `json:"pattern"`
+	Category string `json:"category"`
+}
+
+func (q *Queries) CreateCategoryRule(ctx context.Context, arg CreateCategoryRuleParams) (CategoryRule, error) {
+	row := q.db.QueryRowContext(ctx, createCategoryRule, arg.Username, arg.Pattern, arg.Category)
+	var i CategoryRule
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Pattern,
+		&i.Category,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteCategoryRule = `-- name: DeleteCategoryRule :execrows
+DELETE FROM category_rules
+WHERE id = $1 AND username = $2
+`
+
+type DeleteCategoryRuleParams struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) DeleteCategoryRule(ctx context.Context, arg DeleteCategoryRuleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteCategoryRule, arg.ID, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getCategoryRules = `-- name: GetCategoryRules :many
+SELECT id, username, pattern, category, created_at FROM category_rules
+WHERE username = $1
+ORDER BY id
+`
+
+func (q *Queries) GetCategoryRules(ctx context.Context, username string) ([]CategoryRule, error) {
+	rows, err := q.db.QueryContext(ctx, getCategoryRules, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CategoryRule{}
+	for rows.Next() {
+		var i CategoryRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Pattern,
+			&i.Category,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
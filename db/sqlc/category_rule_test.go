@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryRules(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2 := createRandomUser(t)
+
+	rule, err := testQueries.CreateCategoryRule(context.Background(), CreateCategoryRuleParams{
+		Username: user1.Username,
+		Pattern:  "NETFLIX",
+		Category: "subscriptions",
+	})
+	require.NoError(t, err)
+	require.NotZero(t, rule.ID)
+
+	rules, err := testQueries.GetCategoryRules(context.Background(), user1.Username)
+	require.NoError(t, err)
+	require.Equal(t, []CategoryRule{rule}, rules)
+
+	// only the owner can delete the rule
+	deleted, err := testQueries.DeleteCategoryRule(context.Background(), DeleteCategoryRuleParams{ID: rule.ID, Username: user2.Username})
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+
+	deleted, err = testQueries.DeleteCategoryRule(context.Background(), DeleteCategoryRuleParams{ID: rule.ID, Username: user1.Username})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+}
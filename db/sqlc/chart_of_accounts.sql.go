@@ -0,0 +1,38 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: chart_of_accounts.sql
+
+package db
+
+import (
+	"context"
+)
+
+const getChartOfAccounts = `-- name: GetChartOfAccounts :many
+SELECT code, name, type FROM chart_of_accounts
+ORDER BY code
+`
+
+func (q *Queries) GetChartOfAccounts(ctx context.Context) ([]ChartOfAccount, error) {
+	rows, err := q.db.QueryContext(ctx, getChartOfAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ChartOfAccount{}
+	for rows.Next() {
+		var i ChartOfAccount
+		if err := rows.Scan(&i.Code, &i.Name, &i.Type); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
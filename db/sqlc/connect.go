@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/utils"
+)
+
+// the waits between the pings of Connect, doubled after every failed one
+const (
+	connectMinBackoff = 250 * time.Millisecond
+	connectMaxBackoff = 5 * time.Second
+)
+
+// opens the pool of the DB_SOURCE, limited by the DB_MAX_* and DB_CONN_* configs, and waits for the database
+// to be up. it's pinged with a backoff for up to the DB_CONNECT_TIMEOUT, or until the ctx is done when that's 0.
+func Connect(ctx context.Context, config utils.Config) (*sql.DB, error) {
+	conn, err := openPool(config.DbDriver, config.DbSource)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetMaxOpenConns(config.DbMaxOpenConns)
+	// 0 would keep no idle connection at all, the default of database/sql is kept instead
+	if config.DbMaxIdleConns > 0 {
+		conn.SetMaxIdleConns(config.DbMaxIdleConns)
+	}
+	conn.SetConnMaxLifetime(config.DbConnMaxLifetime)
+	conn.SetConnMaxIdleTime(config.DbConnMaxIdleTime)
+
+	if config.DbConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.DbConnectTimeout)
+		defer cancel()
+	}
+	if err := waitForDatabase(ctx, conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func waitForDatabase(ctx context.Context, conn *sql.DB) error {
+	backoff := connectMinBackoff
+	for attempt := 1; ; attempt++ {
+		err := conn.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("the database isn't up after %d attempts: %w", attempt, err)
+		}
+		log.Printf("The database isn't up yet, retrying in %s : %v", backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("the database isn't up after %d attempts: %w", attempt, err)
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, connectMaxBackoff)
+	}
+}
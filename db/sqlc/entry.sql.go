@@ -7,36 +7,49 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  account_id, amount, description, reference, category
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4, $5
 )
-RETURNING id, account_id, amount, created_at
+RETURNING id, account_id, amount, created_at, description, reference, category
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID   int64  `json:"account_id"`
+	Amount      int64  `json:"amount"`
+	Description string `json:"description"`
+	Reference   string `json:"reference"`
+	Category    string `json:"category"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.Description,
+		arg.Reference,
+		arg.Category,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Description,
+		&i.Reference,
+		&i.Category,
 	)
 	return i, err
 }
 
 const getEntries = `-- name: GetEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, description, reference, category FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -63,6 +76,53 @@ func (q *Queries) GetEntries(ctx context.Context, arg GetEntriesParams) ([]Entry
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Description,
+			&i.Reference,
+			&i.Category,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEntriesBetween = `-- name: GetEntriesBetween :many
+SELECT id, account_id, amount, created_at, description, reference, category FROM entries
+WHERE account_id = $1 AND created_at > $2 AND created_at <= $3
+ORDER BY created_at, id
+`
+
+type GetEntriesBetweenParams struct {
+	AccountID int64     `json:"account_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+// from is exclusive and to is inclusive, same as the balances at those times
+func (q *Queries) GetEntriesBetween(ctx context.Context, arg GetEntriesBetweenParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, getEntriesBetween, arg.AccountID, arg.From, arg.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Description,
+			&i.Reference,
+			&i.Category,
 		); err != nil {
 			return nil, err
 		}
@@ -78,7 +138,7 @@ func (q *Queries) GetEntries(ctx context.Context, arg GetEntriesParams) ([]Entry
 }
 
 const getEntryById = `-- name: GetEntryById :one
-SELECT id, account_id, amount, created_at FROM entries 
+SELECT id, account_id, amount, created_at, description, reference, category FROM entries 
 WHERE id = $1 
 LIMIT 1
 `
@@ -91,6 +151,66 @@ func (q *Queries) GetEntryById(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Description,
+		&i.Reference,
+		&i.Category,
 	)
 	return i, err
 }
+
+const searchEntries = `-- name: SearchEntries :many
+SELECT id, account_id, amount, created_at, description, reference, category FROM entries
+WHERE 
+    account_id = $1 AND
+    ($2::varchar = '' OR description ILIKE '%' || replace(replace(replace($2, '\', '\\'), '%', '\%'), '_', '\_') || '%' OR reference = $2) AND
+    ($3::varchar = '' OR category = $3)
+ORDER BY id DESC
+LIMIT $5
+OFFSET $4
+`
+
+type SearchEntriesParams struct {
+	AccountID int64  `json:"account_id"`
+	Query     string `json:"query"`
+	Category  string `json:"category"`
+	Offset    int32  `json:"offset"`
+	Limit     int32  `json:"limit"`
+}
+
+// the empty query/category match everything, the wildcards of the query are matched as they are
+func (q *Queries) SearchEntries(ctx context.Context, arg SearchEntriesParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, searchEntries,
+		arg.AccountID,
+		arg.Query,
+		arg.Category,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Description,
+			&i.Reference,
+			&i.Category,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/AYehia0/go-bk-mst/utils"
 	"github.com/stretchr/testify/require"
@@ -63,3 +64,28 @@ func TestGetEntries(t *testing.T) {
 		require.Equal(t, acc.ID, entry.AccountID)
 	}
 }
+
+func TestGetEntriesBetween(t *testing.T) {
+	acc := createRandomAccount(t)
+	first := createRandomEntry(t, acc)
+	second := createRandomEntry(t, acc)
+
+	// the start is exclusive and the end inclusive
+	entries, err := testQueries.GetEntriesBetween(context.Background(), GetEntriesBetweenParams{
+		AccountID: acc.ID,
+		From:      first.CreatedAt,
+		To:        second.CreatedAt,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, second.ID, entries[0].ID)
+
+	entries, err = testQueries.GetEntriesBetween(context.Background(), GetEntriesBetweenParams{
+		AccountID: acc.ID,
+		From:      first.CreatedAt.Add(-time.Second),
+		To:        second.CreatedAt,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, first.ID, entries[0].ID)
+}
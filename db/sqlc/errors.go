@@ -0,0 +1,47 @@
+package db
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// the codes of the problem responses, the same as the ones of the problem package
+const (
+	codeInvalidArgument   = "invalid_argument"
+	codeConflict          = "conflict"
+	codeInsufficientFunds = "insufficient_funds"
+	codeLimitExceeded     = "limit_exceeded"
+	codeUnprocessable     = "unprocessable"
+)
+
+// an error of the store the users are shown, it tells the status and the code of its response
+// so the http and grpc servers don't have to know every error of the store, see problem.From
+type StatusError struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func newStatusError(status int, code, format string, a ...any) *StatusError {
+	return &StatusError{Status: status, Code: code, Message: fmt.Sprintf(format, a...)}
+}
+
+func (err *StatusError) Error() string {
+	return err.Message
+}
+
+func (err *StatusError) HTTPStatus() int {
+	return err.Status
+}
+
+func (err *StatusError) ErrorCode() string {
+	return err.Code
+}
+
+func (err *LimitError) HTTPStatus() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (err *LimitError) ErrorCode() string {
+	return codeLimitExceeded
+}
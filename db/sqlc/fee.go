@@ -0,0 +1,51 @@
+// Fees charged to the sender of a transfer, following the fee schedules of the currency.
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// the fee of a transfer before it's executed
+type FeeQuote struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+	Fee      int64  `json:"fee"`
+	// what leaves the sender's account: amount + fee
+	Total        int64 `json:"total"`
+	FeeAccountId int64 `json:"-"`
+}
+
+// flat + percentage, the percentage part is rounded half up to the minor unit
+func (schedule FeeSchedule) fee(amount int64) int64 {
+	return schedule.FlatFee + (amount*schedule.PercentageBps+5000)/10000
+}
+
+func (store *SQLStore) PreviewFee(ctx context.Context, currency string, amount int64) (FeeQuote, error) {
+	return quoteFee(ctx, store.Queries, currency, amount)
+}
+
+// the transfers of a currency without any schedule are free
+func quoteFee(ctx context.Context, q *Queries, currency string, amount int64) (FeeQuote, error) {
+	quote := FeeQuote{
+		Currency: currency,
+		Amount:   amount,
+		Total:    amount,
+	}
+
+	schedule, err := q.GetFeeSchedule(ctx, GetFeeScheduleParams{
+		Currency: currency,
+		Amount:   amount,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return quote, nil
+		}
+		return quote, err
+	}
+
+	quote.Fee = schedule.fee(amount)
+	quote.Total += quote.Fee
+	quote.FeeAccountId = schedule.FeeAccountID
+	return quote, nil
+}
@@ -0,0 +1,123 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: fee_schedule.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createFeeSchedule = `-- name: CreateFeeSchedule :one
+INSERT INTO fee_schedules (
+  currency, min_amount, flat_fee, percentage_bps, fee_account_id
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING id, currency, min_amount, flat_fee, percentage_bps, fee_account_id, created_at
+`
+
+type CreateFeeScheduleParams struct {
+	Currency      string `json:"currency"`
+	MinAmount     int64  `json:"min_amount"`
+	FlatFee       int64  `json:"flat_fee"`
+	PercentageBps int64  `json:"percentage_bps"`
+	FeeAccountID  int64  `json:"fee_account_id"`
+}
+
+func (q *Queries) CreateFeeSchedule(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error) {
+	row := q.db.QueryRowContext(ctx, createFeeSchedule,
+		arg.Currency,
+		arg.MinAmount,
+		arg.FlatFee,
+		arg.PercentageBps,
+		arg.FeeAccountID,
+	)
+	var i FeeSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.Currency,
+		&i.MinAmount,
+		&i.FlatFee,
+		&i.PercentageBps,
+		&i.FeeAccountID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteFeeSchedule = `-- name: DeleteFeeSchedule :exec
+DELETE FROM fee_schedules
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFeeSchedule(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteFeeSchedule, id)
+	return err
+}
+
+const getFeeSchedule = `-- name: GetFeeSchedule :one
+SELECT id, currency, min_amount, flat_fee, percentage_bps, fee_account_id, created_at FROM fee_schedules
+WHERE currency = $1 AND min_amount <= $2
+ORDER BY min_amount DESC
+LIMIT 1
+`
+
+type GetFeeScheduleParams struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+}
+
+// the highest tier the amount reaches
+func (q *Queries) GetFeeSchedule(ctx context.Context, arg GetFeeScheduleParams) (FeeSchedule, error) {
+	row := q.db.QueryRowContext(ctx, getFeeSchedule, arg.Currency, arg.Amount)
+	var i FeeSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.Currency,
+		&i.MinAmount,
+		&i.FlatFee,
+		&i.PercentageBps,
+		&i.FeeAccountID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getFeeSchedules = `-- name: GetFeeSchedules :many
+SELECT id, currency, min_amount, flat_fee, percentage_bps, fee_account_id, created_at FROM fee_schedules
+WHERE currency = $1
+ORDER BY min_amount
+`
+
+func (q *Queries) GetFeeSchedules(ctx context.Context, currency string) ([]FeeSchedule, error) {
+	rows, err := q.db.QueryContext(ctx, getFeeSchedules, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FeeSchedule{}
+	for rows.Next() {
+		var i FeeSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Currency,
+			&i.MinAmount,
+			&i.FlatFee,
+			&i.PercentageBps,
+			&i.FeeAccountID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
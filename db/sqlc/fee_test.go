@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createAccountWithCurrency(t *testing.T, currency string) Account {
+	user := createRandomUser(t)
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		OwnerName: user.Username,
+		Balance:   100000 + utils.GetRandomAmount(),
+		Currency:  currency,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestTransferFees(t *testing.T) {
+	store := NewStore(testDb)
+
+	// a currency of its own, so the schedules don't leak into the other tests
+	currency := strings.ToUpper(utils.RandomString(3))
+	acc1 := createAccountWithCurrency(t, currency)
+	acc2 := createAccountWithCurrency(t, currency)
+	feeAccount, err := testQueries.CreateSystemAccount(context.Background(), CreateSystemAccountParams{
+		Currency:  currency,
+		ChartCode: ChartFeeIncome,
+	})
+	require.NoError(t, err)
+
+	// flat 0.50 for small transfers, 1% + 0.25 from 100.00
+	for _, arg := range []CreateFeeScheduleParams{
+		{Currency: currency, MinAmount: 0, FlatFee: 50, FeeAccountID: feeAccount.ID},
+		{Currency: currency, MinAmount: 10000, FlatFee: 25, PercentageBps: 100, FeeAccountID: feeAccount.ID},
+	} {
+		schedule, err := testQueries.CreateFeeSchedule(context.Background(), arg)
+		require.NoError(t, err)
+		defer testQueries.DeleteFeeSchedule(context.Background(), schedule.ID)
+	}
+
+	quote, err := store.PreviewFee(context.Background(), currency, 9999)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), quote.Fee)
+
+	quote, err = store.PreviewFee(context.Background(), currency, 12345)
+	require.NoError(t, err)
+	require.Equal(t, int64(25+123), quote.Fee)
+	require.Equal(t, int64(12345+148), quote.Total)
+
+	// no schedule, no fee
+	quote, err = store.PreviewFee(context.Background(), strings.ToUpper(utils.RandomString(3)), 12345)
+	require.NoError(t, err)
+	require.Zero(t, quote.Fee)
+
+	res, err := store.TransferTransaction(context.Background(), TransferTxParams{
+		FromAccountId: acc1.ID,
+		ToAccountId:   acc2.ID,
+		Amount:        12345,
+		ChargeFee:     true,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(148), res.Fee)
+	require.Equal(t, int64(148), res.Transfer.Fee)
+	require.Equal(t, -int64(148), res.FromFeeEntry.Amount)
+	require.Equal(t, feeAccount.ID, res.FeeEntry.AccountID)
+	require.Equal(t, int64(148), res.FeeEntry.Amount)
+
+	require.Equal(t, acc1.Balance-12345-148, res.FromAccount.Balance)
+	require.Equal(t, acc2.Balance+12345, res.ToAccount.Balance)
+
+	account, err := store.GetAccountById(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance+148, account.Balance)
+}
+
+func TestHoldFees(t *testing.T) {
+	store := NewStore(testDb)
+
+	currency := strings.ToUpper(utils.RandomString(3))
+	acc1 := createAccountWithCurrency(t, currency)
+	acc2 := createAccountWithCurrency(t, currency)
+	feeAccount, err := testQueries.CreateSystemAccount(context.Background(), CreateSystemAccountParams{
+		Currency:  currency,
+		ChartCode: ChartFeeIncome,
+	})
+	require.NoError(t, err)
+
+	schedule, err := testQueries.CreateFeeSchedule(context.Background(), CreateFeeScheduleParams{
+		Currency: currency, MinAmount: 0, FlatFee: 50, FeeAccountID: feeAccount.ID,
+	})
+	require.NoError(t, err)
+
+	// the fee is reserved with the amount
+	arg := HoldTxParams{
+		FromAccountId: acc1.ID,
+		ToAccountId:   acc2.ID,
+		Amount:        1000,
+		ExpiredAt:     time.Now().Add(time.Hour),
+		ChargeFee:     true,
+	}
+	captured, err := store.HoldTransaction(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), captured.Hold.Fee)
+	require.Equal(t, acc1.AvailableBalance-1050, captured.FromAccount.AvailableBalance)
+
+	voided, err := store.HoldTransaction(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, acc1.AvailableBalance-2100, voided.FromAccount.AvailableBalance)
+
+	// the capture charges the quote of the hold, even once the schedule is gone
+	require.NoError(t, testQueries.DeleteFeeSchedule(context.Background(), schedule.ID))
+
+	res, err := store.CaptureHoldTransaction(context.Background(), captured.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), res.Fee)
+	require.Equal(t, feeAccount.ID, res.FeeEntry.AccountID)
+	require.Equal(t, acc1.Balance-1050, res.FromAccount.Balance)
+	require.Equal(t, acc1.AvailableBalance-2100, res.FromAccount.AvailableBalance)
+
+	// the void gives the fee back with the amount
+	_, err = store.VoidHoldTransaction(context.Background(), voided.Hold.ID)
+	require.NoError(t, err)
+
+	account, err := store.GetAccountById(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc1.Balance-1050, account.AvailableBalance)
+}
+
+// the fee account comes before the accounts of the transfers, a batch has to lock it with them
+// or it waits on it while holding rows the single transfers wait on
+func TestBatchTransferFeesDeadlock(t *testing.T) {
+	store := NewStore(testDb)
+
+	currency := strings.ToUpper(utils.RandomString(3))
+	feeAccount, err := testQueries.CreateSystemAccount(context.Background(), CreateSystemAccountParams{
+		Currency:  currency,
+		ChartCode: ChartFeeIncome,
+	})
+	require.NoError(t, err)
+	acc1 := createAccountWithCurrency(t, currency)
+	acc2 := createAccountWithCurrency(t, currency)
+
+	schedule, err := testQueries.CreateFeeSchedule(context.Background(), CreateFeeScheduleParams{
+		Currency: currency, MinAmount: 0, FlatFee: 1, FeeAccountID: feeAccount.ID,
+	})
+	require.NoError(t, err)
+	defer testQueries.DeleteFeeSchedule(context.Background(), schedule.ID)
+
+	errs := make(chan error)
+
+	numConcurrent := 10
+	for i := 0; i < numConcurrent; i++ {
+		arg := TransferTxParams{FromAccountId: acc1.ID, ToAccountId: acc2.ID, Amount: 10, ChargeFee: true}
+		batch := i%2 == 0
+		if !batch {
+			arg.FromAccountId, arg.ToAccountId = acc2.ID, acc1.ID
+		}
+
+		go func() {
+			if batch {
+				_, err := store.BatchTransferTransaction(context.Background(), []TransferTxParams{arg, arg})
+				errs <- err
+				return
+			}
+			_, err := store.TransferTransaction(context.Background(), arg)
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < numConcurrent; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	// 5 batches of 2 transfers and 5 single ones
+	account, err := store.GetAccountById(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance+15, account.Balance)
+}
@@ -0,0 +1,175 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: hold.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createHold = `-- name: CreateHold :one
+INSERT INTO holds (
+  from_account_id, to_account_id, amount, expired_at, fee, fee_account_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING id, from_account_id, to_account_id, amount, status, transfer_id, expired_at, created_at, fee, fee_account_id
+`
+
+type CreateHoldParams struct {
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	ExpiredAt     time.Time     `json:"expired_at"`
+	Fee           int64         `json:"fee"`
+	FeeAccountID  sql.NullInt64 `json:"fee_account_id"`
+}
+
+func (q *Queries) CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error) {
+	row := q.db.QueryRowContext(ctx, createHold,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ExpiredAt,
+		arg.Fee,
+		arg.FeeAccountID,
+	)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiredAt,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.FeeAccountID,
+	)
+	return i, err
+}
+
+const getExpiredHoldsForUpdate = `-- name: GetExpiredHoldsForUpdate :many
+SELECT id, from_account_id, to_account_id, amount, status, transfer_id, expired_at, created_at, fee, fee_account_id FROM holds
+WHERE status = 'pending' AND expired_at <= now()
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) GetExpiredHoldsForUpdate(ctx context.Context, limit int32) ([]Hold, error) {
+	rows, err := q.db.QueryContext(ctx, getExpiredHoldsForUpdate, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Hold{}
+	for rows.Next() {
+		var i Hold
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Status,
+			&i.TransferID,
+			&i.ExpiredAt,
+			&i.CreatedAt,
+			&i.Fee,
+			&i.FeeAccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getHoldById = `-- name: GetHoldById :one
+SELECT id, from_account_id, to_account_id, amount, status, transfer_id, expired_at, created_at, fee, fee_account_id FROM holds
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetHoldById(ctx context.Context, id int64) (Hold, error) {
+	row := q.db.QueryRowContext(ctx, getHoldById, id)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiredAt,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.FeeAccountID,
+	)
+	return i, err
+}
+
+const getHoldByIdForUpdate = `-- name: GetHoldByIdForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, status, transfer_id, expired_at, created_at, fee, fee_account_id FROM holds
+WHERE id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetHoldByIdForUpdate(ctx context.Context, id int64) (Hold, error) {
+	row := q.db.QueryRowContext(ctx, getHoldByIdForUpdate, id)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiredAt,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.FeeAccountID,
+	)
+	return i, err
+}
+
+const updateHoldStatus = `-- name: UpdateHoldStatus :one
+UPDATE holds
+SET status = $2, transfer_id = $3
+WHERE id = $1
+RETURNING id, from_account_id, to_account_id, amount, status, transfer_id, expired_at, created_at, fee, fee_account_id
+`
+
+type UpdateHoldStatusParams struct {
+	ID         int64         `json:"id"`
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+}
+
+func (q *Queries) UpdateHoldStatus(ctx context.Context, arg UpdateHoldStatusParams) (Hold, error) {
+	row := q.db.QueryRowContext(ctx, updateHoldStatus, arg.ID, arg.Status, arg.TransferID)
+	var i Hold
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiredAt,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.FeeAccountID,
+	)
+	return i, err
+}
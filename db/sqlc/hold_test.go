@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomHold(t *testing.T, store Store, acc1, acc2 Account, amount int64, expiredAt time.Time) HoldTxResult {
+	res, err := store.HoldTransaction(context.Background(), HoldTxParams{
+		FromAccountId: acc1.ID,
+		ToAccountId:   acc2.ID,
+		Amount:        amount,
+		ExpiredAt:     expiredAt,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.Hold)
+
+	require.Equal(t, HoldStatusPending, res.Hold.Status)
+	require.False(t, res.Hold.TransferID.Valid)
+
+	// only the available balance is reserved
+	require.Equal(t, acc1.Balance, res.FromAccount.Balance)
+	require.Equal(t, acc1.AvailableBalance-amount, res.FromAccount.AvailableBalance)
+
+	return res
+}
+
+func TestHoldTransaction(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	createRandomHold(t, store, acc1, acc2, acc1.AvailableBalance, time.Now().Add(time.Hour))
+
+	// nothing left to reserve
+	_, err := store.HoldTransaction(context.Background(), HoldTxParams{
+		FromAccountId: acc1.ID,
+		ToAccountId:   acc2.ID,
+		Amount:        1,
+		ExpiredAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestCaptureHoldTransaction(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	amount := int64(1)
+	hold := createRandomHold(t, store, acc1, acc2, amount, time.Now().Add(time.Hour)).Hold
+
+	res, err := store.CaptureHoldTransaction(context.Background(), hold.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, HoldStatusCaptured, res.Hold.Status)
+	require.True(t, res.Hold.TransferID.Valid)
+	require.Equal(t, res.Transfer.ID, res.Hold.TransferID.Int64)
+
+	// both balances of the payer end up reduced by the amount
+	require.Equal(t, acc1.Balance-amount, res.FromAccount.Balance)
+	require.Equal(t, acc1.AvailableBalance-amount, res.FromAccount.AvailableBalance)
+	require.Equal(t, acc2.Balance+amount, res.ToAccount.Balance)
+	require.Equal(t, acc2.AvailableBalance+amount, res.ToAccount.AvailableBalance)
+
+	// can't capture twice
+	_, err = store.CaptureHoldTransaction(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotPending)
+}
+
+func TestVoidHoldTransaction(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	hold := createRandomHold(t, store, acc1, acc2, 1, time.Now().Add(time.Hour)).Hold
+
+	voided, err := store.VoidHoldTransaction(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusVoided, voided.Status)
+
+	account, err := store.GetAccountById(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc1.Balance, account.Balance)
+	require.Equal(t, acc1.AvailableBalance, account.AvailableBalance)
+
+	_, err = store.CaptureHoldTransaction(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotPending)
+}
+
+func TestExpireHoldsTransaction(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	hold := createRandomHold(t, store, acc1, acc2, 1, time.Now().Add(-time.Minute)).Hold
+
+	_, err := store.CaptureHoldTransaction(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	// other tests might leave expired holds as well, release all of them
+	for {
+		holds, err := store.ExpireHoldsTransaction(context.Background(), 100)
+		require.NoError(t, err)
+		if len(holds) == 0 {
+			break
+		}
+	}
+
+	expired, err := store.GetHoldById(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusExpired, expired.Status)
+
+	account, err := store.GetAccountById(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc1.AvailableBalance, account.AvailableBalance)
+}
+
+func TestTransferKeepsHeldFunds(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	// everything is on hold, nothing is left to spend
+	hold := createRandomHold(t, store, acc1, acc2, acc1.AvailableBalance, time.Now().Add(time.Hour)).Hold
+
+	_, err := store.TransferTransaction(context.Background(), TransferTxParams{
+		FromAccountId: acc1.ID,
+		ToAccountId:   acc2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.BatchTransferTransaction(context.Background(), []TransferTxParams{
+		{FromAccountId: acc1.ID, ToAccountId: acc2.ID, Amount: 1},
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// but the hold itself can be captured
+	res, err := store.CaptureHoldTransaction(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Zero(t, res.FromAccount.Balance)
+	require.Zero(t, res.FromAccount.AvailableBalance)
+}
@@ -0,0 +1,227 @@
+// Two-phase transfers: the funds are reserved first (hold), then either captured into a normal transfer
+// or released back (void/expiry).
+package db
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+)
+
+const (
+	HoldStatusPending  = "pending"
+	HoldStatusCaptured = "captured"
+	HoldStatusVoided   = "voided"
+	HoldStatusExpired  = "expired"
+)
+
+var (
+	ErrInsufficientFunds = newStatusError(http.StatusUnprocessableEntity, codeInsufficientFunds, "Insufficient available balance")
+	ErrHoldNotPending    = newStatusError(http.StatusConflict, codeConflict, "Hold is no longer pending")
+	ErrHoldExpired       = newStatusError(http.StatusConflict, codeConflict, "Hold has been expired")
+)
+
+// contains the input params to reserve funds
+type HoldTxParams struct {
+	FromAccountId int64     `json:"from_account_id"`
+	ToAccountId   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	ExpiredAt     time.Time `json:"expired_at"`
+	// the limits of the payer, a pending hold counts like a transfer
+	Limits *TransferLimits `json:"limits"`
+	// the fee of the transfer is quoted now and reserved with the amount, the capture charges it
+	ChargeFee bool `json:"charge_fee"`
+}
+
+// contains the output result of a successful hold
+type HoldTxResult struct {
+	Hold        Hold    `json:"hold"`
+	FromAccount Account `json:"from_account"`
+}
+
+// contains the output result of a successful capture
+type CaptureHoldTxResult struct {
+	Hold Hold `json:"hold"`
+	TransferTxResult
+}
+
+// reserves the amount from the available balance of the from account, the ledger balance stays as it's.
+func (store *SQLStore) HoldTransaction(ctx context.Context, arg HoldTxParams) (HoldTxResult, error) {
+	var res HoldTxResult
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		// lock the account, so concurrent holds can't reserve the same funds twice
+		account, err := q.GetAccountByIdForUpdate(ctx, arg.FromAccountId)
+		if err != nil {
+			return err
+		}
+
+		var quote FeeQuote
+		if arg.ChargeFee {
+			quote, err = quoteFee(ctx, q, account.Currency, arg.Amount)
+			if err != nil {
+				return err
+			}
+		}
+
+		if account.AvailableBalance < arg.Amount+quote.Fee {
+			return ErrInsufficientFunds
+		}
+
+		if arg.Limits != nil {
+			if err := checkTransferLimits(ctx, q, account, *arg.Limits, arg.Amount); err != nil {
+				return err
+			}
+		}
+
+		res.FromAccount, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			ID:     arg.FromAccountId,
+			Amount: -(arg.Amount + quote.Fee),
+		})
+		if err != nil {
+			return err
+		}
+
+		res.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			FromAccountID: arg.FromAccountId,
+			ToAccountID:   arg.ToAccountId,
+			Amount:        arg.Amount,
+			ExpiredAt:     arg.ExpiredAt,
+			Fee:           quote.Fee,
+			FeeAccountID:  sql.NullInt64{Int64: quote.FeeAccountId, Valid: quote.Fee > 0},
+		})
+		if err != nil {
+			return err
+		}
+		return notifyHold(ctx, q, res.FromAccount, res.Hold)
+	})
+	return res, err
+}
+
+// settles a pending hold through the normal transfer/entries mechanism.
+func (store *SQLStore) CaptureHoldTransaction(ctx context.Context, holdId int64) (CaptureHoldTxResult, error) {
+	var res CaptureHoldTxResult
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		hold, err := getPendingHold(ctx, q, holdId)
+		if err != nil {
+			return err
+		}
+
+		if time.Now().After(hold.ExpiredAt) {
+			return ErrHoldExpired
+		}
+
+		// the fee quoted by the hold, the schedule might have changed since
+		quote := FeeQuote{Amount: hold.Amount, Fee: hold.Fee, Total: hold.Amount + hold.Fee, FeeAccountId: hold.FeeAccountID.Int64}
+
+		// in the order of the transfer, so no deadlock can happen
+		ids := []int64{hold.FromAccountID, hold.ToAccountID}
+		if hold.FeeAccountID.Valid {
+			ids = append(ids, hold.FeeAccountID.Int64)
+		}
+		if err = lockAccounts(ctx, q, ids...); err != nil {
+			return err
+		}
+
+		// give back the reserved amount and fee first, the transfer takes them from the available balance again
+		// and the payer can spend what's on its own hold
+		_, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			ID:     hold.FromAccountID,
+			Amount: quote.Total,
+		})
+		if err != nil {
+			return err
+		}
+
+		res.TransferTxResult, err = transfer(ctx, q, TransferTxParams{
+			FromAccountId: hold.FromAccountID,
+			ToAccountId:   hold.ToAccountID,
+			Amount:        hold.Amount,
+			ChargeFee:     quote.Fee > 0,
+			Quote:         &quote,
+		})
+		if err != nil {
+			return err
+		}
+
+		res.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+			ID:         hold.ID,
+			Status:     HoldStatusCaptured,
+			TransferID: sql.NullInt64{Int64: res.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+	return res, err
+}
+
+// releases the reserved amount of a pending hold.
+func (store *SQLStore) VoidHoldTransaction(ctx context.Context, holdId int64) (Hold, error) {
+	var res Hold
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		hold, err := getPendingHold(ctx, q, holdId)
+		if err != nil {
+			return err
+		}
+
+		res, err = releaseHold(ctx, q, hold, HoldStatusVoided)
+		return err
+	})
+	return res, err
+}
+
+// releases up to limit pending holds which passed their expiry time.
+// the holds are locked with SKIP LOCKED, so multiple instances can run it at the same time.
+func (store *SQLStore) ExpireHoldsTransaction(ctx context.Context, limit int32) ([]Hold, error) {
+	res := []Hold{}
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		holds, err := q.GetExpiredHoldsForUpdate(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, hold := range holds {
+			hold, err = releaseHold(ctx, q, hold, HoldStatusExpired)
+			if err != nil {
+				return err
+			}
+			res = append(res, hold)
+		}
+		return nil
+	})
+	return res, err
+}
+
+func getPendingHold(ctx context.Context, q *Queries, holdId int64) (Hold, error) {
+	hold, err := q.GetHoldByIdForUpdate(ctx, holdId)
+	if err != nil {
+		return hold, err
+	}
+
+	if hold.Status != HoldStatusPending {
+		return hold, ErrHoldNotPending
+	}
+	return hold, nil
+}
+
+func releaseHold(ctx context.Context, q *Queries, hold Hold, status string) (Hold, error) {
+	account, err := q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+		ID:     hold.FromAccountID,
+		Amount: hold.Amount + hold.Fee,
+	})
+	if err != nil {
+		return hold, err
+	}
+
+	hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+	if err != nil {
+		return hold, err
+	}
+	return hold, notifyHold(ctx, q, account, hold)
+}
@@ -0,0 +1,310 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: interest.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const accrueInterest = `-- name: AccrueInterest :execrows
+WITH eod AS (
+  SELECT
+    a.id AS account_id,
+    p.id AS plan_id,
+    p.annual_rate_bps,
+    a.balance - (
+      SELECT COALESCE(SUM(e.amount), 0)::bigint FROM entries e
+      WHERE e.account_id = a.id AND e.created_at >= $2
+    ) AS balance
+  FROM accounts a
+  JOIN account_interest_plans ap ON ap.account_id = a.id
+  JOIN interest_plans p ON p.id = ap.plan_id
+  WHERE a.created_at < $2 AND ap.created_at < $2
+)
+INSERT INTO interest_accruals (
+  account_id, plan_id, date, balance, amount
+)
+SELECT
+  account_id, plan_id, $1::date, balance,
+  GREATEST(balance, 0) * annual_rate_bps * 100 / 365
+FROM eod
+ON CONFLICT (account_id, date) DO NOTHING
+`
+
+type AccrueInterestParams struct {
+	Date     time.Time `json:"date"`
+	EndOfDay time.Time `json:"end_of_day"`
+}
+
+// the end of day balance is the current one minus whatever was booked after the day ended,
+// the accounts already accrued for the day are skipped, so are the ones which got their plan after the day ended.
+func (q *Queries) AccrueInterest(ctx context.Context, arg AccrueInterestParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, accrueInterest, arg.Date, arg.EndOfDay)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createInterestPlan = `-- name: CreateInterestPlan :one
+INSERT INTO interest_plans (
+  name, currency, annual_rate_bps, expense_account_id
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, name, currency, annual_rate_bps, expense_account_id, created_at
+`
+
+type CreateInterestPlanParams struct {
+	Name             string `json:"name"`
+	Currency         string `json:"currency"`
+	AnnualRateBps    int64  `json:"annual_rate_bps"`
+	ExpenseAccountID int64  `json:"expense_account_id"`
+}
+
+func (q *Queries) CreateInterestPlan(ctx context.Context, arg CreateInterestPlanParams) (InterestPlan, error) {
+	row := q.db.QueryRowContext(ctx, createInterestPlan,
+		arg.Name,
+		arg.Currency,
+		arg.AnnualRateBps,
+		arg.ExpenseAccountID,
+	)
+	var i InterestPlan
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Currency,
+		&i.AnnualRateBps,
+		&i.ExpenseAccountID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getInterestAccruals = `-- name: GetInterestAccruals :many
+SELECT id, account_id, plan_id, date, balance, amount, posted_at, transfer_id, created_at FROM interest_accruals
+WHERE account_id = $1
+ORDER BY date DESC
+LIMIT $2
+OFFSET $3
+`
+
+type GetInterestAccrualsParams struct {
+	AccountID int64 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
+}
+
+func (q *Queries) GetInterestAccruals(ctx context.Context, arg GetInterestAccrualsParams) ([]InterestAccrual, error) {
+	rows, err := q.db.QueryContext(ctx, getInterestAccruals, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InterestAccrual{}
+	for rows.Next() {
+		var i InterestAccrual
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.PlanID,
+			&i.Date,
+			&i.Balance,
+			&i.Amount,
+			&i.PostedAt,
+			&i.TransferID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInterestPlanById = `-- name: GetInterestPlanById :one
+SELECT id, name, currency, annual_rate_bps, expense_account_id, created_at FROM interest_plans
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetInterestPlanById(ctx context.Context, id int64) (InterestPlan, error) {
+	row := q.db.QueryRowContext(ctx, getInterestPlanById, id)
+	var i InterestPlan
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Currency,
+		&i.AnnualRateBps,
+		&i.ExpenseAccountID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getInterestPlans = `-- name: GetInterestPlans :many
+SELECT id, name, currency, annual_rate_bps, expense_account_id, created_at FROM interest_plans
+WHERE currency = $1
+ORDER BY id
+`
+
+func (q *Queries) GetInterestPlans(ctx context.Context, currency string) ([]InterestPlan, error) {
+	rows, err := q.db.QueryContext(ctx, getInterestPlans, currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InterestPlan{}
+	for rows.Next() {
+		var i InterestPlan
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Currency,
+			&i.AnnualRateBps,
+			&i.ExpenseAccountID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnpostedAccrualsForUpdate = `-- name: GetUnpostedAccrualsForUpdate :many
+SELECT id, account_id, plan_id, date, balance, amount, posted_at, transfer_id, created_at FROM interest_accruals
+WHERE account_id = $1 AND plan_id = $2 AND posted_at IS NULL AND date < $3::date
+ORDER BY date
+FOR UPDATE
+`
+
+type GetUnpostedAccrualsForUpdateParams struct {
+	AccountID int64     `json:"account_id"`
+	PlanID    int64     `json:"plan_id"`
+	Before    time.Time `json:"before"`
+}
+
+func (q *Queries) GetUnpostedAccrualsForUpdate(ctx context.Context, arg GetUnpostedAccrualsForUpdateParams) ([]InterestAccrual, error) {
+	rows, err := q.db.QueryContext(ctx, getUnpostedAccrualsForUpdate, arg.AccountID, arg.PlanID, arg.Before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InterestAccrual{}
+	for rows.Next() {
+		var i InterestAccrual
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.PlanID,
+			&i.Date,
+			&i.Balance,
+			&i.Amount,
+			&i.PostedAt,
+			&i.TransferID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnpostedInterest = `-- name: GetUnpostedInterest :many
+SELECT account_id, plan_id FROM interest_accruals
+WHERE posted_at IS NULL AND date < $1::date
+GROUP BY account_id, plan_id
+`
+
+type GetUnpostedInterestRow struct {
+	AccountID int64 `json:"account_id"`
+	PlanID    int64 `json:"plan_id"`
+}
+
+func (q *Queries) GetUnpostedInterest(ctx context.Context, before time.Time) ([]GetUnpostedInterestRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUnpostedInterest, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetUnpostedInterestRow{}
+	for rows.Next() {
+		var i GetUnpostedInterestRow
+		if err := rows.Scan(&i.AccountID, &i.PlanID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markAccrualsPosted = `-- name: MarkAccrualsPosted :exec
+UPDATE interest_accruals
+SET posted_at = now(), transfer_id = $1
+WHERE id = ANY($2::bigint[])
+`
+
+type MarkAccrualsPostedParams struct {
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	Ids        []int64       `json:"ids"`
+}
+
+func (q *Queries) MarkAccrualsPosted(ctx context.Context, arg MarkAccrualsPostedParams) error {
+	_, err := q.db.ExecContext(ctx, markAccrualsPosted, arg.TransferID, pq.Array(arg.Ids))
+	return err
+}
+
+const setAccountInterestPlan = `-- name: SetAccountInterestPlan :one
+INSERT INTO account_interest_plans (
+  account_id, plan_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (account_id) DO UPDATE
+SET plan_id = EXCLUDED.plan_id
+RETURNING account_id, plan_id, created_at
+`
+
+type SetAccountInterestPlanParams struct {
+	AccountID int64 `json:"account_id"`
+	PlanID    int64 `json:"plan_id"`
+}
+
+func (q *Queries) SetAccountInterestPlan(ctx context.Context, arg SetAccountInterestPlanParams) (AccountInterestPlan, error) {
+	row := q.db.QueryRowContext(ctx, setAccountInterestPlan, arg.AccountID, arg.PlanID)
+	var i AccountInterestPlan
+	err := row.Scan(&i.AccountID, &i.PlanID, &i.CreatedAt)
+	return i, err
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterest(t *testing.T) {
+	store := NewStore(testDb)
+
+	currency := strings.ToUpper(utils.RandomString(3))
+	account := createAccountWithCurrency(t, currency)
+	expenseAccount, err := testQueries.CreateSystemAccount(context.Background(), CreateSystemAccountParams{
+		Currency:  currency,
+		ChartCode: ChartInterestExpense,
+	})
+	require.NoError(t, err)
+
+	plan, err := testQueries.CreateInterestPlan(context.Background(), CreateInterestPlanParams{
+		Name:             "Saver",
+		Currency:         currency,
+		AnnualRateBps:    365,
+		ExpenseAccountID: expenseAccount.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.SetAccountInterestPlan(context.Background(), SetAccountInterestPlanParams{
+		AccountID: account.ID,
+		PlanID:    plan.ID,
+	})
+	require.NoError(t, err)
+
+	// the account is created today, the previous days have nothing to accrue
+	today := time.Now().UTC()
+	for i := 0; i < 2; i++ {
+		_, err = store.AccrueInterestTransaction(context.Background(), today.AddDate(0, 0, -1))
+		require.NoError(t, err)
+		_, err = store.AccrueInterestTransaction(context.Background(), today)
+		require.NoError(t, err)
+	}
+
+	// a single accrual no matter how many times the job runs
+	accruals, err := testQueries.GetInterestAccruals(context.Background(), GetInterestAccrualsParams{
+		AccountID: account.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 1)
+	require.Equal(t, account.Balance, accruals[0].Balance)
+	// 3.65% a year is 0.01% a day
+	require.Equal(t, account.Balance*InterestAccrualScale/10000, accruals[0].Amount)
+
+	arg := PostInterestTxParams{
+		AccountId: account.ID,
+		PlanId:    plan.ID,
+		Before:    today.AddDate(0, 0, 1),
+	}
+	res, err := store.PostInterestTransaction(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, res.Accruals, 1)
+
+	expected := (accruals[0].Amount + InterestAccrualScale/2) / InterestAccrualScale
+	require.Equal(t, expected, res.Amount)
+	if expected > 0 {
+		require.NotNil(t, res.Transfer)
+		require.Equal(t, expenseAccount.ID, res.Transfer.Transfer.FromAccountID)
+		require.Equal(t, account.Balance+expected, res.Transfer.ToAccount.Balance)
+	}
+
+	// already posted
+	res, err = store.PostInterestTransaction(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, res.Accruals)
+	require.Nil(t, res.Transfer)
+}
+
+func TestAccrueInterestFromPlanStart(t *testing.T) {
+	store := NewStore(testDb)
+
+	currency := strings.ToUpper(utils.RandomString(3))
+	account := createAccountWithCurrency(t, currency)
+	expenseAccount, err := testQueries.CreateSystemAccount(context.Background(), CreateSystemAccountParams{
+		Currency:  currency,
+		ChartCode: ChartInterestExpense,
+	})
+	require.NoError(t, err)
+
+	plan, err := testQueries.CreateInterestPlan(context.Background(), CreateInterestPlanParams{
+		Name:             "Saver",
+		Currency:         currency,
+		AnnualRateBps:    365,
+		ExpenseAccountID: expenseAccount.ID,
+	})
+	require.NoError(t, err)
+
+	// an older account getting its plan today
+	_, err = testDb.ExecContext(context.Background(), "UPDATE accounts SET created_at = now() - interval '3 days' WHERE id = $1", account.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.SetAccountInterestPlan(context.Background(), SetAccountInterestPlanParams{
+		AccountID: account.ID,
+		PlanID:    plan.ID,
+	})
+	require.NoError(t, err)
+
+	// the days before the plan have nothing to accrue, a backfill doesn't pay them
+	today := time.Now().UTC()
+	for days := 2; days >= 0; days-- {
+		_, err = store.AccrueInterestTransaction(context.Background(), today.AddDate(0, 0, -days))
+		require.NoError(t, err)
+	}
+
+	accruals, err := testQueries.GetInterestAccruals(context.Background(), GetInterestAccrualsParams{
+		AccountID: account.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 1)
+	require.Equal(t, today.Format(time.DateOnly), accruals[0].Date.Format(time.DateOnly))
+}
@@ -0,0 +1,93 @@
+// Interest on the savings accounts: accrued daily on the end of day balance, posted monthly.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// the accruals are kept in millionths of the minor unit, so the daily amounts don't round to nothing
+const InterestAccrualScale = 1_000_000
+
+// contains the input params to post the accrued interest of an account
+type PostInterestTxParams struct {
+	AccountId int64 `json:"account_id"`
+	PlanId    int64 `json:"plan_id"`
+	// the accruals of the days before are posted, usually the first day of the month
+	Before time.Time `json:"before"`
+}
+
+// contains the output result of a posting, Transfer is nil when nothing was left to post
+type PostInterestTxResult struct {
+	Accruals []InterestAccrual `json:"accruals"`
+	Amount   int64             `json:"amount"`
+	Transfer *TransferTxResult `json:"transfer,omitempty"`
+}
+
+// accrues the interest of every savings account for the given day, accounts already accrued are skipped.
+func (store *SQLStore) AccrueInterestTransaction(ctx context.Context, date time.Time) (int64, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	return store.AccrueInterest(ctx, AccrueInterestParams{
+		Date:     day,
+		EndOfDay: day.AddDate(0, 0, 1),
+	})
+}
+
+// credits the unposted accruals of the account as a single transfer from the plan's expense account.
+// the accruals are locked and marked as posted in the same transaction, so a rerun can't post them twice.
+func (store *SQLStore) PostInterestTransaction(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error) {
+	var res PostInterestTxResult
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		var err error
+
+		res.Accruals, err = q.GetUnpostedAccrualsForUpdate(ctx, GetUnpostedAccrualsForUpdateParams{
+			AccountID: arg.AccountId,
+			PlanID:    arg.PlanId,
+			Before:    arg.Before,
+		})
+		if err != nil || len(res.Accruals) == 0 {
+			return err
+		}
+
+		var total int64
+		ids := make([]int64, 0, len(res.Accruals))
+		for _, accrual := range res.Accruals {
+			total += accrual.Amount
+			ids = append(ids, accrual.ID)
+		}
+		// rounded half up to the minor unit
+		res.Amount = (total + InterestAccrualScale/2) / InterestAccrualScale
+
+		var transferId sql.NullInt64
+		if res.Amount > 0 {
+			plan, err := q.GetInterestPlanById(ctx, arg.PlanId)
+			if err != nil {
+				return err
+			}
+
+			last := res.Accruals[len(res.Accruals)-1].Date
+			result, err := transfer(ctx, q, TransferTxParams{
+				FromAccountId: plan.ExpenseAccountID,
+				ToAccountId:   arg.AccountId,
+				Amount:        res.Amount,
+				Description:   fmt.Sprintf("Interest until %s (%s)", last.Format("2006-01-02"), plan.Name),
+				Category:      "interest",
+			})
+			if err != nil {
+				return err
+			}
+			res.Transfer = &result
+			transferId = sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+		}
+
+		return q.MarkAccrualsPosted(ctx, MarkAccrualsPostedParams{
+			TransferID: transferId,
+			Ids:        ids,
+		})
+	})
+	return res, err
+}
@@ -15,15 +15,17 @@ import (
 
 var testQueries *Queries
 var testDb *sql.DB
+var testConfig utils.Config
 
 func TestMain(m *testing.M) {
-	config, err := utils.ConfigStore("../..", "config", "env")
+	var err error
+	testConfig, err = utils.ConfigStore("../..", "config", "env")
 
 	if err != nil {
 		log.Fatalf("Couldn't load configs, error: %s", err)
 	}
 
-	testDb, err = sql.Open(config.DbDriver, config.DbSource)
+	testDb, err = sql.Open(testConfig.DbDriver, testConfig.DbSource)
 
 	if err != nil {
 		log.Fatalf("Failed to connect to the database : %v", err)
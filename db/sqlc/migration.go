@@ -0,0 +1,22 @@
+package db
+
+import (
+	"context"
+)
+
+// the row golang-migrate keeps the applied version in, dirty when a migration failed half way
+type SchemaMigration struct {
+	Version int64 `json:"version"`
+	Dirty   bool  `json:"dirty"`
+}
+
+// the checks of the readiness probe go to the pool directly, they'd only clutter the traces
+func (store *SQLStore) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+func (store *SQLStore) GetSchemaMigration(ctx context.Context) (SchemaMigration, error) {
+	var migration SchemaMigration
+	err := store.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&migration.Version, &migration.Dirty)
+	return migration, err
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AYehia0/go-bk-mst/db/migrations"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaMigration(t *testing.T) {
+	store := NewStore(testDb)
+	require.NoError(t, store.Ping(context.Background()))
+
+	// the tests run against the fully migrated database
+	migration, err := store.GetSchemaMigration(context.Background())
+	require.NoError(t, err)
+	latest, err := migrations.Latest()
+	require.NoError(t, err)
+	require.Equal(t, int64(latest), migration.Version)
+	require.False(t, migration.Dirty)
+}
@@ -5,24 +5,175 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Account struct {
+	ID               int64     `json:"id"`
+	OwnerName        string    `json:"owner_name"`
+	Balance          int64     `json:"balance"`
+	Currency         string    `json:"currency"`
+	CreatedAt        time.Time `json:"created_at"`
+	AvailableBalance int64     `json:"available_balance"`
+	ChartCode        string    `json:"chart_code"`
+	IsSystem         bool      `json:"is_system"`
+}
+
+type AccountInterestPlan struct {
+	AccountID int64     `json:"account_id"`
+	PlanID    int64     `json:"plan_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BalanceSnapshot struct {
 	ID        int64     `json:"id"`
-	OwnerName string    `json:"owner_name"`
+	AccountID int64     `json:"account_id"`
+	TakenAt   time.Time `json:"taken_at"`
 	Balance   int64     `json:"balance"`
-	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type CashReceipt struct {
+	ID int64 `json:"id"`
+	// deposit or withdrawal
+	Kind       string    `json:"kind"`
+	AccountID  int64     `json:"account_id"`
+	Amount     int64     `json:"amount"`
+	Currency   string    `json:"currency"`
+	Teller     string    `json:"teller"`
+	TransferID int64     `json:"transfer_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type CategoryRule struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	Pattern   string    `json:"pattern"`
+	Category  string    `json:"category"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ChartOfAccount struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
 type Entry struct {
 	ID        int64 `json:"id"`
 	AccountID int64 `json:"account_id"`
 	// Positive or negative
-	Amount    int64     `json:"amount"`
+	Amount      int64     `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+	Description string    `json:"description"`
+	Reference   string    `json:"reference"`
+	Category    string    `json:"category"`
+}
+
+type FeeSchedule struct {
+	ID        int64  `json:"id"`
+	Currency  string `json:"currency"`
+	MinAmount int64  `json:"min_amount"`
+	FlatFee   int64  `json:"flat_fee"`
+	// Basis points, 100 = 1%
+	PercentageBps int64 `json:"percentage_bps"`
+	// The bank owned account credited with the fees
+	FeeAccountID int64     `json:"fee_account_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Hold struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// Positive only!
+	Amount int64 `json:"amount"`
+	// pending, captured, voided or expired
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiredAt  time.Time     `json:"expired_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	// Reserved on top of the amount
+	Fee          int64         `json:"fee"`
+	FeeAccountID sql.NullInt64 `json:"fee_account_id"`
+}
+
+type InterestAccrual struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	PlanID    int64     `json:"plan_id"`
+	Date      time.Time `json:"date"`
+	// End of day balance
+	Balance int64 `json:"balance"`
+	// Millionths of the minor unit
+	Amount   int64        `json:"amount"`
+	PostedAt sql.NullTime `json:"posted_at"`
+	// NULL until posted, or when the posted amount rounds to 0
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type InterestPlan struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	// Basis points a year, 250 = 2.5%
+	AnnualRateBps int64 `json:"annual_rate_bps"`
+	// The bank owned account paying the interest
+	ExpenseAccountID int64     `json:"expense_account_id"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	EventType     string          `json:"event_type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+	PublishedAt   sql.NullTime    `json:"published_at"`
+	Attempts      int32           `json:"attempts"`
+	LastError     string          `json:"last_error"`
+}
+
+type PaymentFile struct {
+	ID        int64  `json:"id"`
+	OwnerName string `json:"owner_name"`
+	Format    string `json:"format"`
+	MessageID string `json:"message_id"`
+	// preview, processing or executed
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PaymentInstruction struct {
+	ID            int64  `json:"id"`
+	FileID        int64  `json:"file_id"`
+	EndToEndID    string `json:"end_to_end_id"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	// valid, invalid, completed or failed
+	Status     string        `json:"status"`
+	Error      string        `json:"error"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type Reversal struct {
+	ID                 int64 `json:"id"`
+	TransferID         int64 `json:"transfer_id"`
+	ReversalTransferID int64 `json:"reversal_transfer_id"`
+	// Positive only!
+	Amount int64  `json:"amount"`
+	Reason string `json:"reason"`
+	// The user who requested the reversal
+	Actor     string    `json:"actor"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -42,8 +193,26 @@ type Transfer struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	// Positive only!
-	Amount    int64     `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	Amount      int64     `json:"amount"`
+	CreatedAt   time.Time `json:"created_at"`
+	Description string    `json:"description"`
+	// End-to-end reference
+	Reference string `json:"reference"`
+	Category  string `json:"category"`
+	Fee       int64  `json:"fee"`
+}
+
+type TransferLimit struct {
+	ID        int64          `json:"id"`
+	Username  sql.NullString `json:"username"`
+	AccountID sql.NullInt64  `json:"account_id"`
+	// Max amount of a single transfer
+	MaxAmount sql.NullInt64 `json:"max_amount"`
+	// Max total sent in the last 24 hours
+	DailyAmount sql.NullInt64 `json:"daily_amount"`
+	// Max number of transfers in the last hour
+	HourlyCount sql.NullInt64 `json:"hourly_count"`
+	CreatedAt   time.Time     `json:"created_at"`
 }
 
 type User struct {
@@ -53,4 +222,30 @@ type User struct {
 	FullName          string    `json:"full_name"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	// depositor, teller or admin
+	Role string `json:"role"`
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode int32           `json:"last_status_code"`
+	LastError      string          `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    sql.NullTime    `json:"delivered_at"`
+}
+
+type WebhookSubscription struct {
+	ID        int64     `json:"id"`
+	OwnerName string    `json:"owner_name"`
+	Url       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
 }
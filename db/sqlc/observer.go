@@ -0,0 +1,33 @@
+package db
+
+// called with every committed transfer, nil until set with ObserveTransfers
+var transferObserver func(currency string, amount int64)
+
+// registers the function counting the transfers once their transaction commits, the metrics of the servers.
+// every transfer is observed, the batches, hold captures, reversals, cash and interest ones included.
+// it's set once at startup, before the store is used
+func ObserveTransfers(observe func(currency string, amount int64)) {
+	transferObserver = observe
+}
+
+// the queries of a transaction, the transfers made are kept to be observed after the commit
+type observedDBTX struct {
+	DBTX
+	transfers []TransferTxResult
+}
+
+// keeps the transfer of the transaction of q, if it's run in one
+func observeTransfer(q *Queries, res TransferTxResult) {
+	if observed, ok := q.db.(*observedDBTX); ok {
+		observed.transfers = append(observed.transfers, res)
+	}
+}
+
+func (observed *observedDBTX) committed() {
+	if transferObserver == nil {
+		return
+	}
+	for _, res := range observed.transfers {
+		transferObserver(res.FromAccount.Currency, res.Transfer.Amount)
+	}
+}
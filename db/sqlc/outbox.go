@@ -0,0 +1,116 @@
+// Domain events, written to the outbox in the same transaction as the change and relayed afterwards.
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	EventTransferCompleted = "transfer.completed"
+	EventAccountCreated    = "account.created"
+	EventUserRegistered    = "user.registered"
+)
+
+const (
+	AggregateTransfer = "transfer"
+	AggregateAccount  = "account"
+	AggregateUser     = "user"
+)
+
+// the payload of user.registered, the user without the password
+type UserRegisteredEvent struct {
+	Username  string    `json:"username"`
+	FullName  string    `json:"full_name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// adds the event to the outbox of the running transaction, it's only published if the transaction commits
+func addEvent(ctx context.Context, q *Queries, eventType, aggregateType, aggregateId string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateId,
+		Payload:       data,
+	})
+	return err
+}
+
+// creates the account and its account.created event
+func (store *SQLStore) CreateAccountTransaction(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, q, EventAccountCreated, AggregateAccount, strconv.FormatInt(account.ID, 10), account)
+	})
+	return account, err
+}
+
+// creates the user and its user.registered event
+func (store *SQLStore) CreateUserTransaction(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return addEvent(ctx, q, EventUserRegistered, AggregateUser, user.Username, UserRegisteredEvent{
+			Username:  user.Username,
+			FullName:  user.FullName,
+			Email:     user.Email,
+			Role:      user.Role,
+			CreatedAt: user.CreatedAt,
+		})
+	})
+	return user, err
+}
+
+// publishes the oldest unpublished events in order, stopping at the first failure so the order is kept.
+// the events stay locked while they're published, so the relays running in parallel don't publish them twice,
+// but an event is published again if the transaction fails after its publish (at least once).
+func (store *SQLStore) RelayOutboxTransaction(ctx context.Context, limit int32, publish func(OutboxEvent) error) (int, error) {
+	published := 0
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		events, err := q.GetUnpublishedOutboxEvents(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err := publish(event); err != nil {
+				// keep the reason, the event is retried on the next run
+				return q.MarkOutboxEventFailed(ctx, MarkOutboxEventFailedParams{
+					ID:        event.ID,
+					LastError: err.Error(),
+				})
+			}
+
+			if err := q.MarkOutboxEventPublished(ctx, event.ID); err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return published, nil
+}
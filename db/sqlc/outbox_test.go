@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func getAggregateEvents(t *testing.T, aggregateType, aggregateId string) []OutboxEvent {
+	events, err := testQueries.GetOutboxEventsByAggregate(context.Background(), GetOutboxEventsByAggregateParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateId,
+	})
+	require.NoError(t, err)
+	return events
+}
+
+func TestCreateAccountTransaction(t *testing.T) {
+	store := NewStore(testDb)
+	user := createRandomUser(t)
+
+	account, err := store.CreateAccountTransaction(context.Background(), CreateAccountParams{
+		OwnerName: user.Username,
+		Currency:  utils.USD,
+	})
+	require.NoError(t, err)
+
+	events := getAggregateEvents(t, AggregateAccount, strconv.FormatInt(account.ID, 10))
+	require.Len(t, events, 1)
+	require.Equal(t, EventAccountCreated, events[0].EventType)
+	require.False(t, events[0].PublishedAt.Valid)
+
+	var payload Account
+	require.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+	require.Equal(t, account.ID, payload.ID)
+	require.Equal(t, account.OwnerName, payload.OwnerName)
+}
+
+func TestCreateUserTransaction(t *testing.T) {
+	store := NewStore(testDb)
+
+	user, err := store.CreateUserTransaction(context.Background(), CreateUserParams{
+		Username: utils.GetRandomOwnerName(),
+		Email:    utils.GetRandomEmail(),
+		Password: "hashed",
+		FullName: utils.GetRandomOwnerName(),
+	})
+	require.NoError(t, err)
+
+	events := getAggregateEvents(t, AggregateUser, user.Username)
+	require.Len(t, events, 1)
+	require.Equal(t, EventUserRegistered, events[0].EventType)
+	// never leak the password hash
+	require.NotContains(t, string(events[0].Payload), "hashed")
+
+	// nothing is written when the user can't be created
+	_, err = store.CreateUserTransaction(context.Background(), CreateUserParams{
+		Username: user.Username,
+		Email:    utils.GetRandomEmail(),
+		Password: "hashed",
+		FullName: user.FullName,
+	})
+	require.Error(t, err)
+	require.Len(t, getAggregateEvents(t, AggregateUser, user.Username), 1)
+}
+
+func TestTransferCompletedEvent(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	res, err := store.TransferTransaction(context.Background(), TransferTxParams{
+		FromAccountId: acc1.ID,
+		ToAccountId:   acc2.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+
+	events := getAggregateEvents(t, AggregateTransfer, strconv.FormatInt(res.Transfer.ID, 10))
+	require.Len(t, events, 1)
+	require.Equal(t, EventTransferCompleted, events[0].EventType)
+
+	var payload Transfer
+	require.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+	require.Equal(t, res.Transfer.ID, payload.ID)
+	require.Equal(t, int64(1), payload.Amount)
+}
+
+func TestRelayOutboxTransaction(t *testing.T) {
+	store := NewStore(testDb)
+	user := createRandomUser(t)
+
+	account, err := store.CreateAccountTransaction(context.Background(), CreateAccountParams{
+		OwnerName: user.Username,
+		Currency:  utils.USD,
+	})
+	require.NoError(t, err)
+	event := getAggregateEvents(t, AggregateAccount, strconv.FormatInt(account.ID, 10))[0]
+
+	// the relay stops at the failed event and retries it later
+	_, err = store.RelayOutboxTransaction(context.Background(), 1000, func(e OutboxEvent) error {
+		if e.ID == event.ID {
+			return errors.New("broker is down")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	failed, err := store.GetOutboxEventById(context.Background(), event.ID)
+	require.NoError(t, err)
+	require.False(t, failed.PublishedAt.Valid)
+	require.Equal(t, int32(1), failed.Attempts)
+	require.Equal(t, "broker is down", failed.LastError)
+
+	// other tests keep adding events, relay until ours is out
+	var published []int64
+	for i := 0; i < 100; i++ {
+		_, err = store.RelayOutboxTransaction(context.Background(), 1000, func(e OutboxEvent) error {
+			published = append(published, e.ID)
+			return nil
+		})
+		require.NoError(t, err)
+
+		relayed, err := store.GetOutboxEventById(context.Background(), event.ID)
+		require.NoError(t, err)
+		if relayed.PublishedAt.Valid {
+			require.Equal(t, int32(2), relayed.Attempts)
+			require.Empty(t, relayed.LastError)
+			break
+		}
+	}
+	require.Contains(t, published, event.ID)
+
+	// in order
+	for i := 1; i < len(published); i++ {
+		require.Less(t, published[i-1], published[i])
+	}
+}
@@ -0,0 +1,239 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: payment_file.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createPaymentFile = `-- name: CreatePaymentFile :one
+INSERT INTO payment_files (
+  owner_name, format, message_id
+) VALUES (
+  $1, $2, $3
+)
+RETURNING id, owner_name, format, message_id, status, created_at
+`
+
+type CreatePaymentFileParams struct {
+	OwnerName string `json:"owner_name"`
+	Format    string `json:"format"`
+	MessageID string `json:"message_id"`
+}
+
+func (q *Queries) CreatePaymentFile(ctx context.Context, arg CreatePaymentFileParams) (PaymentFile, error) {
+	row := q.db.QueryRowContext(ctx, createPaymentFile, arg.OwnerName, arg.Format, arg.MessageID)
+	var i PaymentFile
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerName,
+		&i.Format,
+		&i.MessageID,
+		&i.Status,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createPaymentInstruction = `-- name: CreatePaymentInstruction :one
+INSERT INTO payment_instructions (
+  file_id, end_to_end_id, from_account_id, to_account_id, amount, currency, status, error
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING id, file_id, end_to_end_id, from_account_id, to_account_id, amount, currency, status, error, transfer_id, created_at
+`
+
+type CreatePaymentInstructionParams struct {
+	FileID        int64  `json:"file_id"`
+	EndToEndID    string `json:"end_to_end_id"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	Status        string `json:"status"`
+	Error         string `json:"error"`
+}
+
+func (q *Queries) CreatePaymentInstruction(ctx context.Context, arg CreatePaymentInstructionParams) (PaymentInstruction, error) {
+	row := q.db.QueryRowContext(ctx, createPaymentInstruction,
+		arg.FileID,
+		arg.EndToEndID,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Currency,
+		arg.Status,
+		arg.Error,
+	)
+	var i PaymentInstruction
+	err := row.Scan(
+		&i.ID,
+		&i.FileID,
+		&i.EndToEndID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Status,
+		&i.Error,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPaymentFileById = `-- name: GetPaymentFileById :one
+SELECT id, owner_name, format, message_id, status, created_at FROM payment_files
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetPaymentFileById(ctx context.Context, id int64) (PaymentFile, error) {
+	row := q.db.QueryRowContext(ctx, getPaymentFileById, id)
+	var i PaymentFile
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerName,
+		&i.Format,
+		&i.MessageID,
+		&i.Status,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPaymentInstructionForUpdate = `-- name: GetPaymentInstructionForUpdate :one
+SELECT id, file_id, end_to_end_id, from_account_id, to_account_id, amount, currency, status, error, transfer_id, created_at FROM payment_instructions
+WHERE id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetPaymentInstructionForUpdate(ctx context.Context, id int64) (PaymentInstruction, error) {
+	row := q.db.QueryRowContext(ctx, getPaymentInstructionForUpdate, id)
+	var i PaymentInstruction
+	err := row.Scan(
+		&i.ID,
+		&i.FileID,
+		&i.EndToEndID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Status,
+		&i.Error,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPaymentInstructions = `-- name: GetPaymentInstructions :many
+SELECT id, file_id, end_to_end_id, from_account_id, to_account_id, amount, currency, status, error, transfer_id, created_at FROM payment_instructions
+WHERE file_id = $1
+ORDER BY id
+`
+
+func (q *Queries) GetPaymentInstructions(ctx context.Context, fileID int64) ([]PaymentInstruction, error) {
+	rows, err := q.db.QueryContext(ctx, getPaymentInstructions, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PaymentInstruction{}
+	for rows.Next() {
+		var i PaymentInstruction
+		if err := rows.Scan(
+			&i.ID,
+			&i.FileID,
+			&i.EndToEndID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Currency,
+			&i.Status,
+			&i.Error,
+			&i.TransferID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePaymentFileStatus = `-- name: UpdatePaymentFileStatus :one
+UPDATE payment_files
+SET status = $1
+WHERE id = $2 AND status = $3
+RETURNING id, owner_name, format, message_id, status, created_at
+`
+
+type UpdatePaymentFileStatusParams struct {
+	Status     string `json:"status"`
+	ID         int64  `json:"id"`
+	FromStatus string `json:"from_status"`
+}
+
+func (q *Queries) UpdatePaymentFileStatus(ctx context.Context, arg UpdatePaymentFileStatusParams) (PaymentFile, error) {
+	row := q.db.QueryRowContext(ctx, updatePaymentFileStatus, arg.Status, arg.ID, arg.FromStatus)
+	var i PaymentFile
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerName,
+		&i.Format,
+		&i.MessageID,
+		&i.Status,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updatePaymentInstruction = `-- name: UpdatePaymentInstruction :one
+UPDATE payment_instructions
+SET status = $2, error = $3, transfer_id = $4
+WHERE id = $1 AND status = 'valid'
+RETURNING id, file_id, end_to_end_id, from_account_id, to_account_id, amount, currency, status, error, transfer_id, created_at
+`
+
+type UpdatePaymentInstructionParams struct {
+	ID         int64         `json:"id"`
+	Status     string        `json:"status"`
+	Error      string        `json:"error"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+}
+
+// only a valid instruction is executed, once
+func (q *Queries) UpdatePaymentInstruction(ctx context.Context, arg UpdatePaymentInstructionParams) (PaymentInstruction, error) {
+	row := q.db.QueryRowContext(ctx, updatePaymentInstruction,
+		arg.ID,
+		arg.Status,
+		arg.Error,
+		arg.TransferID,
+	)
+	var i PaymentInstruction
+	err := row.Scan(
+		&i.ID,
+		&i.FileID,
+		&i.EndToEndID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Status,
+		&i.Error,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
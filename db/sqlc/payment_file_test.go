@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePaymentFileTransaction(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	arg := CreatePaymentFileTxParams{
+		CreatePaymentFileParams: CreatePaymentFileParams{
+			OwnerName: acc1.OwnerName,
+			Format:    "csv",
+		},
+		Instructions: []CreatePaymentInstructionParams{
+			{EndToEndID: "1", FromAccountID: acc1.ID, ToAccountID: acc2.ID, Amount: 10, Currency: acc1.Currency, Status: PaymentInstructionStatusValid},
+			{EndToEndID: "2", FromAccountID: acc1.ID, ToAccountID: -1, Amount: 10, Currency: acc1.Currency, Status: PaymentInstructionStatusInvalid, Error: "not found"},
+		},
+	}
+
+	res, err := store.CreatePaymentFileTransaction(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, PaymentFileStatusPreview, res.File.Status)
+	require.Len(t, res.Instructions, 2)
+
+	instructions, err := store.GetPaymentInstructions(context.Background(), res.File.ID)
+	require.NoError(t, err)
+	require.Len(t, instructions, 2)
+	require.Equal(t, "not found", instructions[1].Error)
+
+	// only one caller moves the file out of the preview
+	_, err = store.UpdatePaymentFileStatus(context.Background(), UpdatePaymentFileStatusParams{
+		ID:         res.File.ID,
+		FromStatus: PaymentFileStatusPreview,
+		Status:     PaymentFileStatusProcessing,
+	})
+	require.NoError(t, err)
+
+	_, err = store.UpdatePaymentFileStatus(context.Background(), UpdatePaymentFileStatusParams{
+		ID:         res.File.ID,
+		FromStatus: PaymentFileStatusPreview,
+		Status:     PaymentFileStatusProcessing,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestExecutePaymentInstructionTransaction(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	res, err := store.CreatePaymentFileTransaction(context.Background(), CreatePaymentFileTxParams{
+		CreatePaymentFileParams: CreatePaymentFileParams{
+			OwnerName: acc1.OwnerName,
+			Format:    "csv",
+		},
+		Instructions: []CreatePaymentInstructionParams{
+			{EndToEndID: "1", FromAccountID: acc1.ID, ToAccountID: acc2.ID, Amount: 10, Currency: acc1.Currency, Status: PaymentInstructionStatusValid},
+			{EndToEndID: "2", FromAccountID: acc1.ID, ToAccountID: acc2.ID, Amount: acc1.Balance + 1, Currency: acc1.Currency, Status: PaymentInstructionStatusValid},
+		},
+	})
+	require.NoError(t, err)
+
+	// the transfer and the instruction are committed together
+	instruction, err := store.ExecutePaymentInstructionTransaction(context.Background(), res.Instructions[0].ID, nil)
+	require.NoError(t, err)
+	require.Equal(t, PaymentInstructionStatusCompleted, instruction.Status)
+	require.True(t, instruction.TransferID.Valid)
+
+	transfer, err := store.GetTransferById(context.Background(), instruction.TransferID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, "1", transfer.Reference)
+	require.Equal(t, int64(10), transfer.Amount)
+
+	// a resume doesn't pay it twice
+	_, err = store.ExecutePaymentInstructionTransaction(context.Background(), res.Instructions[0].ID, nil)
+	require.ErrorIs(t, err, ErrPaymentInstructionNotValid)
+
+	// a failed transfer leaves the instruction valid
+	_, err = store.ExecutePaymentInstructionTransaction(context.Background(), res.Instructions[1].ID, nil)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	instructions, err := store.GetPaymentInstructions(context.Background(), res.File.ID)
+	require.NoError(t, err)
+	require.Equal(t, PaymentInstructionStatusValid, instructions[1].Status)
+	require.False(t, instructions[1].TransferID.Valid)
+}
@@ -0,0 +1,98 @@
+// Storing uploaded bulk payment files, so they can be previewed before they are executed.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+const (
+	PaymentFileStatusPreview    = "preview"
+	PaymentFileStatusProcessing = "processing"
+	PaymentFileStatusExecuted   = "executed"
+
+	PaymentInstructionStatusValid     = "valid"
+	PaymentInstructionStatusInvalid   = "invalid"
+	PaymentInstructionStatusCompleted = "completed"
+	PaymentInstructionStatusFailed    = "failed"
+)
+
+var ErrPaymentInstructionNotValid = errors.New("Payment instruction is no longer valid")
+
+// contains the input params to store a payment file, the FileID of the instructions is filled in by the transaction
+type CreatePaymentFileTxParams struct {
+	CreatePaymentFileParams
+	Instructions []CreatePaymentInstructionParams `json:"instructions"`
+}
+
+type PaymentFileTxResult struct {
+	File         PaymentFile          `json:"file"`
+	Instructions []PaymentInstruction `json:"instructions"`
+}
+
+// stores the file with all its instructions or nothing
+func (store *SQLStore) CreatePaymentFileTransaction(ctx context.Context, arg CreatePaymentFileTxParams) (PaymentFileTxResult, error) {
+	var res PaymentFileTxResult
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		var err error
+
+		res.File, err = q.CreatePaymentFile(ctx, arg.CreatePaymentFileParams)
+		if err != nil {
+			return err
+		}
+
+		res.Instructions = make([]PaymentInstruction, 0, len(arg.Instructions))
+		for _, instruction := range arg.Instructions {
+			instruction.FileID = res.File.ID
+
+			created, err := q.CreatePaymentInstruction(ctx, instruction)
+			if err != nil {
+				return err
+			}
+			res.Instructions = append(res.Instructions, created)
+		}
+		return nil
+	})
+	return res, err
+}
+
+// transfers the amount of a valid instruction and marks it completed in the same transaction, so a file can be
+// resumed after a crash without paying an instruction twice. the instruction is locked, a concurrent resume of
+// the same file waits and gets ErrPaymentInstructionNotValid.
+func (store *SQLStore) ExecutePaymentInstructionTransaction(ctx context.Context, instructionId int64, limits *TransferLimits) (PaymentInstruction, error) {
+	var res PaymentInstruction
+	var transferRes TransferTxResult
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		instruction, err := q.GetPaymentInstructionForUpdate(ctx, instructionId)
+		if err != nil {
+			return err
+		}
+
+		if instruction.Status != PaymentInstructionStatusValid {
+			return ErrPaymentInstructionNotValid
+		}
+
+		transferRes, err = transfer(ctx, q, TransferTxParams{
+			FromAccountId: instruction.FromAccountID,
+			ToAccountId:   instruction.ToAccountID,
+			Amount:        instruction.Amount,
+			Reference:     instruction.EndToEndID,
+			Limits:        limits,
+			ChargeFee:     true,
+		})
+		if err != nil {
+			return err
+		}
+
+		res, err = q.UpdatePaymentInstruction(ctx, UpdatePaymentInstructionParams{
+			ID:         instruction.ID,
+			Status:     PaymentInstructionStatusCompleted,
+			TransferID: sql.NullInt64{Int64: transferRes.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+	return res, err
+}
@@ -6,28 +6,113 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	// the end of day balance is the current one minus whatever was booked after the day ended,
+	// the accounts already accrued for the day are skipped, so are the ones which got their plan after the day ended.
+	AccrueInterest(ctx context.Context, arg AccrueInterestParams) (int64, error)
+	AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	// leases the due deliveries by pushing their next attempt back, another dispatcher won't pick them
+	// until the lease is over, even if this one dies in the middle.
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]WebhookDelivery, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	// the entries created after taken_at are taken out of the current balance, the accounts already snapshotted are skipped
+	CreateBalanceSnapshots(ctx context.Context, takenAt time.Time) (int64, error)
+	CreateCashReceipt(ctx context.Context, arg CreateCashReceiptParams) (CashReceipt, error)
+	CreateCategoryRule(ctx context.Context, arg CreateCategoryRuleParams) (CategoryRule, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFeeSchedule(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateInterestPlan(ctx context.Context, arg CreateInterestPlanParams) (InterestPlan, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error)
+	CreatePaymentFile(ctx context.Context, arg CreatePaymentFileParams) (PaymentFile, error)
+	CreatePaymentInstruction(ctx context.Context, arg CreatePaymentInstructionParams) (PaymentInstruction, error)
+	CreateReversal(ctx context.Context, arg CreateReversalParams) (Reversal, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateSystemAccount(ctx context.Context, arg CreateSystemAccountParams) (Account, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteCategoryRule(ctx context.Context, arg DeleteCategoryRuleParams) (int64, error)
+	DeleteFeeSchedule(ctx context.Context, id int64) error
+	DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (int64, error)
 	GetAccountById(ctx context.Context, id int64) (Account, error)
 	GetAccountByIdForUpdate(ctx context.Context, id int64) (Account, error)
 	GetAccounts(ctx context.Context, arg GetAccountsParams) ([]Account, error)
+	// the subscriptions to the event of the owners of the accounts
+	GetAccountsWebhookSubscriptions(ctx context.Context, arg GetAccountsWebhookSubscriptionsParams) ([]WebhookSubscription, error)
+	GetCashReceiptById(ctx context.Context, id int64) (CashReceipt, error)
+	GetCategoryRules(ctx context.Context, username string) ([]CategoryRule, error)
+	GetChartOfAccounts(ctx context.Context) ([]ChartOfAccount, error)
 	GetEntries(ctx context.Context, arg GetEntriesParams) ([]Entry, error)
+	// from is exclusive and to is inclusive, same as the balances at those times
+	GetEntriesBetween(ctx context.Context, arg GetEntriesBetweenParams) ([]Entry, error)
 	GetEntryById(ctx context.Context, id int64) (Entry, error)
+	GetExpiredHoldsForUpdate(ctx context.Context, limit int32) ([]Hold, error)
+	// the highest tier the amount reaches
+	GetFeeSchedule(ctx context.Context, arg GetFeeScheduleParams) (FeeSchedule, error)
+	GetFeeSchedules(ctx context.Context, currency string) ([]FeeSchedule, error)
+	GetHoldById(ctx context.Context, id int64) (Hold, error)
+	GetHoldByIdForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetInterestAccruals(ctx context.Context, arg GetInterestAccrualsParams) ([]InterestAccrual, error)
+	GetInterestPlanById(ctx context.Context, id int64) (InterestPlan, error)
+	GetInterestPlans(ctx context.Context, currency string) ([]InterestPlan, error)
+	GetLatestBalanceSnapshot(ctx context.Context, arg GetLatestBalanceSnapshotParams) (BalanceSnapshot, error)
+	GetOutboxEventById(ctx context.Context, id int64) (OutboxEvent, error)
+	GetOutboxEventsByAggregate(ctx context.Context, arg GetOutboxEventsByAggregateParams) ([]OutboxEvent, error)
+	// the pending holds are counted too, they become transfers once captured
+	GetOutgoingTransferStats(ctx context.Context, arg GetOutgoingTransferStatsParams) (GetOutgoingTransferStatsRow, error)
+	GetPaymentFileById(ctx context.Context, id int64) (PaymentFile, error)
+	GetPaymentInstructionForUpdate(ctx context.Context, id int64) (PaymentInstruction, error)
+	GetPaymentInstructions(ctx context.Context, fileID int64) ([]PaymentInstruction, error)
+	GetReversals(ctx context.Context, transferID int64) ([]Reversal, error)
+	GetReversedAmount(ctx context.Context, transferID int64) (int64, error)
 	GetSessionById(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error)
+	GetSystemAccounts(ctx context.Context) ([]Account, error)
 	GetTransferById(ctx context.Context, id int64) (Transfer, error)
+	GetTransferByIdForUpdate(ctx context.Context, id int64) (Transfer, error)
+	// the user's override comes first, so the account's one is applied on top of it
+	GetTransferLimitOverrides(ctx context.Context, arg GetTransferLimitOverridesParams) ([]TransferLimit, error)
 	GetTransfers(ctx context.Context, arg GetTransfersParams) ([]Transfer, error)
+	GetUnpostedAccrualsForUpdate(ctx context.Context, arg GetUnpostedAccrualsForUpdateParams) ([]InterestAccrual, error)
+	GetUnpostedInterest(ctx context.Context, before time.Time) ([]GetUnpostedInterestRow, error)
+	// locked until the relay's transaction is done, another relay skips them instead of publishing them twice
+	GetUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
-	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	GetWebhookDeliveries(ctx context.Context, arg GetWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	GetWebhookDeliveryById(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookSubscriptionById(ctx context.Context, id int64) (WebhookSubscription, error)
+	GetWebhookSubscriptions(ctx context.Context, ownerName string) ([]WebhookSubscription, error)
+	MarkAccrualsPosted(ctx context.Context, arg MarkAccrualsPostedParams) error
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	// delivered to the listeners when the transaction commits, dropped if it rolls back
+	NotifyAccountActivity(ctx context.Context, arg NotifyAccountActivityParams) error
+	ReplayWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	// the empty query/category match everything, the wildcards of the query are matched as they are
+	SearchEntries(ctx context.Context, arg SearchEntriesParams) ([]Entry, error)
+	// the empty query/category match everything, the wildcards of the query are matched as they are
+	SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]Transfer, error)
+	SetAccountInterestPlan(ctx context.Context, arg SetAccountInterestPlanParams) (AccountInterestPlan, error)
+	SetAccountTransferLimit(ctx context.Context, arg SetAccountTransferLimitParams) (TransferLimit, error)
+	SetUserTransferLimit(ctx context.Context, arg SetUserTransferLimitParams) (TransferLimit, error)
+	SumEntriesAfter(ctx context.Context, arg SumEntriesAfterParams) (int64, error)
+	// from is exclusive, to is inclusive
+	SumEntriesBetween(ctx context.Context, arg SumEntriesBetweenParams) (int64, error)
+	UpdateHoldStatus(ctx context.Context, arg UpdateHoldStatusParams) (Hold, error)
+	UpdatePaymentFileStatus(ctx context.Context, arg UpdatePaymentFileStatusParams) (PaymentFile, error)
+	// only a valid instruction is executed, once
+	UpdatePaymentInstruction(ctx context.Context, arg UpdatePaymentInstructionParams) (PaymentInstruction, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpdateWebhookDeliveryAttempt(ctx context.Context, arg UpdateWebhookDeliveryAttemptParams) (WebhookDelivery, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,97 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: reversal.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createReversal = `-- name: CreateReversal :one
+INSERT INTO reversals (
+  transfer_id, reversal_transfer_id, amount, reason, actor
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING id, transfer_id, reversal_transfer_id, amount, reason, actor, created_at
+`
+
+type CreateReversalParams struct {
+	TransferID         int64  `json:"transfer_id"`
+	ReversalTransferID int64  `json:"reversal_transfer_id"`
+	Amount             int64  `json:"amount"`
+	Reason             string `json:"reason"`
+	Actor              string `json:"actor"`
+}
+
+func (q *Queries) CreateReversal(ctx context.Context, arg CreateReversalParams) (Reversal, error) {
+	row := q.db.QueryRowContext(ctx, createReversal,
+		arg.TransferID,
+		arg.ReversalTransferID,
+		arg.Amount,
+		arg.Reason,
+		arg.Actor,
+	)
+	var i Reversal
+	err := row.Scan(
+		&i.ID,
+		&i.TransferID,
+		&i.ReversalTransferID,
+		&i.Amount,
+		&i.Reason,
+		&i.Actor,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getReversals = `-- name: GetReversals :many
+SELECT id, transfer_id, reversal_transfer_id, amount, reason, actor, created_at FROM reversals
+WHERE transfer_id = $1
+ORDER BY id
+`
+
+func (q *Queries) GetReversals(ctx context.Context, transferID int64) ([]Reversal, error) {
+	rows, err := q.db.QueryContext(ctx, getReversals, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Reversal{}
+	for rows.Next() {
+		var i Reversal
+		if err := rows.Scan(
+			&i.ID,
+			&i.TransferID,
+			&i.ReversalTransferID,
+			&i.Amount,
+			&i.Reason,
+			&i.Actor,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReversedAmount = `-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM reversals
+WHERE transfer_id = $1
+`
+
+func (q *Queries) GetReversedAmount(ctx context.Context, transferID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getReversedAmount, transferID)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseTransferTransaction(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+	user := createRandomUser(t)
+
+	amount := int64(50)
+	original, err := store.TransferTransaction(context.Background(), TransferTxParams{
+		FromAccountId: acc1.ID,
+		ToAccountId:   acc2.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+
+	// partial reversal
+	res, err := store.ReverseTransferTransaction(context.Background(), ReverseTransferTxParams{
+		TransferId: original.Transfer.ID,
+		Amount:     20,
+		Reason:     "partial refund",
+		Actor:      user.Username,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, original.Transfer.ID, res.Reversal.TransferID)
+	require.Equal(t, res.Transfer.ID, res.Reversal.ReversalTransferID)
+	require.Equal(t, int64(20), res.Reversal.Amount)
+	require.Equal(t, "partial refund", res.Reversal.Reason)
+	require.Equal(t, user.Username, res.Reversal.Actor)
+
+	// the compensating transfer goes the other way
+	require.Equal(t, acc2.ID, res.Transfer.FromAccountID)
+	require.Equal(t, acc1.ID, res.Transfer.ToAccountID)
+	require.Equal(t, int64(-20), res.FromEntry.Amount)
+	require.Equal(t, int64(20), res.ToEntry.Amount)
+
+	// more than what's left
+	_, err = store.ReverseTransferTransaction(context.Background(), ReverseTransferTxParams{
+		TransferId: original.Transfer.ID,
+		Amount:     amount,
+		Reason:     "too much",
+		Actor:      user.Username,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	// the rest
+	res, err = store.ReverseTransferTransaction(context.Background(), ReverseTransferTxParams{
+		TransferId: original.Transfer.ID,
+		Reason:     "full refund",
+		Actor:      user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(30), res.Reversal.Amount)
+
+	reversals, err := store.GetReversals(context.Background(), original.Transfer.ID)
+	require.NoError(t, err)
+	require.Len(t, reversals, 2)
+
+	// nothing left to reverse
+	_, err = store.ReverseTransferTransaction(context.Background(), ReverseTransferTxParams{
+		TransferId: original.Transfer.ID,
+		Reason:     "again",
+		Actor:      user.Username,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	// balances are back to where they were
+	updatedAcc1, err := store.GetAccountById(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	updatedAcc2, err := store.GetAccountById(context.Background(), acc2.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, acc1.Balance, updatedAcc1.Balance)
+	require.Equal(t, acc2.Balance, updatedAcc2.Balance)
+}
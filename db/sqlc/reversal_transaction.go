@@ -0,0 +1,75 @@
+// Undoing committed transfers by creating a compensating transfer linked to the original one.
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+var ErrReversalExceedsTransfer = newStatusError(http.StatusUnprocessableEntity, codeUnprocessable, "Reversal amount exceeds the remaining amount of the transfer")
+
+// contains the input params to reverse a transfer.
+// a zero amount reverses whatever is left from the original transfer.
+type ReverseTransferTxParams struct {
+	TransferId int64  `json:"transfer_id"`
+	Amount     int64  `json:"amount"`
+	Reason     string `json:"reason"`
+	Actor      string `json:"actor"`
+}
+
+// contains the output result of a successful reversal
+type ReverseTransferTxResult struct {
+	Reversal Reversal `json:"reversal"`
+	TransferTxResult
+}
+
+func (store *SQLStore) ReverseTransferTransaction(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var res ReverseTransferTxResult
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		// lock the original transfer, so concurrent reversals can't exceed its amount
+		original, err := q.GetTransferByIdForUpdate(ctx, arg.TransferId)
+		if err != nil {
+			return err
+		}
+
+		reversed, err := q.GetReversedAmount(ctx, original.ID)
+		if err != nil {
+			return err
+		}
+
+		remaining := original.Amount - reversed
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+
+		if amount <= 0 || amount > remaining {
+			return ErrReversalExceedsTransfer
+		}
+
+		// the money goes back the other way
+		res.TransferTxResult, err = transfer(ctx, q, TransferTxParams{
+			FromAccountId: original.ToAccountID,
+			ToAccountId:   original.FromAccountID,
+			Amount:        amount,
+			Description:   fmt.Sprintf("Reversal of transfer %d: %s", original.ID, arg.Reason),
+			Reference:     original.Reference,
+			Category:      original.Category,
+		})
+		if err != nil {
+			return err
+		}
+
+		res.Reversal, err = q.CreateReversal(ctx, CreateReversalParams{
+			TransferID:         original.ID,
+			ReversalTransferID: res.Transfer.ID,
+			Amount:             amount,
+			Reason:             arg.Reason,
+			Actor:              arg.Actor,
+		})
+		return err
+	})
+	return res, err
+}
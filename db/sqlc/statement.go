@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+)
+
+// the key of the cancel of the statement context, see tracedDBTX.statementContext
+type statementCancelKey struct{}
+
+// opens the pool with its connections wrapped, the deadline of the statement timeout is released
+// once the rows of the query are closed, the sql.Rows of the DBTX can't be wrapped themselves.
+// the drivers without a connector, or connections missing the context interfaces, are used as is
+// and the deadline is released once it passes.
+func openPool(driverName, dataSource string) (*sql.DB, error) {
+	pool, err := sql.Open(driverName, dataSource)
+	if err != nil {
+		return nil, err
+	}
+
+	driverContext, ok := pool.Driver().(driver.DriverContext)
+	if !ok {
+		return pool, nil
+	}
+	// nothing was opened yet, it's only needed for the driver
+	pool.Close()
+
+	connector, err := driverContext.OpenConnector(dataSource)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(statementConnector{connector}), nil
+}
+
+type statementConnector struct {
+	driver.Connector
+}
+
+func (c statementConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if conn, ok := conn.(contextConn); ok {
+		return statementConn{conn}, nil
+	}
+	return conn, nil
+}
+
+// the interfaces database/sql uses the pq connections through, they're all kept by the wrapper
+type contextConn interface {
+	driver.Conn
+	driver.QueryerContext
+	driver.ExecerContext
+	driver.ConnPrepareContext
+	driver.ConnBeginTx
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+type statementConn struct {
+	contextConn
+}
+
+func (c statementConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.contextConn.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if cancel, ok := ctx.Value(statementCancelKey{}).(context.CancelFunc); ok {
+		return &statementRows{Rows: rows, cancel: cancel}, nil
+	}
+	return rows, nil
+}
+
+type statementRows struct {
+	driver.Rows
+	cancel context.CancelFunc
+}
+
+// called by database/sql once the rows are read, or by sql.Row after the Scan
+func (r *statementRows) Close() error {
+	err := r.Rows.Close()
+	r.cancel()
+	return err
+}
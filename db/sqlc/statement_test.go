@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// a driver answering every query with a single row, it keeps the context of the last one
+type fakeDriver struct {
+	queryCtx chan context.Context
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+func (d *fakeDriver) OpenConnector(string) (driver.Connector, error) { return fakeConnector{d}, nil }
+
+type fakeConnector struct{ d *fakeDriver }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return &fakeConn{c.d}, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return c.d }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+func (c *fakeConn) PrepareContext(context.Context, string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return nil, driver.ErrSkip
+}
+func (c *fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+func (c *fakeConn) Ping(context.Context) error         { return nil }
+func (c *fakeConn) ResetSession(context.Context) error { return nil }
+func (c *fakeConn) IsValid() bool                      { return true }
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.queryCtx <- ctx
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct{ read bool }
+
+func (r *fakeRows) Columns() []string { return []string{"id"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = int64(1)
+	return nil
+}
+
+func TestStatementContextCanceledWithRows(t *testing.T) {
+	fake := &fakeDriver{queryCtx: make(chan context.Context, 1)}
+	sql.Register("fake-statement", fake)
+
+	pool, err := openPool("fake-statement", "")
+	require.NoError(t, err)
+	defer pool.Close()
+
+	db := newTracedDBTX(pool, nil)
+	ctx := WithTimeouts(context.Background(), Timeouts{Statement: time.Minute})
+
+	// the deadline covers the reading of the rows
+	rows, err := db.QueryContext(ctx, "SELECT id")
+	require.NoError(t, err)
+	queryCtx := <-fake.queryCtx
+	require.NoError(t, queryCtx.Err())
+
+	require.True(t, rows.Next())
+	require.NoError(t, queryCtx.Err())
+	require.NoError(t, rows.Close())
+	require.ErrorIs(t, queryCtx.Err(), context.Canceled)
+
+	// and the scan of the row
+	var id int64
+	row := db.QueryRowContext(ctx, "SELECT id")
+	queryCtx = <-fake.queryCtx
+	require.NoError(t, queryCtx.Err())
+
+	require.NoError(t, row.Scan(&id))
+	require.Equal(t, int64(1), id)
+	require.ErrorIs(t, queryCtx.Err(), context.Canceled)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -133,3 +134,95 @@ func TestTransferTransactionDeadLock(t *testing.T) {
 	require.Equal(t, acc1.Balance, updatedAcc1.Balance)
 	require.Equal(t, acc2.Balance, updatedAcc2.Balance)
 }
+
+// concurrent batches touching the same accounts in different orders shouldn't deadlock
+func TestBatchTransferTransaction(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+	acc3 := createRandomAccount(t)
+
+	errs := make(chan error)
+
+	amount := int64(10)
+	numConcurrent := 10
+	for i := 0; i < numConcurrent; i++ {
+		batch := []TransferTxParams{
+			{FromAccountId: acc1.ID, ToAccountId: acc2.ID, Amount: amount},
+			{FromAccountId: acc2.ID, ToAccountId: acc3.ID, Amount: amount},
+			{FromAccountId: acc3.ID, ToAccountId: acc1.ID, Amount: amount},
+		}
+		if i%2 == 1 {
+			batch = []TransferTxParams{
+				{FromAccountId: acc3.ID, ToAccountId: acc2.ID, Amount: amount},
+				{FromAccountId: acc2.ID, ToAccountId: acc1.ID, Amount: amount},
+				{FromAccountId: acc1.ID, ToAccountId: acc3.ID, Amount: amount},
+			}
+		}
+
+		go func() {
+			results, err := store.BatchTransferTransaction(context.Background(), batch)
+			if err == nil && len(results) != len(batch) {
+				err = fmt.Errorf("expected %d results, got %d", len(batch), len(results))
+			}
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < numConcurrent; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	// every batch is a cycle, the balances shouldn't change
+	for _, acc := range []Account{acc1, acc2, acc3} {
+		updated, err := store.GetAccountById(context.Background(), acc.ID)
+		require.NoError(t, err)
+		require.Equal(t, acc.Balance, updated.Balance)
+	}
+}
+
+func TestBatchTransferTransactionRollback(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	_, err := store.BatchTransferTransaction(context.Background(), []TransferTxParams{
+		{FromAccountId: acc1.ID, ToAccountId: acc2.ID, Amount: 10},
+		// doesn't exist
+		{FromAccountId: acc1.ID, ToAccountId: -1, Amount: 10},
+	})
+	require.Error(t, err)
+
+	updated, err := store.GetAccountById(context.Background(), acc1.ID)
+	require.NoError(t, err)
+	require.Equal(t, acc1.Balance, updated.Balance)
+}
+
+// the transfers are observed once their transaction commits, every one of a batch and none of a rolled back one
+func TestObserveTransfers(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	var amounts []int64
+	ObserveTransfers(func(currency string, amount int64) {
+		require.Equal(t, acc1.Currency, currency)
+		amounts = append(amounts, amount)
+	})
+	t.Cleanup(func() { ObserveTransfers(nil) })
+
+	_, err := store.BatchTransferTransaction(context.Background(), []TransferTxParams{
+		{FromAccountId: acc1.ID, ToAccountId: acc2.ID, Amount: 10},
+		{FromAccountId: acc2.ID, ToAccountId: acc1.ID, Amount: 5},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{10, 5}, amounts)
+
+	_, err = store.BatchTransferTransaction(context.Background(), []TransferTxParams{
+		{FromAccountId: acc1.ID, ToAccountId: acc2.ID, Amount: 10},
+		// doesn't exist
+		{FromAccountId: acc1.ID, ToAccountId: -1, Amount: 10},
+	})
+	require.Error(t, err)
+	require.Equal(t, []int64{10, 5}, amounts)
+}
@@ -0,0 +1,21 @@
+// The bank's own accounts, booked under the chart of accounts and owned by the system user.
+package db
+
+// nobody can sign up with it (not alphanumeric) nor log in (no valid password hash)
+const SystemUsername = "_bank"
+
+// the codes of the chart of accounts seeded by the migrations
+const (
+	ChartCash             = "1000"
+	ChartCustomerDeposits = "2000"
+	ChartFeeIncome        = "4000"
+	ChartFXGains          = "4100"
+	ChartInterestExpense  = "5000"
+)
+
+const (
+	ChartTypeAsset     = "asset"
+	ChartTypeLiability = "liability"
+	ChartTypeIncome    = "income"
+	ChartTypeExpense   = "expense"
+)
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeededSystemAccounts(t *testing.T) {
+	chart, err := testQueries.GetChartOfAccounts(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, chart)
+
+	for _, currency := range []string{utils.USD, utils.EUR, utils.EGP, utils.CAD} {
+		for _, code := range []string{ChartCash, ChartFeeIncome, ChartFXGains, ChartInterestExpense} {
+			account, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+				ChartCode: code,
+				Currency:  currency,
+			})
+			require.NoError(t, err)
+			require.True(t, account.IsSystem)
+			require.Equal(t, SystemUsername, account.OwnerName)
+		}
+	}
+
+	// the customers' accounts are deposits
+	account := createRandomAccount(t)
+	require.False(t, account.IsSystem)
+	require.Equal(t, ChartCustomerDeposits, account.ChartCode)
+}
+
+func TestSystemAccountAsCounterparty(t *testing.T) {
+	store := NewStore(testDb)
+
+	currency := strings.ToUpper(utils.RandomString(3))
+	cash, err := testQueries.CreateSystemAccount(context.Background(), CreateSystemAccountParams{
+		Currency:  currency,
+		ChartCode: ChartCash,
+	})
+	require.NoError(t, err)
+
+	// a single system account per chart code and currency
+	_, err = testQueries.CreateSystemAccount(context.Background(), CreateSystemAccountParams{
+		Currency:  currency,
+		ChartCode: ChartCash,
+	})
+	require.Error(t, err)
+
+	account := createAccountWithCurrency(t, currency)
+
+	res, err := store.TransferTransaction(context.Background(), TransferTxParams{
+		FromAccountId: cash.ID,
+		ToAccountId:   account.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-10), res.FromAccount.Balance)
+	require.Equal(t, account.Balance+10, res.ToAccount.Balance)
+}
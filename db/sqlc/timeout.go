@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// the bounds of the queries made with a context, 0 means unbounded.
+// a query running longer than Statement is canceled by postgres, so is one waiting longer than Lock
+// for the row locks of another transaction.
+type Timeouts struct {
+	Statement time.Duration
+	Lock      time.Duration
+}
+
+type timeoutsKey struct{}
+
+// the queries of the store made with the returned context are bound by the timeouts,
+// the servers set the DB_STATEMENT_TIMEOUT and DB_LOCK_TIMEOUT on every request
+func WithTimeouts(ctx context.Context, timeouts Timeouts) context.Context {
+	return context.WithValue(ctx, timeoutsKey{}, timeouts)
+}
+
+// the zero Timeouts when the context has none
+func ContextTimeouts(ctx context.Context) Timeouts {
+	timeouts, _ := ctx.Value(timeoutsKey{}).(Timeouts)
+	return timeouts
+}
+
+// the SET LOCAL of the timeouts, scoped to the transaction so the pooled connection is left as it was.
+// empty when there are none
+func (timeouts Timeouts) setLocal() string {
+	var query string
+	if timeouts.Statement > 0 {
+		query += fmt.Sprintf("SET LOCAL statement_timeout = %d;\n", timeouts.Statement.Milliseconds())
+	}
+	if timeouts.Lock > 0 {
+		query += fmt.Sprintf("SET LOCAL lock_timeout = %d;\n", timeouts.Lock.Milliseconds())
+	}
+	if query == "" {
+		return ""
+	}
+	// named like the sqlc queries for their span
+	return "-- name: SetLocalTimeouts :exec\n" + query
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutsSetLocal(t *testing.T) {
+	require.Empty(t, Timeouts{}.setLocal())
+	require.Equal(t, "-- name: SetLocalTimeouts :exec\nSET LOCAL lock_timeout = 250;\n", Timeouts{Lock: 250 * time.Millisecond}.setLocal())
+	require.Equal(t,
+		"-- name: SetLocalTimeouts :exec\nSET LOCAL statement_timeout = 5000;\nSET LOCAL lock_timeout = 2000;\n",
+		Timeouts{Statement: 5 * time.Second, Lock: 2 * time.Second}.setLocal(),
+	)
+
+	// the context without timeouts is unbounded
+	require.Equal(t, Timeouts{}, ContextTimeouts(context.Background()))
+}
+
+func TestTransactionLockTimeout(t *testing.T) {
+	store := NewStore(testDb).(*SQLStore)
+	account := createRandomAccount(t)
+
+	// another transaction holds the row
+	tx, err := testDb.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	_, err = tx.Exec("SELECT id FROM accounts WHERE id = $1 FOR UPDATE", account.ID)
+	require.NoError(t, err)
+
+	ctx := WithTimeouts(context.Background(), Timeouts{Lock: 100 * time.Millisecond})
+	err = store.execTransaction(ctx, func(q *Queries) error {
+		_, err := q.db.ExecContext(ctx, "SELECT id FROM accounts WHERE id = $1 FOR UPDATE", account.ID)
+		return err
+	})
+	require.Error(t, err)
+	require.IsType(t, &pq.Error{}, err)
+	require.Equal(t, "lock_not_available", err.(*pq.Error).Code.Name())
+}
+
+func TestTransactionStatementTimeout(t *testing.T) {
+	store := NewStore(testDb).(*SQLStore)
+
+	ctx := WithTimeouts(context.Background(), Timeouts{Statement: 100 * time.Millisecond})
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		_, err := q.db.ExecContext(ctx, "SELECT pg_sleep(1)")
+		return err
+	})
+	require.Error(t, err)
+	require.IsType(t, &pq.Error{}, err)
+	require.Equal(t, "query_canceled", err.(*pq.Error).Code.Name())
+
+	// SET LOCAL is scoped to the transaction, the connection goes back to the pool unbounded
+	var statementTimeout string
+	require.NoError(t, testDb.QueryRow("SHOW statement_timeout").Scan(&statementTimeout))
+	require.Equal(t, "0", statementTimeout)
+}
+
+func TestPoolStatementTimeout(t *testing.T) {
+	store := NewStore(testDb).(*SQLStore)
+
+	ctx := WithTimeouts(context.Background(), Timeouts{Statement: 100 * time.Millisecond})
+	start := time.Now()
+	_, err := store.Queries.db.ExecContext(ctx, "SELECT pg_sleep(1)")
+	require.Error(t, err)
+	require.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"runtime"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/AYehia0/go-bk-mst/db"
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// a span per query, named after the sqlc query.
+// the queries of a transaction are children of its span, the closures of the transactions
+// use the ctx of the caller so it can't be taken from there, it's nil for the queries of the pool.
+type tracedDBTX struct {
+	db DBTX
+	tx trace.Span
+}
+
+func newTracedDBTX(db DBTX, tx trace.Span) DBTX {
+	return &tracedDBTX{db: db, tx: tx}
+}
+
+func (t *tracedDBTX) startSpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	if t.tx != nil {
+		ctx = trace.ContextWithSpan(ctx, t.tx)
+	}
+	return tracer().Start(ctx, queryName(query),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBStatementKey.String(query)),
+	)
+}
+
+// the queries of the pool can't SET LOCAL like the transactions, the statement timeout is their deadline instead.
+// the cancel goes along for the connections of openPool to call it once the rows are closed
+func (t *tracedDBTX) statementContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout := ContextTimeouts(ctx).Statement; t.tx == nil && timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		return context.WithValue(ctx, statementCancelKey{}, cancel), cancel
+	}
+	return ctx, func() {}
+}
+
+func endSpan(span trace.Span, err error) {
+	// no rows isn't a failure of the query
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t *tracedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := t.statementContext(ctx)
+	defer cancel()
+	ctx, span := t.startSpan(ctx, query)
+	res, err := t.db.ExecContext(ctx, query, args...)
+	endSpan(span, err)
+	return res, err
+}
+
+func (t *tracedDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, cancel := t.statementContext(ctx)
+	defer cancel()
+	ctx, span := t.startSpan(ctx, query)
+	stmt, err := t.db.PrepareContext(ctx, query)
+	endSpan(span, err)
+	return stmt, err
+}
+
+// the span ends before the rows are read, the deadline of the statement timeout has to cover the reading
+// so it's only canceled here when the query failed, the rows cancel it once closed, see statementRows
+func (t *tracedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, cancel := t.statementContext(ctx)
+	ctx, span := t.startSpan(ctx, query)
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	endSpan(span, err)
+	if err != nil {
+		cancel()
+	}
+	return rows, err
+}
+
+// the Scan closes the rows of the row, canceling the deadline like QueryContext
+func (t *tracedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, cancel := t.statementContext(ctx)
+	ctx, span := t.startSpan(ctx, query)
+	row := t.db.QueryRowContext(ctx, query, args...)
+	endSpan(span, row.Err())
+	if row.Err() != nil {
+		cancel()
+	}
+	return row
+}
+
+// the name of the sqlc queries, from their "-- name: GetAccountById :one" comment
+func queryName(query string) string {
+	if rest, ok := strings.CutPrefix(query, "-- name: "); ok {
+		if name, _, ok := strings.Cut(rest, " "); ok {
+			return name
+		}
+	}
+	return "query"
+}
+
+// the name of the exported func calling execTransaction, like TransferTransaction
+func transactionName() string {
+	pc, _, _, ok := runtime.Caller(2)
+	if !ok {
+		return "Transaction"
+	}
+	name := runtime.FuncForPC(pc).Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
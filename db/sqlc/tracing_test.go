@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryName(t *testing.T) {
+	require.Equal(t, "GetAccountById", queryName("-- name: GetAccountById :one\nSELECT * FROM accounts WHERE id = $1"))
+	require.Equal(t, "query", queryName("SELECT 1"))
+}
+
+func TestTransferTxSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	ctx, root := provider.Tracer("test").Start(context.Background(), "request")
+	_, err := store.TransferTransaction(ctx, TransferTxParams{
+		FromAccountId: acc1.ID,
+		ToAccountId:   acc2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	root.End()
+
+	// the other tests might be running queries too, keep the spans of our trace only
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID() == root.SpanContext().TraceID() {
+			spans[span.Name()] = span
+		}
+	}
+
+	tx, ok := spans["TransferTransaction"]
+	require.True(t, ok)
+	require.Equal(t, root.SpanContext().SpanID(), tx.Parent().SpanID())
+
+	// the queries of the closure are under the transaction, not under the request
+	for _, name := range []string{"CreateTransfer", "CreateEntry"} {
+		query, ok := spans[name]
+		require.True(t, ok, name)
+		require.Equal(t, tx.SpanContext().SpanID(), query.Parent().SpanID(), name)
+	}
+}
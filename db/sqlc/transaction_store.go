@@ -6,12 +6,35 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
+	"time"
 )
 
 // in order to have all the functions defined in this interface, we can use sqlc emit to interface to automatically add them
 type Store interface {
 	Querier
 	TransferTransaction(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	TransferAllowance(ctx context.Context, account Account, limits TransferLimits) (TransferAllowance, error)
+	PreviewFee(ctx context.Context, currency string, amount int64) (FeeQuote, error)
+	BatchTransferTransaction(ctx context.Context, args []TransferTxParams) ([]TransferTxResult, error)
+	HoldTransaction(ctx context.Context, arg HoldTxParams) (HoldTxResult, error)
+	CaptureHoldTransaction(ctx context.Context, holdId int64) (CaptureHoldTxResult, error)
+	VoidHoldTransaction(ctx context.Context, holdId int64) (Hold, error)
+	ExpireHoldsTransaction(ctx context.Context, limit int32) ([]Hold, error)
+	ReverseTransferTransaction(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	CreatePaymentFileTransaction(ctx context.Context, arg CreatePaymentFileTxParams) (PaymentFileTxResult, error)
+	ExecutePaymentInstructionTransaction(ctx context.Context, instructionId int64, limits *TransferLimits) (PaymentInstruction, error)
+	AccrueInterestTransaction(ctx context.Context, date time.Time) (int64, error)
+	PostInterestTransaction(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error)
+	CashTransaction(ctx context.Context, arg CashTxParams) (CashTxResult, error)
+	SnapshotBalances(ctx context.Context, takenAt time.Time) (int64, error)
+	BalanceAt(ctx context.Context, account Account, at time.Time) (int64, error)
+	CreateAccountTransaction(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateUserTransaction(ctx context.Context, arg CreateUserParams) (User, error)
+	RelayOutboxTransaction(ctx context.Context, limit int32, publish func(OutboxEvent) error) (int, error)
+	CreateWebhookDeliveriesTransaction(ctx context.Context, arg CreateWebhookDeliveriesTxParams) (int, error)
+	Ping(ctx context.Context) error
+	GetSchemaMigration(ctx context.Context) (SchemaMigration, error)
 }
 
 // provides all the functions to execute sql db queries and transactions
@@ -23,13 +46,17 @@ type SQLStore struct {
 func NewStore(db *sql.DB) Store {
 	return &SQLStore{
 		db:      db,
-		Queries: New(db),
+		Queries: New(newTracedDBTX(db, nil)),
 	}
 }
 
 // execute the transaction
 // callback on the same function
-func (store *SQLStore) execTransaction(ctx context.Context, fn func(*Queries) error) error {
+func (store *SQLStore) execTransaction(ctx context.Context, fn func(*Queries) error) (err error) {
+	// a span from the begin to the commit, the time waiting for the locks shows up in the query spans under it
+	ctx, span := tracer().Start(ctx, transactionName())
+	defer func() { endSpan(span, err) }()
+
 	// store.db.Begin() uses the background context
 	tx, err := store.db.BeginTx(ctx, nil)
 
@@ -37,8 +64,19 @@ func (store *SQLStore) execTransaction(ctx context.Context, fn func(*Queries) er
 		return err
 	}
 
+	traced := newTracedDBTX(tx, span)
+
+	// bound the statements and the lock waits of the transaction, see WithTimeouts
+	if setLocal := ContextTimeouts(ctx).setLocal(); setLocal != "" {
+		if _, err = traced.ExecContext(ctx, setLocal); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
 	// get query object
-	query := New(tx)
+	observed := &observedDBTX{DBTX: traced}
+	query := New(observed)
 	err = fn(query)
 
 	//rollback on any error
@@ -50,14 +88,27 @@ func (store *SQLStore) execTransaction(ctx context.Context, fn func(*Queries) er
 	}
 
 	// commit if all operations were successful
-	return tx.Commit()
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	observed.committed()
+	return nil
 }
 
 // contains the input params for a successful transaction
 type TransferTxParams struct {
-	FromAccountId int64 `json:"from_account_id"`
-	ToAccountId   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountId int64  `json:"from_account_id"`
+	ToAccountId   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Description   string `json:"description"`
+	Reference     string `json:"reference"`
+	Category      string `json:"category"`
+	// the limits of the sender, nil for the transfers the bank makes on its own (reversals, captures...)
+	Limits *TransferLimits `json:"limits"`
+	// the sender pays the fee of the currency's schedule on top of the amount
+	ChargeFee bool `json:"charge_fee"`
+	// the fee quoted beforehand, by the hold being captured or the batch locking its fee accounts, nil to quote it here
+	Quote *FeeQuote `json:"quote,omitempty"`
 }
 
 // contains the output result for a successful transaction
@@ -67,6 +118,10 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	ToEntry     Entry    `json:"to_entry"`
 	FromEntry   Entry    `json:"from_entry"`
+	// the fee is debited from the sender in its own entry and credited to the bank's fee account
+	Fee          int64  `json:"fee"`
+	FromFeeEntry *Entry `json:"from_fee_entry,omitempty"`
+	FeeEntry     *Entry `json:"fee_entry,omitempty"`
 }
 
 var txKey = struct{}{}
@@ -77,51 +132,209 @@ func (store *SQLStore) TransferTransaction(ctx context.Context, arg TransferTxPa
 	// go Closures : https://betterprogramming.pub/closures-made-simple-with-golang-69db3017cd7b?gi=48e0b91f624a
 	err := store.execTransaction(ctx, func(q *Queries) error {
 		var err error
+		res, err = transfer(ctx, q, arg)
+		return err
+	})
+	return res, err
+}
 
-		// 1. create a transfer
-		res.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountId,
-			ToAccountID:   arg.ToAccountId,
-			Amount:        arg.Amount,
-		})
+// creates the transfer record, its entries and updates the balances using the given queries.
+// it's shared by all the transactions that end up moving money between 2 accounts.
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	var res TransferTxResult
+	var err error
 
-		if err != nil {
-			return err
-		}
+	var quote FeeQuote
 
-		// 2. create entry to the account who received the amount with negative amount
-		res.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountId,
-			Amount:    -arg.Amount,
-		})
+	fromAccount, err := q.GetAccountById(ctx, arg.FromAccountId)
+	if err != nil {
+		return res, err
+	}
 
+	if arg.ChargeFee && arg.Quote != nil {
+		quote = *arg.Quote
+	} else if arg.ChargeFee {
+		quote, err = quoteFee(ctx, q, fromAccount.Currency, arg.Amount)
 		if err != nil {
-			return err
+			return res, err
 		}
+	}
+
+	// lock in the same order as moveMoney, the balance and the usage of the sender can't change until the transfer is done
+	ids := []int64{arg.FromAccountId, arg.ToAccountId}
+	if quote.Fee > 0 {
+		ids = append(ids, quote.FeeAccountId)
+	}
+	if err = lockAccounts(ctx, q, ids...); err != nil {
+		return res, err
+	}
 
-		// 3. create entry from the account who sent the amount with positive amount
-		res.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountId,
-			Amount:    arg.Amount,
-		})
+	// read again under the lock, the funds on hold aren't available to spend.
+	// the bank's own accounts, like the cash and the interest expense ones, can go below zero
+	fromAccount, err = q.GetAccountById(ctx, arg.FromAccountId)
+	if err != nil {
+		return res, err
+	}
+	if !fromAccount.IsSystem && fromAccount.AvailableBalance < arg.Amount+quote.Fee {
+		return res, ErrInsufficientFunds
+	}
 
-		if err != nil {
-			return err
+	if arg.Limits != nil {
+		if err = checkTransferLimits(ctx, q, fromAccount, *arg.Limits, arg.Amount); err != nil {
+			return res, err
 		}
+	}
+
+	// 1. create a transfer
+	res.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountId,
+		ToAccountID:   arg.ToAccountId,
+		Amount:        arg.Amount,
+		Description:   arg.Description,
+		Reference:     arg.Reference,
+		Category:      arg.Category,
+		Fee:           quote.Fee,
+	})
+
+	if err != nil {
+		return res, err
+	}
+
+	// 2. create entry to the account who received the amount with negative amount
+	res.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:   arg.FromAccountId,
+		Amount:      -arg.Amount,
+		Description: arg.Description,
+		Reference:   arg.Reference,
+		Category:    arg.Category,
+	})
 
-		// get the accounts from the database, then add/subtract from their balance (need proper locking mechanism)
+	if err != nil {
+		return res, err
+	}
 
-		// to avoid deadlock, update smaller account id first
-		if arg.FromAccountId < arg.ToAccountId {
+	// 3. create entry from the account who sent the amount with positive amount
+	res.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:   arg.ToAccountId,
+		Amount:      arg.Amount,
+		Description: arg.Description,
+		Reference:   arg.Reference,
+		Category:    arg.Category,
+	})
 
-			res.FromAccount, res.ToAccount, err = moveMoney(ctx, q, arg.FromAccountId, -arg.Amount, arg.ToAccountId, arg.Amount)
-		} else {
-			res.ToAccount, res.FromAccount, err = moveMoney(ctx, q, arg.ToAccountId, arg.Amount, arg.FromAccountId, -arg.Amount)
+	if err != nil {
+		return res, err
+	}
+
+	// get the accounts from the database, then add/subtract from their balance (need proper locking mechanism)
+
+	// to avoid deadlock, update smaller account id first
+	if arg.FromAccountId < arg.ToAccountId {
+		res.FromAccount, res.ToAccount, err = moveMoney(ctx, q, arg.FromAccountId, -arg.Amount, arg.ToAccountId, arg.Amount)
+	} else {
+		res.ToAccount, res.FromAccount, err = moveMoney(ctx, q, arg.ToAccountId, arg.Amount, arg.FromAccountId, -arg.Amount)
+	}
+
+	if err != nil {
+		return res, err
+	}
+
+	// 4. charge the fee
+	if quote.Fee > 0 {
+		if err = chargeTransferFee(ctx, q, arg, quote, &res); err != nil {
+			return res, err
 		}
+	}
+
+	// 5. let the other systems and the account streams know, once the transaction commits
+	if err = notifyTransfer(ctx, q, res); err != nil {
+		return res, err
+	}
+	if err = addEvent(ctx, q, EventTransferCompleted, AggregateTransfer, strconv.FormatInt(res.Transfer.ID, 10), res.Transfer); err != nil {
+		return res, err
+	}
+	observeTransfer(q, res)
+	return res, nil
+}
+
+// the new entries of both sides, the fee account is a system one nobody streams
+func notifyTransfer(ctx context.Context, q *Queries, res TransferTxResult) error {
+	if err := notifyActivity(ctx, q, res.FromAccount, res.FromEntry); err != nil {
+		return err
+	}
+	if err := notifyActivity(ctx, q, res.ToAccount, res.ToEntry); err != nil {
+		return err
+	}
+	if res.FromFeeEntry != nil {
+		return notifyActivity(ctx, q, res.FromAccount, *res.FromFeeEntry)
+	}
+	return nil
+}
+
+// charges the fee of the transfer, the accounts are already locked by transfer
+func chargeTransferFee(ctx context.Context, q *Queries, arg TransferTxParams, quote FeeQuote, res *TransferTxResult) error {
+	var err error
 
-		return nil
+	res.Fee = quote.Fee
+	res.FromFeeEntry, res.FeeEntry, err = chargeFee(ctx, q, arg, quote)
+	if err != nil {
+		return err
+	}
+
+	res.FromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     arg.FromAccountId,
+		Amount: -quote.Fee,
 	})
-	return res, err
+	if err != nil {
+		return err
+	}
+
+	feeAccount, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     quote.FeeAccountId,
+		Amount: quote.Fee,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the fee account might be one side of the transfer
+	switch feeAccount.ID {
+	case res.ToAccount.ID:
+		res.ToAccount = feeAccount
+	case res.FromAccount.ID:
+		res.FromAccount = feeAccount
+	}
+	return nil
+}
+
+func chargeFee(ctx context.Context, q *Queries, arg TransferTxParams, quote FeeQuote) (*Entry, *Entry, error) {
+	description := "Fee"
+	if arg.Description != "" {
+		description = "Fee: " + arg.Description
+	}
+
+	fromEntry, err := q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:   arg.FromAccountId,
+		Amount:      -quote.Fee,
+		Description: description,
+		Reference:   arg.Reference,
+		Category:    "fees",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	feeEntry, err := q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:   quote.FeeAccountId,
+		Amount:      quote.Fee,
+		Description: description,
+		Reference:   arg.Reference,
+		Category:    "fees",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &fromEntry, &feeEntry, nil
 }
 
 func moveMoney(
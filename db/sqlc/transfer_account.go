@@ -0,0 +1,29 @@
+package db
+
+import (
+	"context"
+	"net/http"
+)
+
+// the account exists, isn't one of the bank's and has the currency of the transfer.
+// checked by the http and grpc servers before starting a transfer, the messages are shown to the users
+func CheckTransferAccount(ctx context.Context, q Querier, accountId int64, currency string) (Account, error) {
+	account, err := q.GetAccountById(ctx, accountId)
+	if err != nil {
+		return account, err
+	}
+
+	// the bank's own accounts are only moved by the bank
+	if account.IsSystem {
+		return account, newStatusError(http.StatusUnprocessableEntity, codeUnprocessable, "Account [%d] is a system account", accountId)
+	}
+
+	if account.Currency != currency {
+		return account, CurrencyMismatch(accountId, currency, account.Currency)
+	}
+	return account, nil
+}
+
+func CurrencyMismatch(accountId int64, currency, accountCurrency string) error {
+	return newStatusError(http.StatusBadRequest, codeInvalidArgument, "Account [%d] currency mismatch: %s vs %s", accountId, currency, accountCurrency)
+}
@@ -0,0 +1,155 @@
+// Limits on the money leaving an account, checked inside the transactions that move it.
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/utils"
+)
+
+const (
+	LimitMaxAmount   = "max_amount"
+	LimitDailyAmount = "daily_amount"
+	LimitHourlyCount = "hourly_count"
+)
+
+// the configured defaults, the store applies the overrides of the user/account on top of them
+func DefaultTransferLimits(config utils.Config) *TransferLimits {
+	return &TransferLimits{
+		MaxAmount:   config.TransferMaxAmount,
+		DailyAmount: config.TransferDailyAmount,
+		HourlyCount: config.TransferHourlyCount,
+	}
+}
+
+// 0 means unlimited
+type TransferLimits struct {
+	MaxAmount   int64 `json:"max_amount"`
+	DailyAmount int64 `json:"daily_amount"`
+	HourlyCount int64 `json:"hourly_count"`
+}
+
+// applies the non NULL limits of the override on top of the current ones
+func (limits TransferLimits) override(row TransferLimit) TransferLimits {
+	if row.MaxAmount.Valid {
+		limits.MaxAmount = row.MaxAmount.Int64
+	}
+	if row.DailyAmount.Valid {
+		limits.DailyAmount = row.DailyAmount.Int64
+	}
+	if row.HourlyCount.Valid {
+		limits.HourlyCount = row.HourlyCount.Int64
+	}
+	return limits
+}
+
+// the effective limits of an account and how much of them is already used
+type TransferAllowance struct {
+	Limits TransferLimits `json:"limits"`
+	// sent in the last 24 hours
+	DailyAmount int64 `json:"daily_amount"`
+	// transfers made in the last hour
+	HourlyCount int64 `json:"hourly_count"`
+}
+
+// the amount that can still be sent today, -1 when unlimited
+func (allowance TransferAllowance) RemainingDailyAmount() int64 {
+	return remaining(allowance.Limits.DailyAmount, allowance.DailyAmount)
+}
+
+// the number of transfers left for this hour, -1 when unlimited
+func (allowance TransferAllowance) RemainingHourlyCount() int64 {
+	return remaining(allowance.Limits.HourlyCount, allowance.HourlyCount)
+}
+
+func remaining(limit, used int64) int64 {
+	if limit == 0 {
+		return -1
+	}
+	if used > limit {
+		return 0
+	}
+	return limit - used
+}
+
+// returned when a transfer would go over one of the limits
+type LimitError struct {
+	Limit     string `json:"limit"`
+	Max       int64  `json:"max"`
+	Remaining int64  `json:"remaining"`
+}
+
+func (err *LimitError) Error() string {
+	return fmt.Sprintf("Transfer exceeds the %s limit of %d, %d remaining", err.Limit, err.Max, err.Remaining)
+}
+
+// checks whether the amount can be sent on top of what's already sent
+func (allowance TransferAllowance) check(amount int64) error {
+	limits := allowance.Limits
+
+	if limits.MaxAmount > 0 && amount > limits.MaxAmount {
+		return &LimitError{Limit: LimitMaxAmount, Max: limits.MaxAmount, Remaining: limits.MaxAmount}
+	}
+	if limits.DailyAmount > 0 && allowance.DailyAmount+amount > limits.DailyAmount {
+		return &LimitError{Limit: LimitDailyAmount, Max: limits.DailyAmount, Remaining: allowance.RemainingDailyAmount()}
+	}
+	if limits.HourlyCount > 0 && allowance.HourlyCount+1 > limits.HourlyCount {
+		return &LimitError{Limit: LimitHourlyCount, Max: limits.HourlyCount, Remaining: allowance.RemainingHourlyCount()}
+	}
+	return nil
+}
+
+func (store *SQLStore) TransferAllowance(ctx context.Context, account Account, limits TransferLimits) (TransferAllowance, error) {
+	return transferAllowance(ctx, store.Queries, account, limits)
+}
+
+// resolves the limits of the account from the defaults and the overrides, then sums up its recent transfers
+func transferAllowance(ctx context.Context, q *Queries, account Account, limits TransferLimits) (TransferAllowance, error) {
+	var allowance TransferAllowance
+
+	overrides, err := q.GetTransferLimitOverrides(ctx, GetTransferLimitOverridesParams{
+		Username:  sql.NullString{String: account.OwnerName, Valid: true},
+		AccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+	})
+	if err != nil {
+		return allowance, err
+	}
+	for _, override := range overrides {
+		limits = limits.override(override)
+	}
+	allowance.Limits = limits
+
+	now := time.Now()
+
+	daily, err := q.GetOutgoingTransferStats(ctx, GetOutgoingTransferStatsParams{
+		AccountID: account.ID,
+		Since:     now.Add(-24 * time.Hour),
+	})
+	if err != nil {
+		return allowance, err
+	}
+	allowance.DailyAmount = daily.Total
+
+	hourly, err := q.GetOutgoingTransferStats(ctx, GetOutgoingTransferStatsParams{
+		AccountID: account.ID,
+		Since:     now.Add(-time.Hour),
+	})
+	if err != nil {
+		return allowance, err
+	}
+	allowance.HourlyCount = hourly.Count
+
+	return allowance, nil
+}
+
+// must run inside the transaction with the account locked, so concurrent transfers can't both pass the check
+func checkTransferLimits(ctx context.Context, q *Queries, account Account, limits TransferLimits, amount int64) error {
+	allowance, err := transferAllowance(ctx, q, account, limits)
+	if err != nil {
+		return err
+	}
+	return allowance.check(amount)
+}
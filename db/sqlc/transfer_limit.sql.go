@@ -0,0 +1,166 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: transfer_limit.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const getOutgoingTransferStats = `-- name: GetOutgoingTransferStats :one
+SELECT
+  COALESCE(SUM(amount), 0)::bigint AS total,
+  COUNT(*) AS count
+FROM (
+  SELECT t.amount FROM transfers t
+  WHERE t.from_account_id = $1 AND t.created_at >= $2
+  UNION ALL
+  SELECT h.amount FROM holds h
+  WHERE h.from_account_id = $1 AND h.status = 'pending' AND h.created_at >= $2
+) AS outgoing
+`
+
+type GetOutgoingTransferStatsParams struct {
+	AccountID int64     `json:"account_id"`
+	Since     time.Time `json:"since"`
+}
+
+type GetOutgoingTransferStatsRow struct {
+	Total int64 `json:"total"`
+	Count int64 `json:"count"`
+}
+
+// the pending holds are counted too, they become transfers once captured
+func (q *Queries) GetOutgoingTransferStats(ctx context.Context, arg GetOutgoingTransferStatsParams) (GetOutgoingTransferStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getOutgoingTransferStats, arg.AccountID, arg.Since)
+	var i GetOutgoingTransferStatsRow
+	err := row.Scan(&i.Total, &i.Count)
+	return i, err
+}
+
+const getTransferLimitOverrides = `-- name: GetTransferLimitOverrides :many
+SELECT id, username, account_id, max_amount, daily_amount, hourly_count, created_at FROM transfer_limits
+WHERE username = $1 OR account_id = $2
+ORDER BY account_id NULLS FIRST
+`
+
+type GetTransferLimitOverridesParams struct {
+	Username  sql.NullString `json:"username"`
+	AccountID sql.NullInt64  `json:"account_id"`
+}
+
+// the user's override comes first, so the account's one is applied on top of it
+func (q *Queries) GetTransferLimitOverrides(ctx context.Context, arg GetTransferLimitOverridesParams) ([]TransferLimit, error) {
+	rows, err := q.db.QueryContext(ctx, getTransferLimitOverrides, arg.Username, arg.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferLimit{}
+	for rows.Next() {
+		var i TransferLimit
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.AccountID,
+			&i.MaxAmount,
+			&i.DailyAmount,
+			&i.HourlyCount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setAccountTransferLimit = `-- name: SetAccountTransferLimit :one
+INSERT INTO transfer_limits (
+  account_id, max_amount, daily_amount, hourly_count
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (account_id) DO UPDATE
+SET max_amount = EXCLUDED.max_amount,
+    daily_amount = EXCLUDED.daily_amount,
+    hourly_count = EXCLUDED.hourly_count
+RETURNING id, username, account_id, max_amount, daily_amount, hourly_count, created_at
+`
+
+type SetAccountTransferLimitParams struct {
+	AccountID   sql.NullInt64 `json:"account_id"`
+	MaxAmount   sql.NullInt64 `json:"max_amount"`
+	DailyAmount sql.NullInt64 `json:"daily_amount"`
+	HourlyCount sql.NullInt64 `json:"hourly_count"`
+}
+
+func (q *Queries) SetAccountTransferLimit(ctx context.Context, arg SetAccountTransferLimitParams) (TransferLimit, error) {
+	row := q.db.QueryRowContext(ctx, setAccountTransferLimit,
+		arg.AccountID,
+		arg.MaxAmount,
+		arg.DailyAmount,
+		arg.HourlyCount,
+	)
+	var i TransferLimit
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.AccountID,
+		&i.MaxAmount,
+		&i.DailyAmount,
+		&i.HourlyCount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const setUserTransferLimit = `-- name: SetUserTransferLimit :one
+INSERT INTO transfer_limits (
+  username, max_amount, daily_amount, hourly_count
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (username) DO UPDATE
+SET max_amount = EXCLUDED.max_amount,
+    daily_amount = EXCLUDED.daily_amount,
+    hourly_count = EXCLUDED.hourly_count
+RETURNING id, username, account_id, max_amount, daily_amount, hourly_count, created_at
+`
+
+type SetUserTransferLimitParams struct {
+	Username    sql.NullString `json:"username"`
+	MaxAmount   sql.NullInt64  `json:"max_amount"`
+	DailyAmount sql.NullInt64  `json:"daily_amount"`
+	HourlyCount sql.NullInt64  `json:"hourly_count"`
+}
+
+func (q *Queries) SetUserTransferLimit(ctx context.Context, arg SetUserTransferLimitParams) (TransferLimit, error) {
+	row := q.db.QueryRowContext(ctx, setUserTransferLimit,
+		arg.Username,
+		arg.MaxAmount,
+		arg.DailyAmount,
+		arg.HourlyCount,
+	)
+	var i TransferLimit
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.AccountID,
+		&i.MaxAmount,
+		&i.DailyAmount,
+		&i.HourlyCount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferLimits(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	limits := TransferLimits{MaxAmount: 100, DailyAmount: 150, HourlyCount: 10}
+	transfer := func(amount int64) error {
+		_, err := store.TransferTransaction(context.Background(), TransferTxParams{
+			FromAccountId: acc1.ID,
+			ToAccountId:   acc2.ID,
+			Amount:        amount,
+			Limits:        &limits,
+		})
+		return err
+	}
+
+	var limitErr *LimitError
+
+	require.ErrorAs(t, transfer(101), &limitErr)
+	require.Equal(t, LimitMaxAmount, limitErr.Limit)
+
+	require.NoError(t, transfer(100))
+
+	// only 50 left for today
+	require.ErrorAs(t, transfer(51), &limitErr)
+	require.Equal(t, LimitDailyAmount, limitErr.Limit)
+	require.Equal(t, int64(50), limitErr.Remaining)
+
+	// the account's override wins over the user's one and the defaults
+	_, err := testQueries.SetUserTransferLimit(context.Background(), SetUserTransferLimitParams{
+		Username:    sql.NullString{String: acc1.OwnerName, Valid: true},
+		DailyAmount: sql.NullInt64{Int64: 1000, Valid: true},
+		HourlyCount: sql.NullInt64{Int64: 5, Valid: true},
+	})
+	require.NoError(t, err)
+	_, err = testQueries.SetAccountTransferLimit(context.Background(), SetAccountTransferLimitParams{
+		AccountID:   sql.NullInt64{Int64: acc1.ID, Valid: true},
+		HourlyCount: sql.NullInt64{Int64: 2, Valid: true},
+	})
+	require.NoError(t, err)
+
+	allowance, err := store.TransferAllowance(context.Background(), acc1, limits)
+	require.NoError(t, err)
+	require.Equal(t, TransferLimits{MaxAmount: 100, DailyAmount: 1000, HourlyCount: 2}, allowance.Limits)
+	require.Equal(t, int64(100), allowance.DailyAmount)
+	require.Equal(t, int64(900), allowance.RemainingDailyAmount())
+	require.Equal(t, int64(1), allowance.RemainingHourlyCount())
+
+	require.NoError(t, transfer(51))
+
+	require.ErrorAs(t, transfer(1), &limitErr)
+	require.Equal(t, LimitHourlyCount, limitErr.Limit)
+	require.Zero(t, limitErr.Remaining)
+
+	// the internal transfers aren't limited
+	_, err = store.TransferTransaction(context.Background(), TransferTxParams{
+		FromAccountId: acc1.ID,
+		ToAccountId:   acc2.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+}
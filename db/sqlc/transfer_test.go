@@ -70,3 +70,76 @@ func TestGetTransfers(t *testing.T) {
 		require.Equal(t, transfer.ToAccountID, acc2.ID)
 	}
 }
+
+func TestSearchTransfers(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	reference := utils.RandomString(12)
+	result, err := store.TransferTransaction(context.Background(), TransferTxParams{
+		FromAccountId: acc1.ID,
+		ToAccountId:   acc2.ID,
+		Amount:        1,
+		Description:   "NETFLIX.COM monthly",
+		Reference:     reference,
+		Category:      "subscriptions",
+	})
+	require.NoError(t, err)
+
+	// both sides of the transfer carry the details
+	for _, entry := range []Entry{result.FromEntry, result.ToEntry} {
+		require.Equal(t, "NETFLIX.COM monthly", entry.Description)
+		require.Equal(t, reference, entry.Reference)
+		require.Equal(t, "subscriptions", entry.Category)
+	}
+	createRandomTransfer(t, acc1, acc2)
+
+	for _, query := range []string{"netflix", reference} {
+		transfers, err := testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+			AccountID: acc2.ID,
+			Query:     query,
+			Limit:     5,
+		})
+		require.NoError(t, err)
+		require.Len(t, transfers, 1)
+		require.Equal(t, result.Transfer.ID, transfers[0].ID)
+	}
+
+	// the wildcards are searched for, they don't match any description
+	for _, query := range []string{"%", "_", "NETFLIX_COM", `\`} {
+		transfers, err := testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+			AccountID: acc2.ID,
+			Query:     query,
+			Limit:     5,
+		})
+		require.NoError(t, err)
+		require.Empty(t, transfers)
+
+		entries, err := testQueries.SearchEntries(context.Background(), SearchEntriesParams{
+			AccountID: acc2.ID,
+			Query:     query,
+			Limit:     5,
+		})
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	}
+
+	entries, err := testQueries.SearchEntries(context.Background(), SearchEntriesParams{
+		AccountID: acc1.ID,
+		Category:  "subscriptions",
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, result.FromEntry.ID, entries[0].ID)
+
+	// the empty filters list everything, newest first
+	transfers, err := testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+		AccountID: acc1.ID,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	require.Greater(t, transfers[0].ID, transfers[1].ID)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
@@ -53,3 +54,17 @@ func TestGetUserByUsername(t *testing.T) {
 	// check if the timestamps within some duration
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 }
+
+func TestUpdateUserRole(t *testing.T) {
+	user := createRandomUser(t)
+
+	admin, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{Username: user.Username, Role: RoleAdmin})
+	require.NoError(t, err)
+	require.Equal(t, RoleAdmin, admin.Role)
+
+	// only the known roles are stored
+	_, err = testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{Username: user.Username, Role: "root"})
+	require.Error(t, err)
+	require.IsType(t, &pq.Error{}, err)
+	require.Equal(t, "check_violation", err.(*pq.Error).Code.Name())
+}
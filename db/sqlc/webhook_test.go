@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	store := NewStore(testDb)
+	acc1 := createRandomAccount(t)
+	acc2 := createRandomAccount(t)
+
+	subscription, err := store.CreateWebhookSubscription(context.Background(), CreateWebhookSubscriptionParams{
+		OwnerName: acc2.OwnerName,
+		Url:       "https://example.com/hooks",
+		Events:    []string{EventTransferCompleted},
+		Secret:    "whsec_test",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{EventTransferCompleted}, subscription.Events)
+
+	res, err := store.TransferTransaction(context.Background(), TransferTxParams{
+		FromAccountId: acc1.ID,
+		ToAccountId:   acc2.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+	event := getAggregateEvents(t, AggregateTransfer, strconv.FormatInt(res.Transfer.ID, 10))[0]
+
+	arg := CreateWebhookDeliveriesTxParams{
+		EventId:    event.ID,
+		EventType:  event.EventType,
+		AccountIds: []int64{acc1.ID, acc2.ID},
+		Payload:    event.Payload,
+	}
+	// the receiver's owner is subscribed, the sender's isn't
+	count, err := store.CreateWebhookDeliveriesTransaction(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	// relayed again, still a single delivery
+	_, err = store.CreateWebhookDeliveriesTransaction(context.Background(), arg)
+	require.NoError(t, err)
+
+	deliveries, err := store.GetWebhookDeliveries(context.Background(), GetWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          10,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	delivery := deliveries[0]
+	require.Equal(t, WebhookDeliveryPending, delivery.Status)
+
+	// not subscribed to the event
+	count, err = store.CreateWebhookDeliveriesTransaction(context.Background(), CreateWebhookDeliveriesTxParams{
+		EventId:    event.ID,
+		EventType:  EventAccountCreated,
+		AccountIds: []int64{acc2.ID},
+		Payload:    event.Payload,
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	// other deliveries might be due too, claim until ours is leased
+	for i := 0; i < 100; i++ {
+		claimed, err := store.ClaimWebhookDeliveries(context.Background(), ClaimWebhookDeliveriesParams{
+			LeaseSeconds: 60,
+			LimitCount:   100,
+		})
+		require.NoError(t, err)
+
+		delivery, err = store.GetWebhookDeliveryById(context.Background(), delivery.ID)
+		require.NoError(t, err)
+		if delivery.NextAttemptAt.After(time.Now()) || len(claimed) == 0 {
+			break
+		}
+	}
+	require.True(t, delivery.NextAttemptAt.After(time.Now()))
+
+	delivery, err = store.UpdateWebhookDeliveryAttempt(context.Background(), UpdateWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         WebhookDeliveryFailed,
+		NextAttemptAt:  time.Now(),
+		LastStatusCode: 500,
+		LastError:      "boom",
+		DeliveredAt:    sql.NullTime{},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), delivery.Attempts)
+	require.Equal(t, WebhookDeliveryFailed, delivery.Status)
+
+	delivery, err = store.ReplayWebhookDelivery(context.Background(), delivery.ID)
+	require.NoError(t, err)
+	require.Equal(t, WebhookDeliveryPending, delivery.Status)
+	require.Zero(t, delivery.Attempts)
+}
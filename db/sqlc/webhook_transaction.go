@@ -0,0 +1,54 @@
+// Webhook deliveries, queued for the subscriptions of the owners of the accounts an event is about.
+package db
+
+import (
+	"context"
+	"encoding/json"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	// out of attempts, only a replay sends it again
+	WebhookDeliveryFailed = "failed"
+)
+
+// contains the input params to queue the deliveries of an event
+type CreateWebhookDeliveriesTxParams struct {
+	EventId   int64  `json:"event_id"`
+	EventType string `json:"event_type"`
+	// the accounts the event is about, their owners' subscriptions get it
+	AccountIds []int64         `json:"account_ids"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// queues a delivery for every subscription to the event, the ones already queued are skipped.
+// returns the number of subscriptions the event is delivered to.
+func (store *SQLStore) CreateWebhookDeliveriesTransaction(ctx context.Context, arg CreateWebhookDeliveriesTxParams) (int, error) {
+	var count int
+
+	err := store.execTransaction(ctx, func(q *Queries) error {
+		subscriptions, err := q.GetAccountsWebhookSubscriptions(ctx, GetAccountsWebhookSubscriptionsParams{
+			AccountIds: arg.AccountIds,
+			EventType:  arg.EventType,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, subscription := range subscriptions {
+			err = q.CreateWebhookDelivery(ctx, CreateWebhookDeliveryParams{
+				SubscriptionID: subscription.ID,
+				EventID:        arg.EventId,
+				EventType:      arg.EventType,
+				Payload:        arg.Payload,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		count = len(subscriptions)
+		return nil
+	})
+	return count, err
+}
@@ -0,0 +1,8 @@
+package swagger
+
+import _ "embed"
+
+// the OpenAPI v2 spec generated from the protos by make proto, the gateway routes of the SimpleBank service
+//
+//go:embed simple_bank.swagger.json
+var SimpleBank []byte
@@ -0,0 +1,50 @@
+// Publishing the domain events of the outbox to the other systems (notifications, analytics...).
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// the subjects are prefixed so the events can share a message bus with other services
+const SubjectPrefix = "bank."
+
+// a domain event as it's published, Id is the outbox id and is unique per event
+type Event struct {
+	Id            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateId   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// the subject of the event on the bus, bank.transfer.completed for example
+func (event Event) Subject() string {
+	return SubjectPrefix + event.Type
+}
+
+// delivers the events to their consumers, an event might be published more than once
+// so the consumers should dedupe on the id.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+const (
+	PublisherMemory = "memory"
+	PublisherFile   = "file"
+)
+
+// the publisher of the given kind, path is the file of the file publisher
+func NewPublisher(kind, path string) (Publisher, error) {
+	switch kind {
+	case PublisherMemory, "":
+		return NewMemoryPublisher(), nil
+	case PublisherFile:
+		return NewFilePublisher(path)
+	}
+	return nil, fmt.Errorf("Unknown events publisher %q", kind)
+}
@@ -0,0 +1,74 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent(id int64) Event {
+	return Event{
+		Id:            id,
+		Type:          "transfer.completed",
+		AggregateType: "transfer",
+		AggregateId:   "42",
+		Payload:       json.RawMessage(`{"id":42,"amount":100}`),
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	publisher := NewMemoryPublisher()
+	subscriber := publisher.Subscribe(1)
+
+	require.NoError(t, publisher.Publish(context.Background(), testEvent(1)))
+	// the subscriber's buffer is full, the event is still kept
+	require.NoError(t, publisher.Publish(context.Background(), testEvent(2)))
+
+	require.Equal(t, int64(1), (<-subscriber).Id)
+	require.Equal(t, []Event{testEvent(1), testEvent(2)}, publisher.Events())
+
+	require.NoError(t, publisher.Close())
+	_, ok := <-subscriber
+	require.False(t, ok)
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	publisher, err := NewFilePublisher(path)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), testEvent(1)))
+	require.NoError(t, publisher.Close())
+
+	// appended to on the next start
+	publisher, err = NewFilePublisher(path)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), testEvent(2)))
+	require.NoError(t, publisher.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var messages []fileMessage
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var message fileMessage
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &message))
+		messages = append(messages, message)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, messages, 2)
+	require.Equal(t, "bank.transfer.completed", messages[0].Subject)
+	require.Equal(t, []string{"1"}, messages[0].Headers[natsMsgIdHeader])
+	require.Equal(t, testEvent(1), messages[0].Data)
+	require.Equal(t, []string{"2"}, messages[1].Headers[natsMsgIdHeader])
+}
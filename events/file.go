@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// the NATS JetStream header to dedupe the messages published more than once
+const natsMsgIdHeader = "Nats-Msg-Id"
+
+// a line of the file, shaped like a NATS message so it can be replayed to a NATS server as it is
+type fileMessage struct {
+	Subject string              `json:"subject"`
+	Headers map[string][]string `json:"headers"`
+	Data    Event               `json:"data"`
+}
+
+// appends the events to a file, one JSON message per line
+type FilePublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// opens (or creates) the file at path for appending
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &FilePublisher{file: file}, nil
+}
+
+func (publisher *FilePublisher) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(fileMessage{
+		Subject: event.Subject(),
+		Headers: map[string][]string{natsMsgIdHeader: {strconv.FormatInt(event.Id, 10)}},
+		Data:    event,
+	})
+	if err != nil {
+		return err
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	if _, err := publisher.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	// the event is marked as published right after, it has to be on the disk by then
+	return publisher.file.Sync()
+}
+
+func (publisher *FilePublisher) Close() error {
+	return publisher.file.Close()
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// keeps the published events in memory, for the tests and the local runs
+type MemoryPublisher struct {
+	mu          sync.Mutex
+	events      []Event
+	subscribers []chan Event
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (publisher *MemoryPublisher) Publish(ctx context.Context, event Event) error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	publisher.events = append(publisher.events, event)
+	for _, subscriber := range publisher.subscribers {
+		// a slow subscriber doesn't block the relay, it misses the event instead
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+// the events published so far, in order
+func (publisher *MemoryPublisher) Events() []Event {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	return append([]Event(nil), publisher.events...)
+}
+
+// a channel receiving the events published from now on, buffered by size
+func (publisher *MemoryPublisher) Subscribe(size int) <-chan Event {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	subscriber := make(chan Event, size)
+	publisher.subscribers = append(publisher.subscribers, subscriber)
+	return subscriber
+}
+
+// closes the subscribers' channels
+func (publisher *MemoryPublisher) Close() error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	for _, subscriber := range publisher.subscribers {
+		close(subscriber)
+	}
+	publisher.subscribers = nil
+	return nil
+}
@@ -0,0 +1,29 @@
+package events
+
+import (
+	"context"
+	"errors"
+)
+
+// publishes every event to all the publishers in order, stopping at the first failure.
+// the relay publishes the failed event again, so the publishers before it get it twice.
+type MultiPublisher []Publisher
+
+func (publishers MultiPublisher) Publish(ctx context.Context, event Event) error {
+	for _, publisher := range publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (publishers MultiPublisher) Close() error {
+	var errs []error
+	for _, publisher := range publishers {
+		if err := publisher.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,76 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/pb"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/utils"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		return nil, err
+	}
+
+	account, err := server.store.CreateAccountTransaction(ctx, db.CreateAccountParams{
+		OwnerName: authPayload(ctx).Username,
+		Currency:  req.GetCurrency(),
+		Balance:   0,
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	if err := validateField("id", req.GetId(), "min=1"); err != nil {
+		return nil, err
+	}
+
+	account, err := server.store.GetAccountById(ctx, req.GetId())
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if account.OwnerName != authPayload(ctx).Username {
+		return nil, permissionDenied("Account doesn't belong to the logged in user!")
+	}
+
+	return &pb.GetAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	if err := validateField("page_id", req.GetPageId(), "min=1"); err != nil {
+		return nil, err
+	}
+	if err := validateField("page_size", req.GetPageSize(), "min=5,max=10"); err != nil {
+		return nil, err
+	}
+
+	accounts, err := server.store.GetAccounts(ctx, db.GetAccountsParams{
+		OwnerName: authPayload(ctx).Username,
+		Limit:     req.GetPageSize(),
+		Offset:    (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	resp := &pb.ListAccountsResponse{Accounts: make([]*pb.Account, 0, len(accounts))}
+	for _, account := range accounts {
+		resp.Accounts = append(resp.Accounts, convertAccount(account))
+	}
+	return resp, nil
+}
+
+func validateCurrency(currency string) error {
+	if !utils.IsSupportedCurrency(currency) {
+		return statusError(problem.Newf(http.StatusBadRequest, problem.CodeInvalidArgument, "currency: %q isn't supported", currency))
+	}
+	return nil
+}
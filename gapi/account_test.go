@@ -0,0 +1,190 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/pb"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestGetAccountRPC(t *testing.T) {
+	user := getRandomUser()
+	account := getRandomAccount(user.Username)
+
+	testCases := []struct {
+		testName   string
+		accountId  int64
+		setupAuth  func(t *testing.T, ctx context.Context, tokenMaker token.TokenCreator) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, resp *pb.GetAccountResponse, err error)
+	}{
+		{
+			testName:  "OK",
+			accountId: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.TokenCreator) context.Context {
+				return addAuthorization(t, ctx, tokenMaker, token.AuthorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountById(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResp: func(t *testing.T, resp *pb.GetAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, resp.GetAccount().GetId())
+				require.Equal(t, account.OwnerName, resp.GetAccount().GetOwnerName())
+				require.Equal(t, account.Balance, resp.GetAccount().GetBalance())
+				require.Equal(t, account.Currency, resp.GetAccount().GetCurrency())
+			},
+		},
+		{
+			testName:  "NotFound",
+			accountId: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.TokenCreator) context.Context {
+				return addAuthorization(t, ctx, tokenMaker, token.AuthorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountById(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResp: func(t *testing.T, resp *pb.GetAccountResponse, err error) {
+				requireCode(t, codes.NotFound, err)
+			},
+		},
+		{
+			testName:  "InvalidArgument",
+			accountId: -1,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.TokenCreator) context.Context {
+				return addAuthorization(t, ctx, tokenMaker, token.AuthorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, resp *pb.GetAccountResponse, err error) {
+				requireCode(t, codes.InvalidArgument, err)
+			},
+		},
+		{
+			testName:  "PermissionDenied",
+			accountId: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.TokenCreator) context.Context {
+				return addAuthorization(t, ctx, tokenMaker, token.AuthorizationTypeBearer, "someoneelse", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountById(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResp: func(t *testing.T, resp *pb.GetAccountResponse, err error) {
+				requireCode(t, codes.PermissionDenied, err)
+			},
+		},
+		{
+			testName:  "NoAuthorization",
+			accountId: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.TokenCreator) context.Context {
+				return ctx
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, resp *pb.GetAccountResponse, err error) {
+				requireCode(t, codes.Unauthenticated, err)
+			},
+		},
+		{
+			testName:  "UnsupportedAuthorization",
+			accountId: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.TokenCreator) context.Context {
+				return addAuthorization(t, ctx, tokenMaker, "basic", user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, resp *pb.GetAccountResponse, err error) {
+				requireCode(t, codes.Unauthenticated, err)
+			},
+		},
+		{
+			testName:  "ExpiredToken",
+			accountId: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.TokenCreator) context.Context {
+				return addAuthorization(t, ctx, tokenMaker, token.AuthorizationTypeBearer, user.Username, -time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, resp *pb.GetAccountResponse, err error) {
+				requireCode(t, codes.Unauthenticated, err)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			ctx := testCase.setupAuth(t, context.Background(), server.tokenCreator)
+			resp, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: testCase.accountId})
+			testCase.checkResp(t, resp, err)
+		})
+	}
+}
+
+func TestCreateAccountRPC(t *testing.T) {
+	user := getRandomUser()
+	account := getRandomAccount(user.Username)
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	store := mockdb.NewMockStore(controller)
+	store.EXPECT().
+		CreateAccountTransaction(gomock.Any(), gomock.Eq(db.CreateAccountParams{
+			OwnerName: user.Username,
+			Currency:  account.Currency,
+		})).
+		Times(1).
+		Return(account, nil)
+
+	server := newTestServer(t, store)
+	client := newTestClient(t, server)
+	ctx := addAuthorization(t, context.Background(), server.tokenCreator, token.AuthorizationTypeBearer, user.Username, time.Minute)
+
+	resp, err := client.CreateAccount(ctx, &pb.CreateAccountRequest{Currency: account.Currency})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, resp.GetAccount().GetId())
+
+	// the currency is validated before reaching the store
+	_, err = client.CreateAccount(ctx, &pb.CreateAccountRequest{Currency: "XYZ"})
+	requireCode(t, codes.InvalidArgument, err)
+}
+
+func getRandomAccount(username string) db.Account {
+	return db.Account{
+		ID:        utils.GetRandomAmount(),
+		OwnerName: username,
+		Currency:  utils.GetRandomCurrency(),
+		Balance:   utils.GetRandomAmount(),
+	}
+}
@@ -0,0 +1,49 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/AYehia0/go-bk-mst/metrics"
+	"github.com/AYehia0/go-bk-mst/pb"
+	"github.com/AYehia0/go-bk-mst/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const authorizationHeaderKey = "authorization"
+
+// the methods that require no login
+var publicMethods = map[string]bool{
+	pb.SimpleBank_CreateUser_FullMethodName:       true,
+	pb.SimpleBank_LoginUser_FullMethodName:        true,
+	pb.SimpleBank_RenewAccessToken_FullMethodName: true,
+}
+
+type authorizationPayloadKey struct{}
+
+// the grpc version of the authMiddleware, the token comes in the authorization metadata
+func (server *Server) authInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(authorizationHeaderKey); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+
+	payload, err := token.VerifyAuthorization(server.tokenCreator, authorization)
+	if err != nil {
+		metrics.TokenFailure(err)
+		return nil, unauthenticated(err)
+	}
+
+	return handler(context.WithValue(ctx, authorizationPayloadKey{}, payload), req)
+}
+
+// the payload of the logged-in user, set by the authInterceptor
+func authPayload(ctx context.Context) *token.Payload {
+	return ctx.Value(authorizationPayloadKey{}).(*token.Payload)
+}
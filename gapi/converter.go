@@ -0,0 +1,73 @@
+package gapi
+
+import (
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// without the password field
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username: user.Username,
+		Email:    user.Email,
+		FullName: user.FullName,
+	}
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:               account.ID,
+		OwnerName:        account.OwnerName,
+		Balance:          account.Balance,
+		AvailableBalance: account.AvailableBalance,
+		Currency:         account.Currency,
+		CreatedAt:        timestamppb.New(account.CreatedAt),
+		ChartCode:        account.ChartCode,
+		IsSystem:         account.IsSystem,
+	}
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Description:   transfer.Description,
+		Reference:     transfer.Reference,
+		Category:      transfer.Category,
+		Fee:           transfer.Fee,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	}
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:          entry.ID,
+		AccountId:   entry.AccountID,
+		Amount:      entry.Amount,
+		Description: entry.Description,
+		Reference:   entry.Reference,
+		Category:    entry.Category,
+		CreatedAt:   timestamppb.New(entry.CreatedAt),
+	}
+}
+
+func convertTransferResult(result db.TransferTxResult) *pb.CreateTransferResponse {
+	resp := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+		Fee:         result.Fee,
+	}
+	if result.FromFeeEntry != nil {
+		resp.FromFeeEntry = convertEntry(*result.FromFeeEntry)
+	}
+	if result.FeeEntry != nil {
+		resp.FeeEntry = convertEntry(*result.FeeEntry)
+	}
+	return resp
+}
@@ -0,0 +1,82 @@
+package gapi
+
+import (
+	"errors"
+	"net/http"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/pb"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// the same rules as the binding tags of the gin requests
+var validate = validator.New()
+
+func validateField(field string, value any, tag string) error {
+	if err := validate.Var(value, tag); err != nil {
+		return statusError(problem.InvalidField(field, err))
+	}
+	return nil
+}
+
+// the closest grpc codes of the error codes, like the http statuses of the api package
+var problemCodes = map[string]codes.Code{
+	problem.CodeInvalidArgument:   codes.InvalidArgument,
+	problem.CodeUnauthenticated:   codes.Unauthenticated,
+	problem.CodePermissionDenied:  codes.PermissionDenied,
+	problem.CodeForbidden:         codes.FailedPrecondition,
+	problem.CodeNotFound:          codes.NotFound,
+	problem.CodeAlreadyExists:     codes.AlreadyExists,
+	problem.CodeConflict:          codes.FailedPrecondition,
+	problem.CodeInsufficientFunds: codes.FailedPrecondition,
+	problem.CodeLimitExceeded:     codes.ResourceExhausted,
+	problem.CodeUnprocessable:     codes.FailedPrecondition,
+	problem.CodeInternal:          codes.Internal,
+}
+
+// the status of the error, the details keep what the grpc code can't tell, the error code and the http status
+// of the gateway routes, see gatewayErrorHandler
+func statusError(e *problem.Error) error {
+	code, ok := problemCodes[e.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+
+	detail := &pb.ErrorDetail{Code: e.Code, Status: int32(e.Status)}
+	for _, field := range e.Fields {
+		detail.Errors = append(detail.Errors, &pb.FieldError{Field: field.Field, Tag: field.Tag, Message: field.Message})
+	}
+
+	details := []protoadapt.MessageV1{detail}
+	var limitErr *db.LimitError
+	if errors.As(e.Limit, &limitErr) {
+		details = append([]protoadapt.MessageV1{&pb.TransferLimitError{
+			Limit:     limitErr.Limit,
+			Max:       limitErr.Max,
+			Remaining: limitErr.Remaining,
+		}}, details...)
+	}
+
+	st, err := status.New(code, e.Message).WithDetails(details...)
+	if err != nil {
+		return status.Error(code, e.Message)
+	}
+	return st.Err()
+}
+
+// maps the store errors through problem.From, the messages of the unexpected ones are hidden
+func storeError(err error) error {
+	return statusError(problem.From(err))
+}
+
+func permissionDenied(format string, a ...any) error {
+	return statusError(problem.Newf(http.StatusUnauthorized, problem.CodePermissionDenied, format, a...))
+}
+
+func unauthenticated(err error) error {
+	return statusError(problem.Wrap(http.StatusUnauthorized, problem.CodeUnauthenticated, err))
+}
@@ -0,0 +1,269 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/pb"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/tracing"
+	"github.com/google/uuid"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const gatewayBufferSize = 1 << 20
+
+// the http routes generated from the google.api.http rules of the protos.
+// the requests are proxied to the grpc server in memory, so they go through the same interceptors,
+// and the responses keep the bodies and statuses of the gin handlers they replaced.
+// the in-memory server is stopped once the ctx is done.
+func (server *Server) Gateway(ctx context.Context) (http.Handler, error) {
+	listener := bufconn.Listen(gatewayBufferSize)
+
+	grpcServer := server.GrpcServer()
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		// the grpc spans continue the traces of the http requests
+		grpc.WithUnaryInterceptor(tracing.UnaryClientInterceptor),
+	)
+	if err != nil {
+		grpcServer.Stop()
+		return nil, err
+	}
+
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &gatewayMarshaler{
+			JSONPb: runtime.JSONPb{
+				MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+				UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+			},
+		}),
+		runtime.WithErrorHandler(gatewayErrorHandler),
+	)
+
+	if err := pb.RegisterSimpleBankHandler(ctx, mux, conn); err != nil {
+		conn.Close()
+		grpcServer.Stop()
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+		grpcServer.Stop()
+	}()
+	return mux, nil
+}
+
+// the error codes of the statuses without an ErrorDetail, the ones not coming from the SimpleBank handlers
+var gatewayProblemCodes = map[codes.Code]string{
+	codes.InvalidArgument:    problem.CodeInvalidArgument,
+	codes.OutOfRange:         problem.CodeInvalidArgument,
+	codes.Unauthenticated:    problem.CodeUnauthenticated,
+	codes.PermissionDenied:   problem.CodePermissionDenied,
+	codes.NotFound:           problem.CodeNotFound,
+	codes.AlreadyExists:      problem.CodeAlreadyExists,
+	codes.FailedPrecondition: problem.CodeConflict,
+	codes.ResourceExhausted:  problem.CodeLimitExceeded,
+}
+
+// writes the application/problem+json bodies of the gin handlers, with the http status of the ErrorDetail.
+// the statuses without one, of the gateway itself, follow the usual grpc to http mapping
+func gatewayErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	st := status.Convert(err)
+
+	e := &problem.Error{Status: runtime.HTTPStatusFromCode(st.Code()), Code: gatewayProblemCodes[st.Code()], Message: st.Message()}
+	if e.Code == "" {
+		e.Code = problem.CodeInternal
+	}
+
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *pb.ErrorDetail:
+			e.Code = detail.GetCode()
+			if detail.GetStatus() != 0 {
+				e.Status = int(detail.GetStatus())
+			}
+			for _, field := range detail.GetErrors() {
+				e.Fields = append(e.Fields, problem.FieldError{
+					Field:   field.GetField(),
+					Tag:     field.GetTag(),
+					Message: field.GetMessage(),
+				})
+			}
+		case *pb.TransferLimitError:
+			e.Limit = &db.LimitError{
+				Limit:     detail.GetLimit(),
+				Max:       detail.GetMax(),
+				Remaining: detail.GetRemaining(),
+			}
+		}
+	}
+
+	problem.Write(w, r, e)
+}
+
+const gatewayContentType = "application/json; charset=utf-8"
+
+// encodes the responses with encoding/json as the gin handlers did, protojson quotes the int64 fields
+// and formats the messages differently. the requests are still decoded with protojson.
+type gatewayMarshaler struct {
+	runtime.JSONPb
+}
+
+func (m *gatewayMarshaler) ContentType(v any) string {
+	return gatewayContentType
+}
+
+func (m *gatewayMarshaler) Marshal(v any) ([]byte, error) {
+	if legacy, ok := gatewayBody(v); ok {
+		return json.Marshal(legacy)
+	}
+	return m.JSONPb.Marshal(v)
+}
+
+type gatewayUser struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+}
+
+type gatewayLoginUser struct {
+	AccessToken          string      `json:"access_token"`
+	RefreshToken         string      `json:"refresh_token"`
+	User                 gatewayUser `json:"user"`
+	AccessTokenExpireAt  time.Time   `json:"access_token_expire_at"`
+	RefreshTokenExpireAt time.Time   `json:"refresh_token_expire_at"`
+	SessionId            uuid.UUID   `json:"session_id"`
+}
+
+type gatewayRenewAccessToken struct {
+	AccessToken         string    `json:"access_token"`
+	AccessTokenExpireAt time.Time `json:"access_token_expire_at"`
+}
+
+// the structs the gin handlers responded with, for the response bodies of the http rules
+func gatewayBody(v any) (any, bool) {
+	switch resp := v.(type) {
+	case *pb.User:
+		return newGatewayUser(resp), true
+	case *pb.LoginUserResponse:
+		sessionId, _ := uuid.Parse(resp.GetSessionId())
+		return gatewayLoginUser{
+			AccessToken:          resp.GetAccessToken(),
+			RefreshToken:         resp.GetRefreshToken(),
+			User:                 newGatewayUser(resp.GetUser()),
+			AccessTokenExpireAt:  gatewayTime(resp.GetAccessTokenExpireAt()),
+			RefreshTokenExpireAt: gatewayTime(resp.GetRefreshTokenExpireAt()),
+			SessionId:            sessionId,
+		}, true
+	case *pb.RenewAccessTokenResponse:
+		return gatewayRenewAccessToken{
+			AccessToken:         resp.GetAccessToken(),
+			AccessTokenExpireAt: gatewayTime(resp.GetAccessTokenExpireAt()),
+		}, true
+	case *pb.Account:
+		return accountFromPb(resp), true
+	case []*pb.Account:
+		accounts := make([]db.Account, 0, len(resp))
+		for _, account := range resp {
+			accounts = append(accounts, accountFromPb(account))
+		}
+		return accounts, true
+	case *pb.CreateTransferResponse:
+		result := db.TransferTxResult{
+			Transfer:    transferFromPb(resp.GetTransfer()),
+			FromAccount: accountFromPb(resp.GetFromAccount()),
+			ToAccount:   accountFromPb(resp.GetToAccount()),
+			ToEntry:     entryFromPb(resp.GetToEntry()),
+			FromEntry:   entryFromPb(resp.GetFromEntry()),
+			Fee:         resp.GetFee(),
+		}
+		if resp.FromFeeEntry != nil {
+			entry := entryFromPb(resp.GetFromFeeEntry())
+			result.FromFeeEntry = &entry
+		}
+		if resp.FeeEntry != nil {
+			entry := entryFromPb(resp.GetFeeEntry())
+			result.FeeEntry = &entry
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+func newGatewayUser(user *pb.User) gatewayUser {
+	return gatewayUser{
+		Username: user.GetUsername(),
+		Email:    user.GetEmail(),
+		FullName: user.GetFullName(),
+	}
+}
+
+func accountFromPb(account *pb.Account) db.Account {
+	return db.Account{
+		ID:               account.GetId(),
+		OwnerName:        account.GetOwnerName(),
+		Balance:          account.GetBalance(),
+		Currency:         account.GetCurrency(),
+		CreatedAt:        gatewayTime(account.GetCreatedAt()),
+		AvailableBalance: account.GetAvailableBalance(),
+		ChartCode:        account.GetChartCode(),
+		IsSystem:         account.GetIsSystem(),
+	}
+}
+
+func transferFromPb(transfer *pb.Transfer) db.Transfer {
+	return db.Transfer{
+		ID:            transfer.GetId(),
+		FromAccountID: transfer.GetFromAccountId(),
+		ToAccountID:   transfer.GetToAccountId(),
+		Amount:        transfer.GetAmount(),
+		CreatedAt:     gatewayTime(transfer.GetCreatedAt()),
+		Description:   transfer.GetDescription(),
+		Reference:     transfer.GetReference(),
+		Category:      transfer.GetCategory(),
+		Fee:           transfer.GetFee(),
+	}
+}
+
+func entryFromPb(entry *pb.Entry) db.Entry {
+	return db.Entry{
+		ID:          entry.GetId(),
+		AccountID:   entry.GetAccountId(),
+		Amount:      entry.GetAmount(),
+		CreatedAt:   gatewayTime(entry.GetCreatedAt()),
+		Description: entry.GetDescription(),
+		Reference:   entry.GetReference(),
+		Category:    entry.GetCategory(),
+	}
+}
+
+// the timestamps only keep the instant, they're rendered in the local zone as the gin handlers did
+// with the token expiries and the times of the database sessions. the zero times are kept in UTC as they were.
+func gatewayTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	t := ts.AsTime()
+	if t.IsZero() {
+		return time.Time{}
+	}
+	return t.Local()
+}
@@ -0,0 +1,239 @@
+package gapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// the gateway answers with the same bytes the gin handlers did
+func TestGatewayCompatibility(t *testing.T) {
+	user := getRandomUser()
+
+	account1 := getRandomAccount(user.Username)
+	account2 := getRandomAccount(getRandomUser().Username)
+	// the ids are random, the stubs can't tell the accounts apart otherwise
+	account2.ID = account1.ID + 1000
+	account1.Currency, account2.Currency = utils.USD, utils.USD
+	account1.CreatedAt = time.Date(2023, 8, 1, 10, 30, 0, 123456000, time.Local)
+	account1.ChartCode = db.ChartCustomerDeposits
+
+	// the entries of the fee as chargeFee books them, the fee account is the bank's fee income of the currency
+	fromFeeEntry := db.Entry{ID: 4, AccountID: account1.ID, Amount: -1, Description: "Fee", Reference: "INV-1", Category: "fees"}
+	feeEntry := db.Entry{ID: 5, AccountID: 3, Amount: 1, Description: "Fee", Reference: "INV-1", Category: "fees"}
+	result := db.TransferTxResult{
+		Transfer:     db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10, Fee: 1, Reference: "INV-1"},
+		FromAccount:  account1,
+		ToAccount:    account2,
+		FromEntry:    db.Entry{ID: 2, AccountID: account1.ID, Amount: -10, Reference: "INV-1"},
+		ToEntry:      db.Entry{ID: 3, AccountID: account2.ID, Amount: 10, Reference: "INV-1"},
+		Fee:          1,
+		FromFeeEntry: &fromFeeEntry,
+		FeeEntry:     &feeEntry,
+	}
+
+	testCases := []struct {
+		testName   string
+		method     string
+		url        string
+		body       any
+		buildStubs func(store *mockdb.MockStore)
+		status     int
+		want       any
+	}{
+		{
+			testName: "GetAccount",
+			method:   http.MethodGet,
+			url:      "/accounts/" + jsonString(t, account1.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+			},
+			status: http.StatusOK,
+			want:   account1,
+		},
+		{
+			testName: "ListAccounts/Empty",
+			method:   http.MethodGet,
+			url:      "/accounts?page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccounts(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{}, nil)
+			},
+			status: http.StatusOK,
+			want:   []db.Account{},
+		},
+		{
+			testName: "CreateTransfer",
+			method:   http.MethodPost,
+			url:      "/transfers",
+			body: map[string]any{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        utils.USD,
+				"reference":       "INV-1",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(1).Return(result, nil)
+			},
+			status: http.StatusOK,
+			want:   result,
+		},
+		{
+			testName: "CreateTransfer/LimitExceeded",
+			method:   http.MethodPost,
+			url:      "/transfers",
+			body: map[string]any{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        utils.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, &db.LimitError{Limit: db.LimitDailyAmount, Max: 500000, Remaining: 5})
+			},
+			status: http.StatusUnprocessableEntity,
+			want: problem.Document{
+				Type:     "about:blank",
+				Title:    "Unprocessable Entity",
+				Status:   http.StatusUnprocessableEntity,
+				Detail:   (&db.LimitError{Limit: db.LimitDailyAmount, Max: 500000, Remaining: 5}).Error(),
+				Instance: "/transfers",
+				Code:     problem.CodeLimitExceeded,
+				Limit:    &db.LimitError{Limit: db.LimitDailyAmount, Max: 500000, Remaining: 5},
+			},
+		},
+		{
+			testName: "CreateAccountDuplicate",
+			method:   http.MethodPost,
+			url:      "/accounts",
+			body:     map[string]any{"currency": utils.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pq.Error{Code: "23505"})
+			},
+			// the status comes from the ErrorDetail, not from the grpc code
+			status: http.StatusConflict,
+			want: problem.Document{
+				Type:     "about:blank",
+				Title:    "Conflict",
+				Status:   http.StatusConflict,
+				Detail:   "Resource already exists",
+				Instance: "/accounts",
+				Code:     problem.CodeAlreadyExists,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			gateway, err := server.Gateway(ctx)
+			require.NoError(t, err)
+
+			var body bytes.Buffer
+			if testCase.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(testCase.body))
+			}
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(testCase.method, testCase.url, &body)
+
+			accessToken, _, err := server.tokenCreator.Create(user.Username, time.Minute)
+			require.NoError(t, err)
+			req.Header.Set("Authorization", token.AuthorizationTypeBearer+" "+accessToken)
+
+			gateway.ServeHTTP(recorder, req)
+
+			require.Equal(t, testCase.status, recorder.Code)
+			if testCase.status >= http.StatusBadRequest {
+				require.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+			} else {
+				require.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+			}
+			require.Equal(t, jsonString(t, testCase.want), recorder.Body.String())
+		})
+	}
+}
+
+// the session keeps the client details of the http request, not the ones of the in-memory connection
+func TestGatewayLoginUserSession(t *testing.T) {
+	user := getRandomUser()
+	password := utils.RandomString(8)
+
+	hashedPassword, err := utils.GenerateHash(password)
+	require.NoError(t, err)
+	user.Password = hashedPassword
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	store := mockdb.NewMockStore(controller)
+	store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+			require.Equal(t, "bank-cli/1.0", arg.UserAgent)
+			require.Equal(t, "203.0.113.7", arg.IpAddr)
+			return db.Session{ID: arg.ID, Username: arg.Username}, nil
+		})
+
+	server := newTestServer(t, store)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gateway, err := server.Gateway(ctx)
+	require.NoError(t, err)
+
+	data := jsonString(t, map[string]string{"username": user.Username, "password": password})
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewReader([]byte(data)))
+	req.Header.Set("User-Agent", "bank-cli/1.0")
+	req.RemoteAddr = "203.0.113.7:52100"
+
+	gateway.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp gatewayLoginUser
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	require.Equal(t, user.Username, resp.User.Username)
+	require.NotEmpty(t, resp.AccessToken)
+}
+
+func jsonString(t *testing.T, v any) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,78 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/pb"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// the limits every user initiated transfer of the test server is sent with
+var testTransferLimits = db.TransferLimits{
+	MaxAmount:   100000,
+	DailyAmount: 500000,
+	HourlyCount: 10,
+}
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := utils.Config{
+		TokenKey:                   utils.RandomString(32),
+		TokenExpireDuration:        time.Minute,
+		TokenRefreshExpireDuration: time.Hour,
+		TransferMaxAmount:          testTransferLimits.MaxAmount,
+		TransferDailyAmount:        testTransferLimits.DailyAmount,
+		TransferHourlyCount:        testTransferLimits.HourlyCount,
+	}
+	server, err := NewServer(config, store)
+
+	require.NoError(t, err)
+
+	return server
+}
+
+// serves the server in memory, so the requests go through the interceptors as well
+func newTestClient(t *testing.T, server *Server) pb.SimpleBankClient {
+	listener := bufconn.Listen(1 << 20)
+
+	grpcServer := server.GrpcServer()
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewSimpleBankClient(conn)
+}
+
+func addAuthorization(t *testing.T, ctx context.Context, tokenCreator token.TokenCreator, authorizationType string, username string, duration time.Duration) context.Context {
+	accessToken, _, err := tokenCreator.Create(username, duration)
+	require.NoError(t, err)
+
+	authorization := fmt.Sprintf("%s %s", authorizationType, accessToken)
+	return metadata.AppendToOutgoingContext(ctx, authorizationHeaderKey, authorization)
+}
+
+func requireCode(t *testing.T, code codes.Code, err error) {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, code, st.Code(), st.Message())
+}
@@ -0,0 +1,49 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	userAgentHeaderKey = "user-agent"
+	// set by the gateway from the headers of the http requests
+	gatewayUserAgentHeaderKey = "grpcgateway-user-agent"
+	xForwardedForHeaderKey    = "x-forwarded-for"
+)
+
+type clientMetadata struct {
+	UserAgent string
+	ClientIP  string
+}
+
+// the client details saved with the sessions, like the ctx.ClientIP and UserAgent of gin
+func extractMetadata(ctx context.Context) clientMetadata {
+	var client clientMetadata
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(userAgentHeaderKey); len(values) > 0 {
+			client.UserAgent = values[0]
+		}
+		if values := md.Get(gatewayUserAgentHeaderKey); len(values) > 0 {
+			client.UserAgent = values[0]
+		}
+		// the first address is the client, the rest are the proxies
+		if values := md.Get(xForwardedForHeaderKey); len(values) > 0 {
+			client.ClientIP = strings.TrimSpace(strings.Split(values[0], ",")[0])
+			return client
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		client.ClientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(client.ClientIP); err == nil {
+			client.ClientIP = host
+		}
+	}
+	return client
+}
@@ -0,0 +1,102 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"time"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/pb"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/AYehia0/go-bk-mst/tracing"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// the gRPC API, served next to the gin one over the same store and tokens
+type Server struct {
+	pb.UnimplementedSimpleBankServer
+	store        db.Store
+	tokenCreator token.TokenCreator
+	config       utils.Config
+}
+
+func NewServer(config utils.Config, store db.Store) (*Server, error) {
+	// the same key as the HTTP server, so the tokens work on both of them
+	creator, err := token.NewPasteoToken(config.TokenKey)
+
+	if err != nil {
+		return nil, err
+	}
+	server := &Server{
+		store:        store,
+		tokenCreator: creator,
+		config:       config,
+	}
+
+	return server, nil
+}
+
+// the grpc server with the tracing, db timeouts and auth interceptors and the SimpleBank service registered
+func (server *Server) GrpcServer() *grpc.Server {
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor, server.dbTimeoutsInterceptor, server.authInterceptor))
+	pb.RegisterSimpleBankServer(grpcServer, server)
+
+	// lets grpcurl and the like discover the service
+	reflection.Register(grpcServer)
+
+	return grpcServer
+}
+
+// the DB_STATEMENT_TIMEOUT and DB_LOCK_TIMEOUT of the calls, like the dbTimeoutsMiddleware of the HTTP server
+func (server *Server) dbTimeoutsInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	timeouts := db.Timeouts{Statement: server.config.DbStatementTimeout, Lock: server.config.DbLockTimeout}
+	return handler(db.WithTimeouts(ctx, timeouts), req)
+}
+
+// serves until the ctx is done, then waits up to the SHUTDOWN_TIMEOUT for the calls in flight
+func (server *Server) StartServer(ctx context.Context, addressUrl string) error {
+	listener, err := net.Listen("tcp", addressUrl)
+	if err != nil {
+		return err
+	}
+	return server.serve(ctx, listener)
+}
+
+func (server *Server) serve(ctx context.Context, listener net.Listener) error {
+	grpcServer := server.GrpcServer()
+
+	stopped := make(chan struct{})
+	defer close(stopped)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			server.gracefulStop(grpcServer)
+		case <-stopped:
+		}
+	}()
+
+	// nil once stopped
+	return grpcServer.Serve(listener)
+}
+
+// GracefulStop waits for every call, the ones still running after the SHUTDOWN_TIMEOUT are cancelled
+func (server *Server) gracefulStop(grpcServer *grpc.Server) {
+	drained := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(drained)
+	}()
+
+	timer := time.NewTimer(server.config.ShutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-drained:
+	case <-timer.C:
+		grpcServer.Stop()
+		<-drained
+	}
+}
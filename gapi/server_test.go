@@ -0,0 +1,109 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/pb"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestServeStops(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(controller))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- server.serve(ctx, listener)
+	}()
+
+	cancel()
+	select {
+	case err := <-served:
+		require.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("The server didn't stop")
+	}
+}
+
+// the calls still running after the SHUTDOWN_TIMEOUT don't hold the shutdown
+func TestServeStopsAfterTimeout(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	started := make(chan struct{})
+	store := mockdb.NewMockStore(controller)
+	store.EXPECT().
+		GetAccountById(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, id int64) (db.Account, error) {
+			close(started)
+			// cancelled by the Stop
+			<-ctx.Done()
+			return db.Account{}, ctx.Err()
+		})
+
+	server := newTestServer(t, store)
+	server.config.ShutdownTimeout = 100 * time.Millisecond
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() {
+		served <- server.serve(ctx, listener)
+	}()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	callCtx := addAuthorization(t, context.Background(), server.tokenCreator, token.AuthorizationTypeBearer, utils.GetRandomOwnerName(), time.Minute)
+	called := make(chan error, 1)
+	go func() {
+		_, err := pb.NewSimpleBankClient(conn).GetAccount(callCtx, &pb.GetAccountRequest{Id: 1})
+		called <- err
+	}()
+
+	<-started
+	cancel()
+	select {
+	case err := <-served:
+		require.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		t.Fatal("The server didn't stop")
+	}
+	require.Error(t, <-called)
+}
+
+func TestDbTimeoutsInterceptor(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(controller))
+	server.config.DbStatementTimeout = 5 * time.Second
+	server.config.DbLockTimeout = 2 * time.Second
+
+	_, err := server.dbTimeoutsInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+		require.Equal(t, db.Timeouts{Statement: 5 * time.Second, Lock: 2 * time.Second}, db.ContextTimeouts(ctx))
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/AYehia0/go-bk-mst/category"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/pb"
+)
+
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	if err := validateField("from_account_id", req.GetFromAccountId(), "min=1"); err != nil {
+		return nil, err
+	}
+	if err := validateField("to_account_id", req.GetToAccountId(), "min=1"); err != nil {
+		return nil, err
+	}
+	if err := validateField("amount", req.GetAmount(), "gt=0"); err != nil {
+		return nil, err
+	}
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		return nil, err
+	}
+	if err := validateField("description", req.GetDescription(), "max=255"); err != nil {
+		return nil, err
+	}
+	if err := validateField("reference", req.GetReference(), "max=64"); err != nil {
+		return nil, err
+	}
+	if err := validateField("category", req.GetCategory(), "max=64"); err != nil {
+		return nil, err
+	}
+
+	if _, err := db.CheckTransferAccount(ctx, server.store, req.GetToAccountId(), req.GetCurrency()); err != nil {
+		return nil, storeError(err)
+	}
+	fromAccount, err := db.CheckTransferAccount(ctx, server.store, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	// logged-in user can only transfer money from his account to others
+	payload := authPayload(ctx)
+	if payload.Username != fromAccount.OwnerName {
+		return nil, permissionDenied("from_account doesn't belong to the logged-in user!")
+	}
+
+	args := []db.TransferTxParams{{
+		FromAccountId: req.GetFromAccountId(),
+		ToAccountId:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		Description:   req.GetDescription(),
+		Reference:     req.GetReference(),
+		Category:      req.GetCategory(),
+		Limits:        db.DefaultTransferLimits(server.config),
+		ChargeFee:     true,
+	}}
+	if err := category.Fill(ctx, server.store, payload.Username, args); err != nil {
+		return nil, storeError(err)
+	}
+
+	result, err := server.store.TransferTransaction(ctx, args[0])
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return convertTransferResult(result), nil
+}
@@ -0,0 +1,180 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/pb"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateTransferRPC(t *testing.T) {
+	user1 := getRandomUser()
+	user2 := getRandomUser()
+
+	account1 := getRandomAccount(user1.Username)
+	account2 := getRandomAccount(user2.Username)
+	account3 := getRandomAccount(user2.Username)
+	account1.ID, account2.ID, account3.ID = 1, 2, 3
+
+	account1.Currency = utils.USD
+	account2.Currency = utils.USD
+	account3.Currency = utils.EGP
+
+	amount := int64(10)
+
+	testCases := []struct {
+		testName   string
+		username   string
+		req        *pb.CreateTransferRequest
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, resp *pb.CreateTransferResponse, err error)
+	}{
+		{
+			testName: "OK",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      utils.USD,
+				Description:   "rent",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					GetCategoryRules(gomock.Any(), gomock.Eq(user1.Username)).
+					Times(1).
+					Return([]db.CategoryRule{{Pattern: "RENT", Category: "housing"}}, nil)
+				store.EXPECT().
+					TransferTransaction(gomock.Any(), gomock.Eq(db.TransferTxParams{
+						FromAccountId: account1.ID,
+						ToAccountId:   account2.ID,
+						Amount:        amount,
+						Description:   "rent",
+						Category:      "housing",
+						Limits:        &testTransferLimits,
+						ChargeFee:     true,
+					})).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer: db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount, Category: "housing"},
+						Fee:      1,
+					}, nil)
+			},
+			checkResp: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, amount, resp.GetTransfer().GetAmount())
+				require.Equal(t, "housing", resp.GetTransfer().GetCategory())
+				require.Equal(t, int64(1), resp.GetFee())
+			},
+		},
+		{
+			testName: "PermissionDenied",
+			username: user2.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      utils.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				requireCode(t, codes.PermissionDenied, err)
+			},
+		},
+		{
+			testName: "CurrencyMismatch",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account3.ID,
+				Amount:        amount,
+				Currency:      utils.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().TransferTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				requireCode(t, codes.InvalidArgument, err)
+			},
+		},
+		{
+			testName: "LimitExceeded",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      utils.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, &db.LimitError{Limit: db.LimitDailyAmount})
+			},
+			checkResp: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				requireCode(t, codes.ResourceExhausted, err)
+
+				// the limit comes in the details for the clients to show
+				details := status.Convert(err).Details()
+				require.Len(t, details, 2)
+				require.Equal(t, db.LimitDailyAmount, details[0].(*pb.TransferLimitError).GetLimit())
+				require.Equal(t, problem.CodeLimitExceeded, details[1].(*pb.ErrorDetail).GetCode())
+				require.Equal(t, int32(http.StatusUnprocessableEntity), details[1].(*pb.ErrorDetail).GetStatus())
+			},
+		},
+		{
+			testName: "NegativeAmount",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        -amount,
+				Currency:      utils.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountById(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, resp *pb.CreateTransferResponse, err error) {
+				requireCode(t, codes.InvalidArgument, err)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			ctx := addAuthorization(t, context.Background(), server.tokenCreator, token.AuthorizationTypeBearer, testCase.username, time.Minute)
+			resp, err := client.CreateTransfer(ctx, testCase.req)
+			testCase.checkResp(t, resp, err)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/metrics"
+	"github.com/AYehia0/go-bk-mst/pb"
+	"github.com/AYehia0/go-bk-mst/problem"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	if err := validateUsername(req.GetUsername()); err != nil {
+		return nil, err
+	}
+	if err := validateField("email", req.GetEmail(), "required,email"); err != nil {
+		return nil, err
+	}
+	if err := validatePassword(req.GetPassword()); err != nil {
+		return nil, err
+	}
+	if err := validateField("full_name", req.GetFullName(), "required"); err != nil {
+		return nil, err
+	}
+
+	// hashing the password
+	hashedPassword, err := utils.GenerateHash(req.GetPassword())
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	user, err := server.store.CreateUserTransaction(ctx, db.CreateUserParams{
+		Email:    req.GetEmail(),
+		Username: req.GetUsername(),
+		Password: hashedPassword,
+		FullName: req.GetFullName(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.CreateUserResponse{User: convertUser(user)}, nil
+}
+
+func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	if err := validateUsername(req.GetUsername()); err != nil {
+		return nil, err
+	}
+	if err := validatePassword(req.GetPassword()); err != nil {
+		return nil, err
+	}
+
+	user, err := server.store.GetUserByUsername(ctx, req.GetUsername())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			metrics.LoginFailed()
+		}
+		return nil, storeError(err)
+	}
+
+	if err := utils.ComparePasswords(req.GetPassword(), user.Password); err != nil {
+		metrics.LoginFailed()
+		return nil, unauthenticated(errors.New("Invalid username or password"))
+	}
+
+	accessToken, payloadAccess, err := server.tokenCreator.Create(user.Username, server.config.TokenExpireDuration)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	refreshToken, payloadRefresh, err := server.tokenCreator.Create(user.Username, server.config.TokenRefreshExpireDuration)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	client := extractMetadata(ctx)
+	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:           payloadRefresh.Id,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		IsBlocked:    false,
+		IpAddr:       client.ClientIP,
+		UserAgent:    client.UserAgent,
+		ExpiredAt:    payloadRefresh.ExpiredAt,
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+	metrics.LoginSucceeded()
+
+	return &pb.LoginUserResponse{
+		User:                 convertUser(user),
+		SessionId:            session.ID.String(),
+		AccessToken:          accessToken,
+		RefreshToken:         refreshToken,
+		AccessTokenExpireAt:  timestamppb.New(payloadAccess.ExpiredAt),
+		RefreshTokenExpireAt: timestamppb.New(payloadRefresh.ExpiredAt),
+	}, nil
+}
+
+func (server *Server) RenewAccessToken(ctx context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	if err := validateField("refresh_token", req.GetRefreshToken(), "required"); err != nil {
+		return nil, err
+	}
+
+	refreshPayload, err := server.tokenCreator.Verify(req.GetRefreshToken())
+	if err != nil {
+		return nil, unauthenticated(err)
+	}
+
+	session, err := server.store.GetSessionById(ctx, refreshPayload.Id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, unauthenticated(errors.New("Session not found"))
+		}
+		return nil, storeError(err)
+	}
+
+	// the http route answered the unusable sessions with a 500, the grpc clients get the same
+	if session.IsBlocked {
+		return nil, sessionError(errors.New("Session has been blocked!"))
+	}
+	if session.Username != refreshPayload.Username {
+		return nil, sessionError(errors.New("Session doesn't belong to this user!"))
+	}
+	if session.RefreshToken != req.GetRefreshToken() {
+		return nil, sessionError(errors.New("Mismatched session token!"))
+	}
+	if time.Now().After(session.ExpiredAt) {
+		return nil, sessionError(errors.New("Session has been expired before!"))
+	}
+
+	accessToken, payloadAccess, err := server.tokenCreator.Create(session.Username, server.config.TokenExpireDuration)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.RenewAccessTokenResponse{
+		AccessToken:         accessToken,
+		AccessTokenExpireAt: timestamppb.New(payloadAccess.ExpiredAt),
+	}, nil
+}
+
+func sessionError(err error) error {
+	return statusError(problem.Wrap(http.StatusInternalServerError, problem.CodeInternal, err))
+}
+
+func validateUsername(username string) error {
+	return validateField("username", username, "required,alphanum")
+}
+
+func validatePassword(password string) error {
+	return validateField("password", password, "required,min=8")
+}
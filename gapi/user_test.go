@@ -0,0 +1,306 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/pb"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCreateUserRPC(t *testing.T) {
+	user := getRandomUser()
+	password := utils.RandomString(8)
+
+	testCases := []struct {
+		testName   string
+		req        *pb.CreateUserRequest
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, resp *pb.CreateUserResponse, err error)
+	}{
+		{
+			testName: "OK",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				Email:    user.Email,
+				Password: password,
+				FullName: user.FullName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateUserParams) (db.User, error) {
+						// the password is hashed before it's stored
+						require.NoError(t, utils.ComparePasswords(password, arg.Password))
+						return user, nil
+					})
+			},
+			checkResp: func(t *testing.T, resp *pb.CreateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, resp.GetUser().GetUsername())
+				require.Equal(t, user.Email, resp.GetUser().GetEmail())
+				require.Equal(t, user.FullName, resp.GetUser().GetFullName())
+			},
+		},
+		{
+			testName: "AlreadyExists",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				Email:    user.Email,
+				Password: password,
+				FullName: user.FullName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTransaction(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, &pq.Error{Code: "23505"})
+			},
+			checkResp: func(t *testing.T, resp *pb.CreateUserResponse, err error) {
+				requireCode(t, codes.AlreadyExists, err)
+			},
+		},
+		{
+			testName: "InvalidEmail",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				Email:    "not-an-email",
+				Password: password,
+				FullName: user.FullName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUserTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, resp *pb.CreateUserResponse, err error) {
+				requireCode(t, codes.InvalidArgument, err)
+			},
+		},
+		{
+			testName: "ShortPassword",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				Email:    user.Email,
+				Password: "123",
+				FullName: user.FullName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUserTransaction(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, resp *pb.CreateUserResponse, err error) {
+				requireCode(t, codes.InvalidArgument, err)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			client := newTestClient(t, newTestServer(t, store))
+
+			resp, err := client.CreateUser(context.Background(), testCase.req)
+			testCase.checkResp(t, resp, err)
+		})
+	}
+}
+
+func TestLoginUserRPC(t *testing.T) {
+	user := getRandomUser()
+	password := utils.RandomString(8)
+
+	hashedPassword, err := utils.GenerateHash(password)
+	require.NoError(t, err)
+	user.Password = hashedPassword
+
+	testCases := []struct {
+		testName   string
+		password   string
+		buildStubs func(store *mockdb.MockStore)
+		checkResp  func(t *testing.T, server *Server, resp *pb.LoginUserResponse, err error)
+	}{
+		{
+			testName: "OK",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.NotEmpty(t, arg.UserAgent)
+						return db.Session{ID: arg.ID, Username: arg.Username}, nil
+					})
+			},
+			checkResp: func(t *testing.T, server *Server, resp *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+
+				// the access token works on the authenticated methods of both servers
+				payload, err := server.tokenCreator.Verify(resp.GetAccessToken())
+				require.NoError(t, err)
+				require.Equal(t, user.Username, payload.Username)
+				require.NotEmpty(t, resp.GetRefreshToken())
+				require.NotEmpty(t, resp.GetSessionId())
+			},
+		},
+		{
+			testName: "WrongPassword",
+			password: "wrong-password",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResp: func(t *testing.T, server *Server, resp *pb.LoginUserResponse, err error) {
+				requireCode(t, codes.Unauthenticated, err)
+			},
+		},
+		{
+			testName: "NotFound",
+			password: password,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResp: func(t *testing.T, server *Server, resp *pb.LoginUserResponse, err error) {
+				requireCode(t, codes.NotFound, err)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			testCase.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			resp, err := client.LoginUser(context.Background(), &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: testCase.password,
+			})
+			testCase.checkResp(t, server, resp, err)
+		})
+	}
+}
+
+func TestRenewAccessTokenRPC(t *testing.T) {
+	user := getRandomUser()
+
+	testCases := []struct {
+		testName   string
+		session    func(session db.Session) db.Session
+		sessionErr error
+		code       codes.Code
+	}{
+		{
+			testName: "OK",
+			session:  func(session db.Session) db.Session { return session },
+			code:     codes.OK,
+		},
+		{
+			testName:   "NotFound",
+			session:    func(session db.Session) db.Session { return db.Session{} },
+			sessionErr: sql.ErrNoRows,
+			code:       codes.Unauthenticated,
+		},
+		{
+			testName: "Blocked",
+			session: func(session db.Session) db.Session {
+				session.IsBlocked = true
+				return session
+			},
+			code: codes.Internal,
+		},
+		{
+			testName: "OtherUser",
+			session: func(session db.Session) db.Session {
+				session.Username = getRandomUser().Username
+				return session
+			},
+			code: codes.Internal,
+		},
+		{
+			testName: "MismatchedToken",
+			session: func(session db.Session) db.Session {
+				session.RefreshToken = "other-token"
+				return session
+			},
+			code: codes.Internal,
+		},
+		{
+			testName: "Expired",
+			session: func(session db.Session) db.Session {
+				session.ExpiredAt = time.Now().Add(-time.Minute)
+				return session
+			},
+			code: codes.Internal,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			store := mockdb.NewMockStore(controller)
+			server := newTestServer(t, store)
+
+			refreshToken, payload, err := server.tokenCreator.Create(user.Username, time.Hour)
+			require.NoError(t, err)
+
+			session := testCase.session(db.Session{
+				ID:           payload.Id,
+				Username:     user.Username,
+				RefreshToken: refreshToken,
+				ExpiredAt:    payload.ExpiredAt,
+			})
+			store.EXPECT().GetSessionById(gomock.Any(), gomock.Eq(payload.Id)).Times(1).Return(session, testCase.sessionErr)
+
+			resp, err := newTestClient(t, server).RenewAccessToken(context.Background(), &pb.RenewAccessTokenRequest{
+				RefreshToken: refreshToken,
+			})
+			if testCase.code != codes.OK {
+				requireCode(t, testCase.code, err)
+				return
+			}
+			require.NoError(t, err)
+
+			accessPayload, err := server.tokenCreator.Verify(resp.GetAccessToken())
+			require.NoError(t, err)
+			require.Equal(t, user.Username, accessPayload.Username)
+		})
+	}
+}
+
+func getRandomUser() db.User {
+	return db.User{
+		Username: utils.GetRandomOwnerName(),
+		FullName: utils.GetRandomOwnerName(),
+		Email:    utils.GetRandomEmail(),
+	}
+}
@@ -1,12 +1,27 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/AYehia0/go-bk-mst/api"
+	"github.com/AYehia0/go-bk-mst/db/migrations"
 	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/events"
+	"github.com/AYehia0/go-bk-mst/gapi"
+	"github.com/AYehia0/go-bk-mst/metrics"
+	"github.com/AYehia0/go-bk-mst/stream"
+	"github.com/AYehia0/go-bk-mst/tracing"
 	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/AYehia0/go-bk-mst/webhook"
+	"github.com/AYehia0/go-bk-mst/worker"
 
 	// important for database init
 	_ "github.com/lib/pq"
@@ -20,19 +35,153 @@ func main() {
 		log.Fatalf("Couldn't load configs, error: %s", err)
 	}
 
-	conn, err := sql.Open(config.DbDriver, config.DbSource)
+	logger, err := utils.NewLogger(os.Stderr, config.LogLevel, config.LogFormat)
+
+	if err != nil {
+		log.Fatalf("Couldn't create the logger : %v", err)
+	}
+	// the log.Printf of the workers go through it too
+	slog.SetDefault(logger)
+
+	// main migrate up|down|version|force, runs the embedded migrations and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(config, os.Args[2:]); err != nil {
+			log.Fatalf("Failed to migrate : %v", err)
+		}
+		return
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), config.TracingExporter, config.TracingEndpoint)
+
+	if err != nil {
+		log.Fatalf("Couldn't set up the tracing : %v", err)
+	}
+
+	// SIGTERM stops the servers and the workers, the work in flight is drained first
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// waits for the database to be up, the containers can start before it
+	conn, err := db.Connect(ctx, config)
 
 	if err != nil {
 		log.Fatalf("Failed to connect to the database : %v", err)
 	}
 
+	if config.AutoMigrate {
+		if err := migrations.AutoMigrate(ctx, conn, config.DbSource); err != nil {
+			log.Fatalf("Failed to migrate the database : %v", err)
+		}
+	}
+
+	// the stats of the connection pool on /metrics
+	if err := metrics.RegisterDB(conn, "simple_bank"); err != nil {
+		log.Fatalf("Failed to register the database metrics : %v", err)
+	}
+
 	store := db.NewStore(conn)
+	db.ObserveTransfers(metrics.ObserveTransfer)
+
+	var workers sync.WaitGroup
+	runWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(ctx)
+		}()
+	}
+
+	// release the holds nobody captured or voided in time
+	runWorker(func(ctx context.Context) { worker.ExpireHolds(ctx, store, config.HoldExpiryInterval) })
+	// accrue the interest of the savings accounts daily and post it monthly
+	runWorker(func(ctx context.Context) { worker.Interest(ctx, store, config.InterestInterval) })
+	// daily balance snapshots, so the historical balances don't scan the whole ledger
+	runWorker(func(ctx context.Context) { worker.SnapshotBalances(ctx, store, config.SnapshotInterval) })
+
+	publisher, err := events.NewPublisher(config.OutboxPublisher, config.OutboxFile)
+	if err != nil {
+		log.Fatalf("Failed to create the events publisher : %v", err)
+	}
+	// the webhooks are queued from the relayed events as well
+	publisher = events.MultiPublisher{publisher, webhook.NewPublisher(store)}
+
+	// publish the domain events written to the outbox
+	runWorker(func(ctx context.Context) { worker.RelayOutbox(ctx, store, publisher, config.OutboxInterval) })
+	// and POST them to the users' webhooks
+	dispatcher := webhook.NewDispatcher(store, config.WebhookTimeout, config.WebhookMaxAttempts)
+	runWorker(func(ctx context.Context) { worker.DeliverWebhooks(ctx, dispatcher, config.WebhookInterval) })
+
 	server, err := api.NewServer(config, store)
 
 	if err != nil {
 		log.Fatalf("Failed to start the server : %v", err)
 	}
 
-	server.StartServer(config.ServerAddr)
+	// the activity of the transfers made by any replica, streamed to the clients connected to this one
+	go func() {
+		if err := stream.Listen(ctx, config.DbSource, server.ActivityHub()); err != nil {
+			log.Printf("Stopped listening to the account activity : %v", err)
+		}
+	}()
+
+	grpcServer, err := gapi.NewServer(config, store)
+
+	if err != nil {
+		log.Fatalf("Failed to start the gRPC server : %v", err)
+	}
+
+	// the same API for the internal services, on its own port.
+	// if either server fails the other one is shut down too
+	var servers sync.WaitGroup
+	serverErrs := make(chan error, 2)
+	serve := func(name string, start func(ctx context.Context) error) {
+		servers.Add(1)
+		go func() {
+			defer servers.Done()
+			if err := start(ctx); err != nil {
+				serverErrs <- fmt.Errorf("the %s server failed : %w", name, err)
+				stop()
+			}
+		}()
+	}
+	serve("gRPC", func(ctx context.Context) error { return grpcServer.StartServer(ctx, config.GrpcServerAddr) })
+	serve("HTTP", func(ctx context.Context) error { return server.StartServer(ctx, config.ServerAddr) })
+
+	<-ctx.Done()
+	log.Printf("Shutting down")
 
+	servers.Wait()
+	close(serverErrs)
+
+	if !waitTimeout(&workers, config.ShutdownTimeout) {
+		log.Printf("The workers didn't stop in %s", config.ShutdownTimeout)
+	}
+	publisher.Close()
+	shutdownTracing(context.Background())
+	conn.Close()
+
+	failed := false
+	for err := range serverErrs {
+		log.Printf("%v", err)
+		failed = true
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// false when the wait group isn't done in time
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
 }
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// counts the requests of the gin routes, the unknown paths share a label so they can't blow up the series
+func Middleware(ctx *gin.Context) {
+	start := time.Now()
+
+	ctx.Next()
+
+	route := ctx.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+
+	httpRequests.WithLabelValues(ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status())).Inc()
+	httpDuration.WithLabelValues(ctx.Request.Method, route).Observe(time.Since(start).Seconds())
+}
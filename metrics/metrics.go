@@ -0,0 +1,101 @@
+// the prometheus metrics of the service, served on /metrics
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "simple_bank"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "The HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "The latency of the HTTP requests by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	transfers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "The transfers made by currency.",
+	}, []string{"currency"})
+
+	// in minor units, from 1.00 to 1,000,000.00
+	transferAmounts = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "transfer_amount",
+		Help:      "The amounts of the transfers in minor units by currency.",
+		Buckets:   prometheus.ExponentialBuckets(100, 10, 7),
+	}, []string{"currency"})
+
+	tokenFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_verification_failures_total",
+		Help:      "The rejected access tokens by reason.",
+	}, []string{"reason"})
+
+	logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "The logins by result, success or failure.",
+	}, []string{"result"})
+)
+
+// the metrics of the default registry, the go runtime and the process ones included
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// exports the stats of the connection pool, it can only be registered once
+func RegisterDB(conn *sql.DB, name string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(conn, name))
+}
+
+// counts a committed transfer, registered with db.ObserveTransfers
+func ObserveTransfer(currency string, amount int64) {
+	transfers.WithLabelValues(currency).Inc()
+	transferAmounts.WithLabelValues(currency).Observe(float64(amount))
+}
+
+// counts a token rejected by token.VerifyAuthorization
+func TokenFailure(err error) {
+	tokenFailures.WithLabelValues(tokenFailureReason(err)).Inc()
+}
+
+func tokenFailureReason(err error) string {
+	switch {
+	case errors.Is(err, token.AuthorizationEmptyError):
+		return "missing"
+	case errors.Is(err, token.AuthorizationFormatError):
+		return "invalid_format"
+	case errors.Is(err, token.AuthorizationTypeError):
+		return "unsupported_type"
+	case errors.Is(err, token.TokenExpiredError):
+		return "expired"
+	default:
+		return "invalid"
+	}
+}
+
+func LoginSucceeded() {
+	logins.WithLabelValues("success").Inc()
+}
+
+func LoginFailed() {
+	logins.WithLabelValues("failure").Inc()
+}
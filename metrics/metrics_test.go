@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AYehia0/go-bk-mst/token"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenFailureReason(t *testing.T) {
+	reasons := map[error]string{
+		token.AuthorizationEmptyError:                          "missing",
+		token.AuthorizationFormatError:                         "invalid_format",
+		fmt.Errorf("%w basic", token.AuthorizationTypeError):   "unsupported_type",
+		token.TokenExpiredError:                                "expired",
+		token.TokenInvalidError:                                "invalid",
+		errors.New("chacha20poly1305: message authentication"): "invalid",
+	}
+	for err, reason := range reasons {
+		require.Equal(t, reason, tokenFailureReason(err), err.Error())
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Middleware)
+	router.GET("/accounts/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	for _, path := range []string{"/accounts/1", "/accounts/2", "/no/such/route"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// the route is the label, not the path
+	require.Equal(t, float64(2), testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/accounts/:id", "204")))
+	require.Equal(t, float64(1), testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "unmatched", "404")))
+}
+
+func TestObserveTransfer(t *testing.T) {
+	before := testutil.ToFloat64(transfers.WithLabelValues("EUR"))
+
+	ObserveTransfer("EUR", 2500)
+	ObserveTransfer("EUR", 100)
+
+	require.Equal(t, before+2, testutil.ToFloat64(transfers.WithLabelValues("EUR")))
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/AYehia0/go-bk-mst/db/migrations"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/golang-migrate/migrate/v4"
+)
+
+const migrateUsage = `usage: main migrate <command>
+
+  up [N]       apply all or N up migrations
+  down N|-all  apply N or all down migrations
+  version      print the current version
+  force V      set the version without running the migrations, after fixing a dirty database`
+
+// the migrate subcommand, the embedded migrations against the DB_SOURCE
+func runMigrate(config utils.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	m, err := migrations.New(config.DbSource)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	// the progress of the migrations
+	m.Log = migrateLogger{}
+
+	command, args := args[0], args[1:]
+	switch {
+	case command == "up" && len(args) == 0:
+		err = m.Up()
+	case command == "up" && len(args) == 1:
+		var steps int
+		if steps, err = positive(args[0]); err == nil {
+			err = m.Steps(steps)
+		}
+	case command == "down" && len(args) == 1 && args[0] == "-all":
+		err = m.Down()
+	case command == "down" && len(args) == 1:
+		var steps int
+		if steps, err = positive(args[0]); err == nil {
+			err = m.Steps(-steps)
+		}
+	case command == "version" && len(args) == 0:
+		version, dirty, err := m.Version()
+		if errors.Is(err, migrate.ErrNilVersion) {
+			fmt.Println("no migration applied")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if dirty {
+			fmt.Printf("%d (dirty)\n", version)
+		} else {
+			fmt.Println(version)
+		}
+		return nil
+	case command == "force" && len(args) == 1:
+		var version int
+		if version, err = strconv.Atoi(args[0]); err == nil {
+			err = m.Force(version)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	if errors.Is(err, migrate.ErrNoChange) {
+		log.Printf("No change")
+		return nil
+	}
+	return err
+}
+
+func positive(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q isn't a positive number of migrations", arg)
+	}
+	return n, nil
+}
+
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...any) {
+	log.Printf(format, v...)
+}
+
+func (migrateLogger) Verbose() bool {
+	return true
+}
@@ -0,0 +1,155 @@
+package problem
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+)
+
+// the errors of the other packages telling the status and the code of their response, the store errors
+type StatusError interface {
+	error
+	HTTPStatus() int
+	ErrorCode() string
+}
+
+// maps any error to the one shown to the users, the messages of the unknown errors are never shown
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return InvalidArgument(err)
+	}
+
+	var statusErr StatusError
+	if errors.As(err, &statusErr) {
+		e := Wrap(statusErr.HTTPStatus(), statusErr.ErrorCode(), statusErr)
+		// the exceeded limit is shown as is
+		if e.Code == CodeLimitExceeded {
+			e.Limit = statusErr
+		}
+		return e
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: "Resource not found", cause: err}
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return &Error{Status: http.StatusConflict, Code: CodeAlreadyExists, Message: "Resource already exists", cause: err}
+		case "foreign_key_violation":
+			return &Error{Status: http.StatusConflict, Code: CodeConflict, Message: "Resource references a missing one", cause: err}
+		}
+	}
+	return Internal(err)
+}
+
+// a 400 for the errors of binding and validating the requests
+func InvalidArgument(err error) *Error {
+	e := &Error{Status: http.StatusBadRequest, Code: CodeInvalidArgument, Message: "Invalid request", cause: err}
+
+	var validationErrs validator.ValidationErrors
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var numErr *strconv.NumError
+	var timeErr *time.ParseError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fieldErr := range validationErrs {
+			e.Fields = append(e.Fields, newFieldError(fieldPath(fieldErr), fieldErr))
+		}
+		e.Message = validationMessage(e.Fields)
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		e.Message = "Request body isn't valid JSON"
+	case errors.As(err, &typeErr):
+		e.Message = fmt.Sprintf("%s must be of type %s", typeErr.Field, typeErr.Type)
+	case errors.As(err, &numErr):
+		e.Message = fmt.Sprintf("%q isn't a valid number", numErr.Num)
+	case errors.As(err, &timeErr):
+		e.Message = fmt.Sprintf("%q isn't a valid time", timeErr.Value)
+	default:
+		// the rest come from parsing the request, they don't leak anything
+		e.Message = err.Error()
+	}
+	return e
+}
+
+// a 400 for a single value validated with validator.Var, the errors of Var don't know the name of the field
+func InvalidField(field string, err error) *Error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return InvalidArgument(fmt.Errorf("%s: %w", field, err))
+	}
+
+	e := InvalidArgument(err)
+	e.Fields = []FieldError{newFieldError(field, validationErrs[0])}
+	e.Message = validationMessage(e.Fields)
+	return e
+}
+
+func validationMessage(fields []FieldError) string {
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field.Message)
+	}
+	return "Request validation failed: " + strings.Join(messages, ", ")
+}
+
+func newFieldError(field string, fieldErr validator.FieldError) FieldError {
+	return FieldError{Field: field, Tag: fieldErr.Tag(), Message: fieldMessage(field, fieldErr)}
+}
+
+// transfers[1].amount rather than batchTransferReq.transfers[1].amount
+func fieldPath(fieldErr validator.FieldError) string {
+	if _, path, ok := strings.Cut(fieldErr.Namespace(), "."); ok {
+		return path
+	}
+	return fieldErr.Field()
+}
+
+func fieldMessage(field string, fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s%s", field, fieldErr.Param(), unit(fieldErr))
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s%s", field, fieldErr.Param(), unit(fieldErr))
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s%s", field, fieldErr.Param(), unit(fieldErr))
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s%s", field, fieldErr.Param(), unit(fieldErr))
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, fieldErr.Param())
+	}
+	return fmt.Sprintf("%s failed on the '%s' rule", field, fieldErr.Tag())
+}
+
+// the min and max of the strings and lists are their lengths
+func unit(fieldErr validator.FieldError) string {
+	switch fieldErr.Kind() {
+	case reflect.String:
+		return " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items long"
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package problem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/AYehia0/go-bk-mst/utils"
+)
+
+// the machine readable codes of the errors, the clients should switch on them instead of the messages
+const (
+	CodeInvalidArgument   = "invalid_argument"
+	CodeUnauthenticated   = "unauthenticated"
+	CodePermissionDenied  = "permission_denied"
+	CodeForbidden         = "forbidden"
+	CodeNotFound          = "not_found"
+	CodeAlreadyExists     = "already_exists"
+	CodeConflict          = "conflict"
+	CodeInsufficientFunds = "insufficient_funds"
+	CodeLimitExceeded     = "limit_exceeded"
+	CodeUnprocessable     = "unprocessable"
+	CodeInternal          = "internal"
+)
+
+const ContentType = "application/problem+json"
+
+// the message of the errors that aren't meant for the users, the cause is only logged
+const internalMessage = "Internal server error"
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
+}
+
+// an error the users are allowed to see, the cause is kept for the logs only
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	// the failed validations of the request
+	Fields []FieldError
+	// the exceeded transfer limit, the limit_exceeded errors of the store
+	Limit StatusError
+	cause error
+}
+
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func Newf(status int, code, format string, a ...any) *Error {
+	return New(status, code, fmt.Sprintf(format, a...))
+}
+
+// an error with the message of err, only for the errors whose message is safe to show
+func Wrap(status int, code string, err error) *Error {
+	return &Error{Status: status, Code: code, Message: err.Error(), cause: err}
+}
+
+// a 500 hiding the message of err
+func Internal(err error) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: internalMessage, cause: err}
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil && e.cause.Error() != e.Message {
+		return fmt.Sprintf("%s: %s", e.Message, e.cause)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// the RFC 7807 body of an error
+type Document struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestId string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	Limit     StatusError  `json:"limit,omitempty"`
+}
+
+func (e *Error) Document(r *http.Request) Document {
+	doc := Document{
+		// the code tells the problems apart, the type has no page to point to
+		Type:   "about:blank",
+		Title:  http.StatusText(e.Status),
+		Status: e.Status,
+		Detail: e.Message,
+		Code:   e.Code,
+		Errors: e.Fields,
+		Limit:  e.Limit,
+	}
+	if r != nil {
+		doc.Instance = r.URL.Path
+		doc.RequestId = utils.RequestId(r.Context())
+	}
+	return doc
+}
+
+// writes the application/problem+json response of err, see From for how the errors are mapped
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	e := From(err)
+
+	body, err := json.Marshal(e.Document(r))
+	if err != nil {
+		e = Internal(err)
+		body, _ = json.Marshal(e.Document(r))
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(e.Status)
+	w.Write(body)
+}
@@ -0,0 +1,147 @@
+package problem
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+type transferReq struct {
+	Amount   int64  `validate:"required,gt=0"`
+	Fee      int64  `validate:"gte=0"`
+	Currency string `validate:"required,oneof=USD EUR"`
+}
+
+func TestFrom(t *testing.T) {
+	validationErr := validator.New().Struct(transferReq{Amount: -1, Fee: -1})
+	limitErr := &db.LimitError{Limit: db.LimitDailyAmount, Max: 100, Remaining: 5}
+
+	testCases := []struct {
+		testName string
+		err      error
+		check    func(t *testing.T, e *Error)
+	}{
+		{
+			testName: "Error",
+			err:      fmt.Errorf("wrapped: %w", New(http.StatusConflict, CodeConflict, "Hold is no longer pending")),
+			check: func(t *testing.T, e *Error) {
+				require.Equal(t, http.StatusConflict, e.Status)
+				require.Equal(t, CodeConflict, e.Code)
+				require.Equal(t, "Hold is no longer pending", e.Message)
+			},
+		},
+		{
+			testName: "NoRows",
+			err:      sql.ErrNoRows,
+			check: func(t *testing.T, e *Error) {
+				require.Equal(t, http.StatusNotFound, e.Status)
+				require.Equal(t, CodeNotFound, e.Code)
+				require.ErrorIs(t, e, sql.ErrNoRows)
+			},
+		},
+		{
+			testName: "UniqueViolation",
+			err:      &pq.Error{Code: "23505", Message: `duplicate key value violates unique constraint "owner_currency_key"`},
+			check: func(t *testing.T, e *Error) {
+				require.Equal(t, http.StatusConflict, e.Status)
+				require.Equal(t, CodeAlreadyExists, e.Code)
+				require.NotContains(t, e.Message, "owner_currency_key")
+			},
+		},
+		{
+			testName: "Validation",
+			err:      validationErr,
+			check: func(t *testing.T, e *Error) {
+				require.Equal(t, http.StatusBadRequest, e.Status)
+				require.Equal(t, CodeInvalidArgument, e.Code)
+				require.Equal(t, []FieldError{
+					{Field: "Amount", Tag: "gt", Message: "Amount must be greater than 0"},
+					{Field: "Fee", Tag: "gte", Message: "Fee must be at least 0"},
+					{Field: "Currency", Tag: "required", Message: "Currency is required"},
+				}, e.Fields)
+			},
+		},
+		{
+			testName: "LimitExceeded",
+			err:      fmt.Errorf("transfer: %w", limitErr),
+			check: func(t *testing.T, e *Error) {
+				require.Equal(t, http.StatusUnprocessableEntity, e.Status)
+				require.Equal(t, CodeLimitExceeded, e.Code)
+				require.Equal(t, limitErr.Error(), e.Message)
+				require.Equal(t, limitErr, e.Limit)
+			},
+		},
+		{
+			testName: "InsufficientFunds",
+			err:      db.ErrInsufficientFunds,
+			check: func(t *testing.T, e *Error) {
+				require.Equal(t, http.StatusUnprocessableEntity, e.Status)
+				require.Equal(t, CodeInsufficientFunds, e.Code)
+			},
+		},
+		{
+			testName: "Unknown",
+			err:      errors.New("pq: connection refused"),
+			check: func(t *testing.T, e *Error) {
+				require.Equal(t, http.StatusInternalServerError, e.Status)
+				require.Equal(t, CodeInternal, e.Code)
+				require.Equal(t, "Internal server error", e.Message)
+				// still there for the logs
+				require.Contains(t, e.Error(), "connection refused")
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			testCase.check(t, From(testCase.err))
+		})
+	}
+}
+
+func TestInvalidArgument(t *testing.T) {
+	var syntaxErr error = json.Unmarshal([]byte("{"), &struct{}{})
+	require.Equal(t, "Request body isn't valid JSON", InvalidArgument(syntaxErr).Message)
+
+	var typeErr error = json.Unmarshal([]byte(`{"amount": "ten"}`), &struct {
+		Amount int64 `json:"amount"`
+	}{})
+	require.Equal(t, "amount must be of type int64", InvalidArgument(typeErr).Message)
+
+	e := InvalidField("page_size", validator.New().Var(int32(20), "min=5,max=10"))
+	require.Equal(t, []FieldError{{Field: "page_size", Tag: "max", Message: "page_size must be at most 10"}}, e.Fields)
+	require.Equal(t, "Request validation failed: page_size must be at most 10", e.Message)
+}
+
+func TestWrite(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+	req = req.WithContext(utils.WithRequestId(req.Context(), "request-1"))
+
+	Write(recorder, req, sql.ErrNoRows)
+
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	require.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+
+	var doc Document
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &doc))
+	require.Equal(t, Document{
+		Type:      "about:blank",
+		Title:     "Not Found",
+		Status:    http.StatusNotFound,
+		Detail:    "Resource not found",
+		Instance:  "/accounts/1",
+		Code:      CodeNotFound,
+		RequestId: "request-1",
+	}, doc)
+}
@@ -0,0 +1,38 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/utils"
+)
+
+// one row per entry after a header, the amounts are formatted in major units like the payment files
+func WriteCSV(w io.Writer, s *Statement) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"entry_id", "date", "description", "reference", "category", "amount", "balance", "currency"}); err != nil {
+		return err
+	}
+
+	for _, line := range s.Lines {
+		err := writer.Write([]string{
+			strconv.FormatInt(line.EntryId, 10),
+			line.Date.UTC().Format(time.RFC3339),
+			line.Description,
+			line.Reference,
+			line.Category,
+			utils.FormatAmount(line.Amount),
+			utils.FormatAmount(line.Balance),
+			s.Currency,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,117 @@
+package statement
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/utils"
+)
+
+const (
+	ofxHeader = `<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+	// identifies the bank in the BANKACCTFROM aggregate
+	ofxBankId = "GOBKMST"
+	// the NAME of a transaction is 32 chars at most
+	ofxMaxNameLength = 32
+)
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxTransaction struct {
+	Type   string `xml:"TRNTYPE"`
+	Posted string `xml:"DTPOSTED"`
+	Amount string `xml:"TRNAMT"`
+	FitId  string `xml:"FITID"`
+	Name   string `xml:"NAME,omitempty"`
+	Memo   string `xml:"MEMO,omitempty"`
+}
+
+type ofxDocument struct {
+	XMLName xml.Name `xml:"OFX"`
+	SignOn  struct {
+		Status   ofxStatus `xml:"STATUS"`
+		Server   string    `xml:"DTSERVER"`
+		Language string    `xml:"LANGUAGE"`
+	} `xml:"SIGNONMSGSRSV1>SONRS"`
+	Statement struct {
+		TrnUid   string    `xml:"TRNUID"`
+		Status   ofxStatus `xml:"STATUS"`
+		Response struct {
+			Currency string `xml:"CURDEF"`
+			Account  struct {
+				BankId string `xml:"BANKID"`
+				Id     string `xml:"ACCTID"`
+				Type   string `xml:"ACCTTYPE"`
+			} `xml:"BANKACCTFROM"`
+			Transactions struct {
+				Start        string           `xml:"DTSTART"`
+				End          string           `xml:"DTEND"`
+				Transactions []ofxTransaction `xml:"STMTTRN"`
+			} `xml:"BANKTRANLIST"`
+			Ledger struct {
+				Amount string `xml:"BALAMT"`
+				AsOf   string `xml:"DTASOF"`
+			} `xml:"LEDGERBAL"`
+		} `xml:"STMTRS"`
+	} `xml:"BANKMSGSRSV1>STMTTRNRS"`
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405") + "[0:GMT]"
+}
+
+// OFX 2.2 (XML) bank statement, importable by most personal finance apps
+func WriteOFX(w io.Writer, s *Statement) error {
+	var doc ofxDocument
+
+	doc.SignOn.Status = ofxStatus{Code: 0, Severity: "INFO"}
+	doc.SignOn.Server = ofxTime(time.Now())
+	doc.SignOn.Language = "ENG"
+
+	doc.Statement.TrnUid = "0"
+	doc.Statement.Status = ofxStatus{Code: 0, Severity: "INFO"}
+
+	resp := &doc.Statement.Response
+	resp.Currency = s.Currency
+	resp.Account.BankId = ofxBankId
+	resp.Account.Id = strconv.FormatInt(s.AccountId, 10)
+	resp.Account.Type = "CHECKING"
+	resp.Transactions.Start = ofxTime(s.From)
+	resp.Transactions.End = ofxTime(s.To)
+	resp.Ledger.Amount = utils.FormatAmount(s.ClosingBalance)
+	resp.Ledger.AsOf = ofxTime(s.To)
+
+	for _, line := range s.Lines {
+		trnType := "CREDIT"
+		if line.Amount < 0 {
+			trnType = "DEBIT"
+		}
+
+		name := line.Description
+		if len([]rune(name)) > ofxMaxNameLength {
+			name = string([]rune(name)[:ofxMaxNameLength])
+		}
+
+		resp.Transactions.Transactions = append(resp.Transactions.Transactions, ofxTransaction{
+			Type:   trnType,
+			Posted: ofxTime(line.Date),
+			Amount: utils.FormatAmount(line.Amount),
+			FitId:  strconv.FormatInt(line.EntryId, 10),
+			Name:   name,
+			Memo:   line.Reference,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header+ofxHeader); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(doc)
+}
@@ -0,0 +1,111 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/AYehia0/go-bk-mst/utils"
+	"github.com/go-pdf/fpdf"
+)
+
+const pdfDateFormat = "2006-01-02"
+
+// the widths of the entry table columns in mm, they add up to the A4 width minus the margins
+var pdfColumns = []struct {
+	title string
+	width float64
+	align string
+}{
+	{"Date", 25, "L"},
+	{"Description", 70, "L"},
+	{"Reference", 30, "L"},
+	{"Amount", 27.5, "R"},
+	{"Balance", 27.5, "R"},
+}
+
+// A4 statement: a header with the owner and the account, the opening/closing balances then the entries.
+// the core fonts are used, so nothing has to be embedded or read from the disk.
+func WritePDF(w io.Writer, s *Statement) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(fmt.Sprintf("Statement of account %d", s.AccountId), true)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+
+	// the core fonts are cp1252, translate from utf-8
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for _, column := range pdfColumns {
+			pdf.CellFormat(column.width, 7, column.title, "1", 0, column.align, true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Account statement", "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	details := [][2]string{
+		{"Account holder", tr(s.FullName)},
+		{"Account", fmt.Sprintf("%d (%s)", s.AccountId, s.Currency)},
+		{"Period", fmt.Sprintf("%s - %s", s.From.UTC().Format(pdfDateFormat), s.LastDay().UTC().Format(pdfDateFormat))},
+		{"Opening balance", utils.FormatAmount(s.OpeningBalance) + " " + s.Currency},
+		{"Closing balance", utils.FormatAmount(s.ClosingBalance) + " " + s.Currency},
+	}
+	credits, debits := s.Totals()
+	details = append(details,
+		[2]string{"Money in", utils.FormatAmount(credits) + " " + s.Currency},
+		[2]string{"Money out", utils.FormatAmount(debits) + " " + s.Currency},
+	)
+	for _, detail := range details {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(40, 6, detail[0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, 6, detail[1], "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	header()
+	_, pageHeight := pdf.GetPageSize()
+	for _, line := range s.Lines {
+		// repeat the table header on every page
+		if pdf.GetY()+6 > pageHeight-15 {
+			pdf.AddPage()
+			header()
+		}
+
+		values := []string{
+			line.Date.UTC().Format(pdfDateFormat),
+			tr(line.Description),
+			tr(line.Reference),
+			utils.FormatAmount(line.Amount),
+			utils.FormatAmount(line.Balance),
+		}
+		for i, column := range pdfColumns {
+			value := values[i]
+			// cut the long texts instead of wrapping them, every entry stays on a single row
+			for pdf.GetStringWidth(value) > column.width-2 && len(value) > 0 {
+				value = value[:len(value)-1]
+			}
+			pdf.CellFormat(column.width, 6, value, "1", 0, column.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	if len(s.Lines) == 0 {
+		pdf.CellFormat(0, 6, "No entries in this period", "1", 1, "C", false, 0, "")
+	}
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,98 @@
+// Account statements for a period, exported as CSV, OFX or PDF.
+package statement
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	FormatCSV = "csv"
+	FormatOFX = "ofx"
+	FormatPDF = "pdf"
+)
+
+// a single booked entry, the amounts are in minor units
+type Line struct {
+	EntryId     int64     `json:"entry_id"`
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	Reference   string    `json:"reference"`
+	Category    string    `json:"category"`
+	Amount      int64     `json:"amount"`
+	// the balance right after the entry
+	Balance int64 `json:"balance"`
+}
+
+// the entries of an account between From (exclusive) and To (inclusive), To is the midnight
+// ending the period so the last day of the statement is the one before it
+type Statement struct {
+	AccountId      int64     `json:"account_id"`
+	OwnerName      string    `json:"owner_name"`
+	FullName       string    `json:"full_name"`
+	Currency       string    `json:"currency"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	OpeningBalance int64     `json:"opening_balance"`
+	ClosingBalance int64     `json:"closing_balance"`
+	Lines          []Line    `json:"lines"`
+}
+
+// fills in the running balances of the lines and the closing balance from the opening one
+func (s *Statement) Balance() {
+	balance := s.OpeningBalance
+	for i := range s.Lines {
+		balance += s.Lines[i].Amount
+		s.Lines[i].Balance = balance
+	}
+	s.ClosingBalance = balance
+}
+
+// the total money in and out during the period
+func (s *Statement) Totals() (credits, debits int64) {
+	for _, line := range s.Lines {
+		if line.Amount > 0 {
+			credits += line.Amount
+		} else {
+			debits -= line.Amount
+		}
+	}
+	return
+}
+
+// the last day covered by the statement, for displaying the period
+func (s *Statement) LastDay() time.Time {
+	return s.To.AddDate(0, 0, -1)
+}
+
+// the name of the downloaded file, statement-<account>-<from>-<to>.<format>
+func (s *Statement) Filename(format string) string {
+	return fmt.Sprintf("statement-%d-%s-%s.%s", s.AccountId, s.From.Format("20060102"), s.LastDay().Format("20060102"), format)
+}
+
+// writes the statement in the given format
+func Write(w io.Writer, format string, s *Statement) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, s)
+	case FormatOFX:
+		return WriteOFX(w, s)
+	case FormatPDF:
+		return WritePDF(w, s)
+	}
+	return fmt.Errorf("Unsupported statement format %q", format)
+}
+
+// the content type of the format, for the downloads
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "application/octet-stream"
+}
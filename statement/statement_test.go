@@ -0,0 +1,105 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testStatement() *Statement {
+	from := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	s := &Statement{
+		AccountId:      7,
+		OwnerName:      "ahmed",
+		FullName:       "Ahmed Yehia",
+		Currency:       "USD",
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: 10000,
+		Lines: []Line{
+			{EntryId: 1, Date: from.Add(time.Hour), Description: "Salary", Category: "income", Amount: 250000},
+			{EntryId: 2, Date: from.Add(48 * time.Hour), Description: "Rent, March", Reference: "INV-42", Amount: -120050},
+			{EntryId: 3, Date: from.Add(72 * time.Hour), Description: "Café", Amount: -450},
+		},
+	}
+	s.Balance()
+	return s
+}
+
+func TestBalance(t *testing.T) {
+	s := testStatement()
+
+	require.Equal(t, int64(260000), s.Lines[0].Balance)
+	require.Equal(t, int64(139950), s.Lines[1].Balance)
+	require.Equal(t, int64(139500), s.Lines[2].Balance)
+	require.Equal(t, int64(139500), s.ClosingBalance)
+
+	credits, debits := s.Totals()
+	require.Equal(t, int64(250000), credits)
+	require.Equal(t, int64(120500), debits)
+	require.Equal(t, s.ClosingBalance, s.OpeningBalance+credits-debits)
+
+	require.Equal(t, "statement-7-20240301-20240331.csv", s.Filename(FormatCSV))
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatCSV, testStatement()))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	require.Equal(t, []string{"entry_id", "date", "description", "reference", "category", "amount", "balance", "currency"}, records[0])
+	require.Equal(t, []string{"2", "2024-03-03T00:00:00Z", "Rent, March", "INV-42", "", "-1200.50", "1399.50", "USD"}, records[2])
+}
+
+func TestWriteOFX(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatOFX, testStatement()))
+
+	content := buf.String()
+	require.True(t, strings.HasPrefix(content, xml.Header+ofxHeader))
+
+	var doc ofxDocument
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+
+	resp := doc.Statement.Response
+	require.Equal(t, "USD", resp.Currency)
+	require.Equal(t, "7", resp.Account.Id)
+	require.Equal(t, "1395.00", resp.Ledger.Amount)
+	require.Equal(t, "20240301000000[0:GMT]", resp.Transactions.Start)
+
+	require.Len(t, resp.Transactions.Transactions, 3)
+	require.Equal(t, ofxTransaction{
+		Type:   "DEBIT",
+		Posted: "20240303000000[0:GMT]",
+		Amount: "-1200.50",
+		FitId:  "2",
+		Name:   "Rent, March",
+		Memo:   "INV-42",
+	}, resp.Transactions.Transactions[1])
+}
+
+func TestWritePDF(t *testing.T) {
+	s := testStatement()
+	// enough entries to span a few pages
+	for i := 0; i < 100; i++ {
+		s.Lines = append(s.Lines, Line{EntryId: int64(i + 4), Date: s.From, Description: strings.Repeat("long description ", 10), Amount: 1})
+	}
+	s.Balance()
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatPDF, s))
+	require.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+}
+
+func TestWriteUnsupported(t *testing.T) {
+	var buf bytes.Buffer
+	require.Error(t, Write(&buf, "xlsx", testStatement()))
+	require.Equal(t, "application/octet-stream", ContentType("xlsx"))
+}
@@ -0,0 +1,97 @@
+// Real-time account activity for the API clients, fed by the NOTIFYs of the transfer transaction.
+package stream
+
+import (
+	"sync"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+)
+
+// a client's stream of the activity of one user's accounts
+type Subscription struct {
+	// closed when the hub drops the subscription
+	C         <-chan db.AccountActivity
+	c         chan db.AccountActivity
+	username  string
+	accountId int64
+}
+
+// fans the activity out to the subscriptions of the accounts' owners
+type Hub struct {
+	mu            sync.RWMutex
+	subscriptions map[string]map[*Subscription]struct{}
+	closed        bool
+}
+
+func NewHub() *Hub {
+	return &Hub{subscriptions: map[string]map[*Subscription]struct{}{}}
+}
+
+// subscribes to the activity of the user's accounts, or only the given one when accountId isn't 0.
+// the activity is dropped while the buffer is full, the clients can always refetch the account.
+func (hub *Hub) Subscribe(username string, accountId int64, buffer int) *Subscription {
+	c := make(chan db.AccountActivity, buffer)
+	subscription := &Subscription{C: c, c: c, username: username, accountId: accountId}
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	// the server is shutting down, the stream ends right away
+	if hub.closed {
+		close(c)
+		return subscription
+	}
+
+	if hub.subscriptions[username] == nil {
+		hub.subscriptions[username] = map[*Subscription]struct{}{}
+	}
+	hub.subscriptions[username][subscription] = struct{}{}
+	return subscription
+}
+
+func (hub *Hub) Unsubscribe(subscription *Subscription) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	subscriptions := hub.subscriptions[subscription.username]
+	if _, ok := subscriptions[subscription]; !ok {
+		return
+	}
+
+	delete(subscriptions, subscription)
+	if len(subscriptions) == 0 {
+		delete(hub.subscriptions, subscription.username)
+	}
+	close(subscription.c)
+}
+
+// drops all the subscriptions, so the streams end and the server can shut down
+func (hub *Hub) Close() {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	for _, subscriptions := range hub.subscriptions {
+		for subscription := range subscriptions {
+			close(subscription.c)
+		}
+	}
+	hub.subscriptions = map[string]map[*Subscription]struct{}{}
+	hub.closed = true
+}
+
+// sends the activity to the subscriptions of the account's owner
+func (hub *Hub) Broadcast(activity db.AccountActivity) {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+
+	for subscription := range hub.subscriptions[activity.OwnerName] {
+		if subscription.accountId != 0 && subscription.accountId != activity.AccountId {
+			continue
+		}
+
+		select {
+		case subscription.c <- activity:
+		default:
+		}
+	}
+}
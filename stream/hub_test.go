@@ -0,0 +1,64 @@
+package stream
+
+import (
+	"testing"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+
+	all := hub.Subscribe("ahmed", 0, 10)
+	one := hub.Subscribe("ahmed", 2, 10)
+	other := hub.Subscribe("mona", 0, 10)
+
+	hub.Broadcast(db.AccountActivity{AccountId: 1, OwnerName: "ahmed", Balance: 100})
+	hub.Broadcast(db.AccountActivity{AccountId: 2, OwnerName: "ahmed", Balance: 200})
+
+	require.Equal(t, int64(1), (<-all.C).AccountId)
+	require.Equal(t, int64(2), (<-all.C).AccountId)
+	require.Equal(t, int64(2), (<-one.C).AccountId)
+	require.Len(t, one.C, 0)
+	require.Len(t, other.C, 0)
+
+	hub.Unsubscribe(all)
+	_, ok := <-all.C
+	require.False(t, ok)
+
+	// twice is fine
+	hub.Unsubscribe(all)
+	hub.Broadcast(db.AccountActivity{AccountId: 2, OwnerName: "ahmed"})
+	require.Len(t, one.C, 1)
+}
+
+func TestHubSlowSubscriber(t *testing.T) {
+	hub := NewHub()
+	subscription := hub.Subscribe("ahmed", 0, 1)
+
+	// the broadcast never blocks, the overflow is dropped
+	hub.Broadcast(db.AccountActivity{AccountId: 1, OwnerName: "ahmed"})
+	hub.Broadcast(db.AccountActivity{AccountId: 2, OwnerName: "ahmed"})
+
+	require.Equal(t, int64(1), (<-subscription.C).AccountId)
+	require.Len(t, subscription.C, 0)
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub()
+	subscription := hub.Subscribe("ahmed", 0, 1)
+
+	hub.Close()
+	_, ok := <-subscription.C
+	require.False(t, ok)
+
+	// the handlers still unsubscribe once their stream ends
+	hub.Unsubscribe(subscription)
+
+	// the streams opened while shutting down end right away
+	late := hub.Subscribe("ahmed", 0, 1)
+	_, ok = <-late.C
+	require.False(t, ok)
+	hub.Unsubscribe(late)
+}
@@ -0,0 +1,59 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/lib/pq"
+)
+
+const (
+	// the reconnection backoff of the listener's connection
+	minReconnectInterval = time.Second
+	maxReconnectInterval = time.Minute
+	// how often the idle connection is checked
+	pingInterval = 90 * time.Second
+)
+
+// LISTENs to the account activity on its own connection and broadcasts it to the hub until the context is done.
+// the notifications sent while the connection is down are lost, the clients resync by fetching their accounts.
+func Listen(ctx context.Context, dataSource string, hub *Hub) error {
+	listener := pq.NewListener(dataSource, minReconnectInterval, maxReconnectInterval, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Account activity listener : %v", err)
+		}
+	})
+	defer listener.Close()
+
+	// blocks until the connection is up, it's listened to again after every reconnection
+	if err := listener.Listen(db.AccountActivityChannel); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case notification := <-listener.Notify:
+			// nil after a reconnection
+			if notification == nil {
+				continue
+			}
+
+			var activity db.AccountActivity
+			if err := json.Unmarshal([]byte(notification.Extra), &activity); err != nil {
+				log.Printf("Invalid account activity : %v", err)
+				continue
+			}
+			hub.Broadcast(activity)
+		case <-ticker.C:
+			go listener.Ping()
+		}
+	}
+}
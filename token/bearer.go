@@ -0,0 +1,35 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// the only supported type of the authorization header/metadata
+const AuthorizationTypeBearer = "bearer"
+
+var (
+	AuthorizationEmptyError  = errors.New("Authentication header is empty")
+	AuthorizationFormatError = errors.New("Invalid authentication header format")
+	AuthorizationTypeError   = errors.New("Unspported authorization type")
+)
+
+// verifies the "bearer <token>" value of the authorization header, shared by the HTTP and gRPC servers
+func VerifyAuthorization(creator TokenCreator, authorization string) (*Payload, error) {
+	if len(authorization) == 0 {
+		return nil, AuthorizationEmptyError
+	}
+
+	fields := strings.Fields(authorization)
+	if len(fields) < 2 {
+		return nil, AuthorizationFormatError
+	}
+
+	authType := strings.ToLower(fields[0])
+	if authType != AuthorizationTypeBearer {
+		return nil, fmt.Errorf("%w %s", AuthorizationTypeError, authType)
+	}
+
+	return creator.Verify(fields[1])
+}
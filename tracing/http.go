@@ -0,0 +1,49 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// a span per gin request, continuing the trace of the traceparent header if there's one.
+// the handlers get the span through the request context.
+func Middleware(ctx *gin.Context) {
+	parent := otel.GetTextMapPropagator().Extract(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header))
+
+	// the route keeps the number of span names small, the unknown paths share one
+	route := ctx.FullPath()
+	name := fmt.Sprintf("%s %s", ctx.Request.Method, route)
+	if route == "" {
+		name = ctx.Request.Method
+	}
+
+	spanCtx, span := tracer().Start(parent, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPMethodKey.String(ctx.Request.Method),
+			semconv.HTTPTargetKey.String(ctx.Request.URL.Path),
+			semconv.HTTPRoute(route),
+		),
+	)
+	defer span.End()
+
+	ctx.Request = ctx.Request.WithContext(spanCtx)
+
+	ctx.Next()
+
+	status := ctx.Writer.Status()
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	if len(ctx.Errors) > 0 {
+		span.RecordError(ctx.Errors.Last())
+	}
+}
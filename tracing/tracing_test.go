@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var recorder = tracetest.NewSpanRecorder()
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	os.Exit(m.Run())
+}
+
+// the span ended last with the name
+func endedSpan(t *testing.T, name string) sdktrace.ReadOnlySpan {
+	spans := recorder.Ended()
+	for i := len(spans) - 1; i >= 0; i-- {
+		if spans[i].Name() == name {
+			return spans[i]
+		}
+	}
+	t.Fatalf("No %q span", name)
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	router := gin.New()
+	router.Use(Middleware)
+
+	var handlerSpan trace.SpanContext
+	router.GET("/accounts/:id", func(ctx *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(ctx.Request.Context())
+		ctx.Status(http.StatusInternalServerError)
+	})
+
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+	req.Header.Set("traceparent", traceparent)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	span := endedSpan(t, "GET /accounts/:id")
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	// continues the trace of the caller
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	require.True(t, span.Parent().IsRemote())
+	require.Equal(t, span.SpanContext(), handlerSpan)
+	require.Equal(t, "Error", span.Status().Code.String())
+}
+
+func TestGrpcPropagation(t *testing.T) {
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "gateway")
+	defer parent.End()
+
+	// what the client sends is what the server receives
+	var sent metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply any, conn *grpc.ClientConn, opts ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	require.NoError(t, UnaryClientInterceptor(ctx, "/pb.SimpleBank/GetAccount", nil, nil, nil, invoker))
+	require.NotEmpty(t, sent.Get("traceparent"))
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.SimpleBank/GetAccount"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	}
+	_, err := UnaryServerInterceptor(metadata.NewIncomingContext(context.Background(), sent), nil, info, handler)
+	require.NoError(t, err)
+
+	span := endedSpan(t, "/pb.SimpleBank/GetAccount")
+	require.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+	require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+}
+
+func TestSetup(t *testing.T) {
+	shutdown, err := Setup(context.Background(), ExporterNone, "")
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), "jaeger", "")
+	require.Error(t, err)
+}
@@ -10,9 +10,53 @@ type Config struct {
 	DbDriver                   string        `mapstructure:"DB_DRIVER"`
 	DbSource                   string        `mapstructure:"DB_SOURCE"`
 	ServerAddr                 string        `mapstructure:"SERVER_ADDR"`
+	GrpcServerAddr             string        `mapstructure:"GRPC_SERVER_ADDR"`
 	TokenKey                   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
 	TokenExpireDuration        time.Duration `mapstructure:"TOKEN_EXPIRE_TIME"`
 	TokenRefreshExpireDuration time.Duration `mapstructure:"TOKEN_REFRESH_EXPIRE_TIME"`
+	HoldExpireDuration         time.Duration `mapstructure:"HOLD_EXPIRE_TIME"`
+	HoldExpiryInterval         time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+	InterestInterval           time.Duration `mapstructure:"INTEREST_INTERVAL"`
+	SnapshotInterval           time.Duration `mapstructure:"SNAPSHOT_INTERVAL"`
+	OutboxInterval             time.Duration `mapstructure:"OUTBOX_INTERVAL"`
+	// memory or file, the file publisher appends the events to OutboxFile
+	OutboxPublisher string        `mapstructure:"OUTBOX_PUBLISHER"`
+	OutboxFile      string        `mapstructure:"OUTBOX_FILE"`
+	WebhookInterval time.Duration `mapstructure:"WEBHOOK_INTERVAL"`
+	// the timeout of a single delivery, and the attempts before it's given up
+	WebhookTimeout     time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	WebhookMaxAttempts int32         `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	// the default transfer limits in minor units, 0 means unlimited
+	TransferMaxAmount   int64 `mapstructure:"TRANSFER_MAX_AMOUNT"`
+	TransferDailyAmount int64 `mapstructure:"TRANSFER_DAILY_AMOUNT"`
+	TransferHourlyCount int64 `mapstructure:"TRANSFER_HOURLY_COUNT"`
+	// the max amount of a single deposit/withdrawal at the counter, 0 means unlimited
+	CashMaxAmount int64 `mapstructure:"CASH_MAX_AMOUNT"`
+	// debug, info, warn or error, the request bodies are only logged at debug
+	LogLevel string `mapstructure:"LOG_LEVEL"`
+	// text or json
+	LogFormat string `mapstructure:"LOG_FORMAT"`
+	// none, stdout or otlp, the otlp exporter sends the spans to the grpc collector of TRACING_ENDPOINT
+	TracingExporter string `mapstructure:"TRACING_EXPORTER"`
+	TracingEndpoint string `mapstructure:"TRACING_ENDPOINT"`
+	// the timeouts of the http.Server, the activity streams aren't bound by them
+	ServerReadTimeout  time.Duration `mapstructure:"SERVER_READ_TIMEOUT"`
+	ServerWriteTimeout time.Duration `mapstructure:"SERVER_WRITE_TIMEOUT"`
+	ServerIdleTimeout  time.Duration `mapstructure:"SERVER_IDLE_TIMEOUT"`
+	// how long the requests and the workers in flight are waited for on SIGTERM
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	// runs the embedded migrations on startup, the replicas take turns
+	AutoMigrate bool `mapstructure:"AUTO_MIGRATE"`
+	// the pool of connections to the database, 0 open conns means unlimited
+	DbMaxOpenConns    int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+	DbMaxIdleConns    int           `mapstructure:"DB_MAX_IDLE_CONNS"`
+	DbConnMaxLifetime time.Duration `mapstructure:"DB_CONN_MAX_LIFETIME"`
+	DbConnMaxIdleTime time.Duration `mapstructure:"DB_CONN_MAX_IDLE_TIME"`
+	// how long the startup retries to reach the database before giving up
+	DbConnectTimeout time.Duration `mapstructure:"DB_CONNECT_TIMEOUT"`
+	// the bounds of the queries and the lock waits of every request, 0 means unbounded
+	DbStatementTimeout time.Duration `mapstructure:"DB_STATEMENT_TIMEOUT"`
+	DbLockTimeout      time.Duration `mapstructure:"DB_LOCK_TIMEOUT"`
 }
 
 func ConfigStore(configPath, configName, configType string) (config Config, err error) {
@@ -1,6 +1,13 @@
 // supported currencies
 package utils
 
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
 const (
 	USD = "USD"
 	EUR = "EUR"
@@ -8,6 +15,9 @@ const (
 	EGP = "EGP"
 )
 
+// amounts are stored in the minor unit of the currency (cents), all the supported currencies have 2 decimals.
+const minorUnits = 100
+
 func IsSupportedCurrency(currency string) bool {
 	switch currency {
 	case USD, EUR, EGP, CAD:
@@ -15,3 +25,44 @@ func IsSupportedCurrency(currency string) bool {
 	}
 	return false
 }
+
+// converts a decimal amount like "12.3" or "12.30" into minor units : 1230
+func ParseAmount(amount string) (int64, error) {
+	amount = strings.TrimSpace(amount)
+	units, cents, found := strings.Cut(amount, ".")
+
+	if units == "" || strings.HasPrefix(units, "-") || strings.HasPrefix(units, "+") || len(cents) > 2 || (found && cents == "") {
+		return 0, fmt.Errorf("Invalid amount: %q", amount)
+	}
+
+	major, err := strconv.ParseInt(units, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid amount: %q", amount)
+	}
+
+	var minor int64
+	if cents != "" {
+		minor, err = strconv.ParseInt(cents, 10, 64)
+		if err != nil || strings.HasPrefix(cents, "-") || strings.HasPrefix(cents, "+") {
+			return 0, fmt.Errorf("Invalid amount: %q", amount)
+		}
+		if len(cents) == 1 {
+			minor *= 10
+		}
+	}
+
+	if major > (math.MaxInt64-minor)/minorUnits {
+		return 0, fmt.Errorf("Amount is too large: %q", amount)
+	}
+	return major*minorUnits + minor, nil
+}
+
+// converts minor units back into a decimal amount : 1230 -> "12.30"
+func FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/minorUnits, amount%minorUnits)
+}
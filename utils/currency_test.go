@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAmount(t *testing.T) {
+	valid := map[string]int64{
+		"0":       0,
+		"12":      1200,
+		"12.3":    1230,
+		"12.30":   1230,
+		"0.05":    5,
+		" 100.5 ": 10050,
+	}
+	for input, expected := range valid {
+		amount, err := ParseAmount(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, amount, input)
+
+		// formatting it back keeps the value
+		parsed, err := ParseAmount(FormatAmount(amount))
+		require.NoError(t, err)
+		require.Equal(t, amount, parsed)
+	}
+
+	for _, input := range []string{"", "-1", "+1", "1.", ".5", "1.234", "1,5", "abc", "1.-5"} {
+		_, err := ParseAmount(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestParseAmountOverflow(t *testing.T) {
+	testCases := []struct {
+		input string
+		valid bool
+	}{
+		// math.MaxInt64 minor units
+		{input: "92233720368547758.07", valid: true},
+		{input: "92233720368547758.08", valid: false},
+		{input: "92233720368547759", valid: false},
+		// wraps around to 84 without the check
+		{input: "184467440737095517.00", valid: false},
+	}
+
+	for _, testCase := range testCases {
+		amount, err := ParseAmount(testCase.input)
+		if testCase.valid {
+			require.NoError(t, err, testCase.input)
+			require.Equal(t, int64(math.MaxInt64), amount)
+		} else {
+			require.ErrorContains(t, err, "too large", testCase.input)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	require.Equal(t, "0.00", FormatAmount(0))
+	require.Equal(t, "0.05", FormatAmount(5))
+	require.Equal(t, "12.30", FormatAmount(1230))
+	require.Equal(t, "-12.30", FormatAmount(-1230))
+}
@@ -1,35 +1,103 @@
 package utils
 
 import (
-	"bytes"
+	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"time"
-
-	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
 )
 
-func LogRequestBodyMiddleware(c *gin.Context) {
-	// Read the request body
-	if c.Request.Body == nil {
-		fmt.Printf("Request body is empty!")
-		c.Next()
-		return
+// what the values of the sensitive fields are replaced with in the logs
+const Redacted = "[REDACTED]"
+
+// the passwords, the tokens and the webhook secrets, matched on the json, form and log keys
+func sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	return key == "authorization" || key == "token" ||
+		strings.HasSuffix(key, "_token") ||
+		strings.Contains(key, "password") ||
+		strings.Contains(key, "secret")
+}
+
+// the logger of the LOG_LEVEL (debug, info, warn or error) and LOG_FORMAT (text or json),
+// the sensitive attributes are redacted whatever logs them
+func NewLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	logLevel := slog.LevelInfo
+	if err := logLevel.UnmarshalText([]byte(level)); level != "" && err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
 	}
-	body, err := ioutil.ReadAll(c.Request.Body)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		c.Abort()
-		return
+
+	opts := &slog.HandlerOptions{
+		Level: logLevel,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if sensitiveKey(a.Key) {
+				return slog.String(a.Key, Redacted)
+			}
+			return a
+		},
 	}
 
-	// Restore the request body for downstream handlers
-	c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+	switch format {
+	case "text", "":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+}
 
-	// Log the request body
-	fmt.Printf("[%s] Request Body: %s\n", time.Now().Format(time.RFC3339), body)
+// the body of a request with its sensitive fields masked, only the json and form bodies are kept,
+// the others could be anything so just their size is
+func RedactBody(contentType string, body []byte) string {
+	switch {
+	case strings.HasPrefix(contentType, "application/json"):
+		var value any
+		if err := json.Unmarshal(body, &value); err == nil {
+			redacted, _ := json.Marshal(redactValue(value))
+			return string(redacted)
+		}
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			return RedactValues(values)
+		}
+	}
+	return fmt.Sprintf("[%d bytes]", len(body))
+}
+
+// the query or form with its sensitive fields masked, unescaped to be readable in the logs
+func RedactValues(values url.Values) string {
+	redacted := make(url.Values, len(values))
+	for key, value := range values {
+		if sensitiveKey(key) {
+			value = []string{Redacted}
+		}
+		redacted[key] = value
+	}
 
-	// Continue to the next middleware/handler
-	c.Next()
+	encoded := redacted.Encode()
+	if unescaped, err := url.QueryUnescape(encoded); err == nil {
+		return unescaped
+	}
+	return encoded
+}
+
+func redactValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, field := range value {
+			if sensitiveKey(key) {
+				value[key] = Redacted
+			} else {
+				value[key] = redactValue(field)
+			}
+		}
+	case []any:
+		for i, item := range value {
+			value[i] = redactValue(item)
+		}
+	}
+	return value
 }
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactBody(t *testing.T) {
+	body := RedactBody("application/json; charset=utf-8", []byte(`{
+		"username": "ahmed",
+		"password": "secret123",
+		"refresh_token": "v2.local.token",
+		"nested": {"old_password": "secret123", "items": [{"access_token": "v2.local.token", "amount": 10}]}
+	}`))
+	require.NotContains(t, body, "secret123")
+	require.NotContains(t, body, "v2.local.token")
+
+	var redacted map[string]any
+	require.NoError(t, json.Unmarshal([]byte(body), &redacted))
+	require.Equal(t, "ahmed", redacted["username"])
+	require.Equal(t, Redacted, redacted["password"])
+	require.Equal(t, Redacted, redacted["refresh_token"])
+	require.Equal(t, float64(10), redacted["nested"].(map[string]any)["items"].([]any)[0].(map[string]any)["amount"])
+
+	form := RedactBody("application/x-www-form-urlencoded", []byte("username=ahmed&password=secret123"))
+	require.Equal(t, "password=[REDACTED]&username=ahmed", form)
+
+	// the bodies that can't be parsed could hold anything
+	require.Equal(t, "[18 bytes]", RedactBody("application/json", []byte(`{"password": "secr`)))
+	require.Equal(t, "[9 bytes]", RedactBody("text/csv", []byte("a,b,c\n1,2")))
+
+	query := RedactValues(url.Values{"access_token": {"v2.local.token"}, "account_id": {"1"}})
+	require.Equal(t, "access_token=[REDACTED]&account_id=1", query)
+}
+
+func TestNewLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := NewLogger(&out, "debug", "json")
+	require.NoError(t, err)
+
+	logger.Debug("Login", "username", "ahmed", "password", "secret123", "access_token_expire_at", "2023-01-01")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	require.Equal(t, "ahmed", line["username"])
+	require.Equal(t, Redacted, line["password"])
+	require.Equal(t, "2023-01-01", line["access_token_expire_at"])
+
+	_, err = NewLogger(&out, "verbose", "json")
+	require.Error(t, err)
+	_, err = NewLogger(&out, "info", "xml")
+	require.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const RequestIdHeader = "X-Request-Id"
+
+type requestIdKey struct{}
+
+// the id of the request, the one sent by the client or a generated one.
+// it's echoed in the response header and stored in the request context, the handlers proxied to
+// the gateway see it too.
+func RequestIdMiddleware(c *gin.Context) {
+	requestId := c.GetHeader(RequestIdHeader)
+	if requestId == "" || len(requestId) > 128 {
+		requestId = uuid.NewString()
+	}
+
+	c.Request = c.Request.WithContext(WithRequestId(c.Request.Context(), requestId))
+	c.Header(RequestIdHeader, requestId)
+
+	c.Next()
+}
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, requestId)
+}
+
+// empty when the request didn't go through the RequestIdMiddleware
+func RequestId(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdKey{}).(string)
+	return requestId
+}
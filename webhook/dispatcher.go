@@ -0,0 +1,171 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+)
+
+const (
+	// the delay before the second attempt, doubled on every failure up to maxBackoff
+	baseBackoff = 10 * time.Second
+	maxBackoff  = time.Hour
+	// the max deliveries claimed at once
+	dispatchBatchSize = 50
+	// the most of the response body kept in the delivery log
+	maxLoggedResponse = 512
+)
+
+var ErrPrivateAddress = errors.New("Webhook endpoint resolves to a private address")
+
+// sends the due deliveries to the subscribers' endpoints and logs the outcome
+type Dispatcher struct {
+	store       db.Store
+	client      *http.Client
+	maxAttempts int32
+	// the time the deliveries are leased for while they're sent
+	lease time.Duration
+	now   func() time.Time
+}
+
+func NewDispatcher(store db.Store, timeout time.Duration, maxAttempts int32) *Dispatcher {
+	return &Dispatcher{
+		store:       store,
+		client:      newClient(timeout),
+		maxAttempts: maxAttempts,
+		lease:       timeout*dispatchBatchSize + time.Minute,
+		now:         time.Now,
+	}
+}
+
+// the subscribers can't make the bank call its own network (the cloud metadata at 169.254.169.254, the database...),
+// the address is checked when it's dialed, whatever the host name resolved to and after every redirect
+func newClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialPublicOnly}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// the proxy's address would be the only one checked
+	transport.Proxy = nil
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// false for the loopback, private, link-local, multicast and unspecified addresses
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate()
+}
+
+// the delay after the given failed attempt (starting at 1): 10s, 20s, 40s... up to an hour
+func Backoff(attempt int32) time.Duration {
+	delay := baseBackoff
+	for i := int32(1); i < attempt; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
+
+// sends a batch of the due deliveries, returns how many were attempted.
+// a delivery which couldn't be recorded is logged, its lease runs out and it's claimed again
+func (dispatcher *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := dispatcher.store.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+		LeaseSeconds: int32(dispatcher.lease / time.Second),
+		LimitCount:   dispatchBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		if _, err := dispatcher.Deliver(ctx, delivery); err != nil {
+			log.Printf("Failed to record the webhook delivery [%d] : %v", delivery.ID, err)
+		}
+	}
+	return len(deliveries), nil
+}
+
+// a single attempt of the delivery, the error is only about recording it
+func (dispatcher *Dispatcher) Deliver(ctx context.Context, delivery db.WebhookDelivery) (db.WebhookDelivery, error) {
+	subscription, err := dispatcher.store.GetWebhookSubscriptionById(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return delivery, err
+	}
+
+	statusCode, err := dispatcher.send(ctx, subscription, delivery)
+
+	now := dispatcher.now()
+	arg := db.UpdateWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         db.WebhookDeliverySucceeded,
+		NextAttemptAt:  now,
+		LastStatusCode: int32(statusCode),
+	}
+
+	switch {
+	case err == nil:
+		arg.DeliveredAt = sql.NullTime{Time: now, Valid: true}
+	default:
+		arg.LastError = err.Error()
+		attempt := delivery.Attempts + 1
+		if attempt >= dispatcher.maxAttempts {
+			arg.Status = db.WebhookDeliveryFailed
+		} else {
+			arg.Status = db.WebhookDeliveryPending
+			arg.NextAttemptAt = now.Add(Backoff(attempt))
+		}
+	}
+
+	return dispatcher.store.UpdateWebhookDeliveryAttempt(ctx, arg)
+}
+
+// POSTs the payload, anything but a 2xx is a failure
+func (dispatcher *Dispatcher) send(ctx context.Context, subscription db.WebhookSubscription, delivery db.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-bk-mst-webhooks")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, dispatcher.now(), delivery.Payload))
+
+	resp, err := dispatcher.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedResponse))
+		return resp.StatusCode, fmt.Errorf("Webhook endpoint responded with %d: %s", resp.StatusCode, body)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/events"
+)
+
+// the event types a subscription can ask for
+var EventTypes = []string{db.EventTransferCompleted, db.EventAccountCreated}
+
+// the body POSTed to the subscribers
+type Envelope struct {
+	// the id of the event, the same for all the subscribers
+	Id        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// queues the deliveries of the relayed events to the subscribers, plugged into the outbox relay as any other publisher
+type Publisher struct {
+	store db.Store
+}
+
+func NewPublisher(store db.Store) *Publisher {
+	return &Publisher{store: store}
+}
+
+func (publisher *Publisher) Publish(ctx context.Context, event events.Event) error {
+	accountIds, err := eventAccounts(event)
+	if err != nil || len(accountIds) == 0 {
+		return err
+	}
+
+	body, err := json.Marshal(Envelope{
+		Id:        event.Id,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = publisher.store.CreateWebhookDeliveriesTransaction(ctx, db.CreateWebhookDeliveriesTxParams{
+		EventId:    event.Id,
+		EventType:  event.Type,
+		AccountIds: accountIds,
+		Payload:    body,
+	})
+	return err
+}
+
+func (publisher *Publisher) Close() error {
+	return nil
+}
+
+// the accounts the event is about, nothing for the events that can't be subscribed to
+func eventAccounts(event events.Event) ([]int64, error) {
+	switch event.Type {
+	case db.EventTransferCompleted:
+		// both sides, the receiver for the money that arrived and the sender for the one that left
+		var transfer db.Transfer
+		if err := json.Unmarshal(event.Payload, &transfer); err != nil {
+			return nil, err
+		}
+		return []int64{transfer.FromAccountID, transfer.ToAccountID}, nil
+	case db.EventAccountCreated:
+		var account db.Account
+		if err := json.Unmarshal(event.Payload, &account); err != nil {
+			return nil, err
+		}
+		return []int64{account.ID}, nil
+	}
+	return nil, nil
+}
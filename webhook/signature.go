@@ -0,0 +1,77 @@
+// Outbound webhooks: the events of the users' accounts POSTed to their endpoints, signed and retried.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>">
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	// the same on the retries and the replays of a delivery, for the receivers to dedupe
+	DeliveryHeader = "X-Webhook-Delivery"
+)
+
+var (
+	ErrInvalidSignature = errors.New("Invalid webhook signature")
+	ErrExpiredSignature = errors.New("Webhook signature is too old")
+)
+
+func computeSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// the signature header of the body sent at the given time, the timestamp is signed too so a captured request can't be replayed later
+func Sign(secret string, at time.Time, body []byte) string {
+	timestamp := at.Unix()
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, computeSignature(secret, timestamp, body))
+}
+
+// checks the signature header of a received body, the way the receivers are expected to.
+// a signature older than tolerance is rejected, 0 accepts any age.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var timestamp int64
+	var signatures []string
+
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrInvalidSignature
+		}
+		switch key {
+		case "t":
+			var err error
+			if timestamp, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return ErrInvalidSignature
+			}
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == 0 || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	if tolerance > 0 && now.Sub(time.Unix(timestamp, 0)) > tolerance {
+		return ErrExpiredSignature
+	}
+
+	expected := computeSignature(secret, timestamp, body)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
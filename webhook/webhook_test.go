@@ -0,0 +1,255 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	mockdb "github.com/AYehia0/go-bk-mst/db/mock"
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/events"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{"id":1}`)
+	now := time.Now()
+
+	header := Sign(secret, now, body)
+	require.NoError(t, Verify(secret, header, body, 5*time.Minute, now))
+
+	require.ErrorIs(t, Verify("another secret", header, body, 5*time.Minute, now), ErrInvalidSignature)
+	require.ErrorIs(t, Verify(secret, header, []byte(`{"id":2}`), 5*time.Minute, now), ErrInvalidSignature)
+	require.ErrorIs(t, Verify(secret, header, body, 5*time.Minute, now.Add(time.Hour)), ErrExpiredSignature)
+	require.ErrorIs(t, Verify(secret, "garbage", body, 0, now), ErrInvalidSignature)
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, 10*time.Second, Backoff(1))
+	require.Equal(t, 20*time.Second, Backoff(2))
+	require.Equal(t, 80*time.Second, Backoff(4))
+	require.Equal(t, time.Hour, Backoff(20))
+}
+
+func TestPublisher(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	store := mockdb.NewMockStore(controller)
+	publisher := NewPublisher(store)
+
+	transfer, err := json.Marshal(db.Transfer{ID: 5, FromAccountID: 1, ToAccountID: 2, Amount: 100})
+	require.NoError(t, err)
+
+	event := events.Event{Id: 9, Type: db.EventTransferCompleted, Payload: transfer, CreatedAt: time.Now().UTC()}
+
+	store.EXPECT().
+		CreateWebhookDeliveriesTransaction(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.CreateWebhookDeliveriesTxParams) (int, error) {
+			require.Equal(t, int64(9), arg.EventId)
+			require.Equal(t, []int64{1, 2}, arg.AccountIds)
+
+			var envelope Envelope
+			require.NoError(t, json.Unmarshal(arg.Payload, &envelope))
+			require.Equal(t, event.Id, envelope.Id)
+			require.JSONEq(t, string(transfer), string(envelope.Data))
+			return 2, nil
+		})
+	require.NoError(t, publisher.Publish(context.Background(), event))
+
+	// nobody can subscribe to the registrations
+	require.NoError(t, publisher.Publish(context.Background(), events.Event{Id: 10, Type: db.EventUserRegistered, Payload: []byte(`{}`)}))
+}
+
+func TestDispatcher(t *testing.T) {
+	secret := "whsec_test"
+	payload := []byte(`{"id":3,"type":"transfer.completed"}`)
+
+	testCases := []struct {
+		testName  string
+		status    int
+		attempts  int32
+		checkStub func(t *testing.T, arg db.UpdateWebhookDeliveryAttemptParams, now time.Time)
+	}{
+		{
+			testName: "Succeeded",
+			status:   http.StatusNoContent,
+			checkStub: func(t *testing.T, arg db.UpdateWebhookDeliveryAttemptParams, now time.Time) {
+				require.Equal(t, db.WebhookDeliverySucceeded, arg.Status)
+				require.Equal(t, int32(http.StatusNoContent), arg.LastStatusCode)
+				require.Empty(t, arg.LastError)
+				require.Equal(t, sql.NullTime{Time: now, Valid: true}, arg.DeliveredAt)
+			},
+		},
+		{
+			testName: "Retried",
+			status:   http.StatusServiceUnavailable,
+			attempts: 2,
+			checkStub: func(t *testing.T, arg db.UpdateWebhookDeliveryAttemptParams, now time.Time) {
+				require.Equal(t, db.WebhookDeliveryPending, arg.Status)
+				require.Equal(t, int32(http.StatusServiceUnavailable), arg.LastStatusCode)
+				require.Contains(t, arg.LastError, "503")
+				// the third attempt failed
+				require.Equal(t, now.Add(40*time.Second), arg.NextAttemptAt)
+				require.False(t, arg.DeliveredAt.Valid)
+			},
+		},
+		{
+			testName: "OutOfAttempts",
+			status:   http.StatusInternalServerError,
+			attempts: 7,
+			checkStub: func(t *testing.T, arg db.UpdateWebhookDeliveryAttemptParams, now time.Time) {
+				require.Equal(t, db.WebhookDeliveryFailed, arg.Status)
+				require.NotEmpty(t, arg.LastError)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.testName, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+
+			now := time.Now()
+
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				require.Equal(t, payload, body)
+				require.Equal(t, db.EventTransferCompleted, r.Header.Get(EventHeader))
+				require.Equal(t, "7", r.Header.Get(DeliveryHeader))
+				require.NoError(t, Verify(secret, r.Header.Get(SignatureHeader), body, time.Minute, now))
+
+				w.WriteHeader(testCase.status)
+			}))
+			defer receiver.Close()
+
+			subscription := db.WebhookSubscription{ID: 1, Url: receiver.URL, Secret: secret}
+			delivery := db.WebhookDelivery{
+				ID:             7,
+				SubscriptionID: subscription.ID,
+				EventType:      db.EventTransferCompleted,
+				Payload:        payload,
+				Attempts:       testCase.attempts,
+			}
+
+			store := mockdb.NewMockStore(controller)
+			store.EXPECT().
+				ClaimWebhookDeliveries(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return([]db.WebhookDelivery{delivery}, nil)
+			store.EXPECT().GetWebhookSubscriptionById(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+			store.EXPECT().
+				UpdateWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.UpdateWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+					require.Equal(t, delivery.ID, arg.ID)
+					testCase.checkStub(t, arg, now)
+					return delivery, nil
+				})
+
+			dispatcher := NewDispatcher(store, time.Second, 8)
+			dispatcher.now = func() time.Time { return now }
+			// the receiver listens on the loopback the dispatcher refuses
+			dispatcher.client = receiver.Client()
+
+			delivered, err := dispatcher.DeliverDue(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, delivered)
+		})
+	}
+}
+
+func TestDispatcherPrivateAddress(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	received := make(chan struct{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer receiver.Close()
+
+	subscription := db.WebhookSubscription{ID: 1, Url: receiver.URL, Secret: "whsec_test"}
+	delivery := db.WebhookDelivery{ID: 7, SubscriptionID: subscription.ID, EventType: db.EventTransferCompleted, Payload: []byte(`{}`)}
+
+	var arg db.UpdateWebhookDeliveryAttemptParams
+	store := mockdb.NewMockStore(controller)
+	store.EXPECT().GetWebhookSubscriptionById(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+	store.EXPECT().
+		UpdateWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, update db.UpdateWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+			arg = update
+			return delivery, nil
+		})
+
+	dispatcher := NewDispatcher(store, time.Second, 8)
+	_, err := dispatcher.Deliver(context.Background(), delivery)
+	require.NoError(t, err)
+
+	// refused before any byte is sent, retried like any other failure
+	require.Equal(t, db.WebhookDeliveryPending, arg.Status)
+	require.Contains(t, arg.LastError, ErrPrivateAddress.Error())
+	require.Len(t, received, 0)
+}
+
+func TestIsPublicAddress(t *testing.T) {
+	for address, public := range map[string]bool{
+		"93.184.216.34":          true,
+		"2606:2800:220:1::":      true,
+		"127.0.0.1":              false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"0.0.0.0":                false,
+		"::1":                    false,
+		"fe80::1":                false,
+		"fd00::1":                false,
+		"::ffff:169.254.169.254": false,
+	} {
+		require.Equal(t, public, IsPublicAddress(netip.MustParseAddr(address)), address)
+	}
+}
+
+func TestDeliverDueContinues(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	subscription := db.WebhookSubscription{ID: 1, Url: receiver.URL, Secret: "whsec_test"}
+	broken := db.WebhookDelivery{ID: 7, SubscriptionID: 2, Payload: []byte(`{}`)}
+	delivery := db.WebhookDelivery{ID: 8, SubscriptionID: subscription.ID, Payload: []byte(`{}`)}
+
+	store := mockdb.NewMockStore(controller)
+	store.EXPECT().
+		ClaimWebhookDeliveries(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.WebhookDelivery{broken, delivery}, nil)
+	store.EXPECT().GetWebhookSubscriptionById(gomock.Any(), gomock.Eq(broken.SubscriptionID)).Times(1).Return(db.WebhookSubscription{}, sql.ErrConnDone)
+
+	// the delivery after the broken one is still sent
+	store.EXPECT().GetWebhookSubscriptionById(gomock.Any(), gomock.Eq(subscription.ID)).Times(1).Return(subscription, nil)
+	store.EXPECT().UpdateWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1).Return(delivery, nil)
+
+	dispatcher := NewDispatcher(store, time.Second, 8)
+	dispatcher.client = receiver.Client()
+
+	delivered, err := dispatcher.DeliverDue(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, delivered)
+}
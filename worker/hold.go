@@ -0,0 +1,43 @@
+// background jobs that run next to the http server.
+// they stop once their context is done, but the batch in flight runs to the end so they can be drained on shutdown.
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+)
+
+// max number of holds released in a single transaction
+const expireHoldsBatchSize = 100
+
+// releases the expired holds every interval until the context is done.
+func ExpireHolds(ctx context.Context, store db.Store, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// keep going until there is nothing left to expire
+			for ctx.Err() == nil {
+				holds, err := store.ExpireHoldsTransaction(context.WithoutCancel(ctx), expireHoldsBatchSize)
+				if err != nil {
+					log.Printf("Failed to expire holds : %v", err)
+					break
+				}
+				if len(holds) < expireHoldsBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
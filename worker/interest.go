@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+)
+
+// the days looked back on every run, so the days missed while the server was down are accrued too
+const interestCatchUpDays = 7
+
+// accrues the interest of the past days and posts the previous months every interval until the context is done.
+// both steps are idempotent, running them more than once a day is harmless.
+func Interest(ctx context.Context, store db.Store, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now().UTC()
+			accrueInterest(context.WithoutCancel(ctx), store, now)
+			postInterest(context.WithoutCancel(ctx), store, now)
+		}
+	}
+}
+
+// today isn't over yet, start from yesterday
+func accrueInterest(ctx context.Context, store db.Store, now time.Time) {
+	for days := interestCatchUpDays; days >= 1; days-- {
+		if _, err := store.AccrueInterestTransaction(ctx, now.AddDate(0, 0, -days)); err != nil {
+			log.Printf("Failed to accrue interest : %v", err)
+			return
+		}
+	}
+}
+
+// credits whatever was accrued before the current month
+func postInterest(ctx context.Context, store db.Store, now time.Time) {
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	unposted, err := store.GetUnpostedInterest(ctx, monthStart)
+	if err != nil {
+		log.Printf("Failed to get the unposted interest : %v", err)
+		return
+	}
+
+	for _, row := range unposted {
+		_, err := store.PostInterestTransaction(ctx, db.PostInterestTxParams{
+			AccountId: row.AccountID,
+			PlanId:    row.PlanID,
+			Before:    monthStart,
+		})
+		if err != nil {
+			log.Printf("Failed to post the interest of account [%d] : %v", row.AccountID, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+	"github.com/AYehia0/go-bk-mst/events"
+)
+
+// the max events published by a single relay transaction
+const outboxBatchSize = 100
+
+// relays the outbox events to the publisher every interval until the context is done.
+func RelayOutbox(ctx context.Context, store db.Store, publisher events.Publisher, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// keep going while there is a backlog
+			for ctx.Err() == nil {
+				published, err := RelayOutboxBatch(context.WithoutCancel(ctx), store, publisher)
+				if err != nil {
+					log.Printf("Failed to relay the outbox events : %v", err)
+				}
+				if err != nil || published < outboxBatchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// publishes a single batch of the oldest unpublished events
+func RelayOutboxBatch(ctx context.Context, store db.Store, publisher events.Publisher) (int, error) {
+	return store.RelayOutboxTransaction(ctx, outboxBatchSize, func(event db.OutboxEvent) error {
+		return publisher.Publish(ctx, events.Event{
+			Id:            event.ID,
+			Type:          event.EventType,
+			AggregateType: event.AggregateType,
+			AggregateId:   event.AggregateID,
+			Payload:       event.Payload,
+			CreatedAt:     event.CreatedAt,
+		})
+	})
+}
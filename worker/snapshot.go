@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/AYehia0/go-bk-mst/db/sqlc"
+)
+
+// how long to wait after midnight, so the transactions started before it are committed when the snapshot is taken
+const snapshotDelay = 5 * time.Minute
+
+// snapshots the balances of all the accounts at every midnight (UTC), checked every interval until the context is done.
+func SnapshotBalances(ctx context.Context, store db.Store, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// the last midnight that is old enough, the ones already taken are skipped
+			takenAt := time.Now().UTC().Add(-snapshotDelay).Truncate(24 * time.Hour)
+			if _, err := store.SnapshotBalances(context.WithoutCancel(ctx), takenAt); err != nil {
+				log.Printf("Failed to snapshot the balances : %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/AYehia0/go-bk-mst/webhook"
+)
+
+// sends the due webhook deliveries every interval until the context is done.
+func DeliverWebhooks(ctx context.Context, dispatcher *webhook.Dispatcher, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// keep going while there are due deliveries
+			for ctx.Err() == nil {
+				delivered, err := dispatcher.DeliverDue(context.WithoutCancel(ctx))
+				if err != nil {
+					log.Printf("Failed to deliver the webhooks : %v", err)
+				}
+				if err != nil || delivered == 0 {
+					break
+				}
+			}
+		}
+	}
+}